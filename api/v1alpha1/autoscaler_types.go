@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisClusterAutoscaler scales the number of primaries of a Redis Cluster with the memory usage and the load of its primary nodes
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,shortName=rdca
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="Min",type=integer,JSONPath=`.spec.minPrimaries`
+// +kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.spec.maxPrimaries`
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.currentPrimaries`
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredPrimaries`
+// +kubebuilder:printcolumn:name="Memory",type=integer,JSONPath=`.status.memoryUtilization`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RedisClusterAutoscaler struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired RedisClusterAutoscaler specification
+	Spec RedisClusterAutoscalerSpec `json:"spec,omitempty"`
+
+	// Status represents the current RedisClusterAutoscaler status
+	Status RedisClusterAutoscalerStatus `json:"status,omitempty"`
+}
+
+// RedisClusterAutoscalerList implements list of RedisClusterAutoscaler.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RedisClusterAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of RedisClusterAutoscaler
+	Items []RedisClusterAutoscaler `json:"items"`
+}
+
+// RedisClusterAutoscalerSpec contains RedisClusterAutoscaler specification
+type RedisClusterAutoscalerSpec struct {
+	// ClusterName name of the scaled RedisCluster, in the same namespace
+	ClusterName string `json:"clusterName"`
+
+	// MinPrimaries minimum number of primary nodes
+	MinPrimaries int32 `json:"minPrimaries"`
+
+	// MaxPrimaries maximum number of primary nodes
+	MaxPrimaries int32 `json:"maxPrimaries"`
+
+	// TargetMemoryUtilization target percentage of used_memory/maxmemory of the primary nodes, 75 by default
+	TargetMemoryUtilization *int32 `json:"targetMemoryUtilization,omitempty"`
+
+	// TargetOpsPerSec target number of commands processed per second by each primary node, the load is ignored when it is not set
+	TargetOpsPerSec *int64 `json:"targetOpsPerSec,omitempty"`
+
+	// CooldownSeconds minimum number of seconds between two scaling operations, 300 by default
+	CooldownSeconds *int32 `json:"cooldownSeconds,omitempty"`
+}
+
+// RedisClusterAutoscalerStatus contains RedisClusterAutoscaler status
+type RedisClusterAutoscalerStatus struct {
+	// CurrentPrimaries number of primary nodes of the cluster at the last evaluation
+	CurrentPrimaries int32 `json:"currentPrimaries,omitempty"`
+
+	// DesiredPrimaries number of primary nodes computed from the statistics of the primary nodes at the last evaluation
+	DesiredPrimaries int32 `json:"desiredPrimaries,omitempty"`
+
+	// MemoryUtilization percentage of used_memory/maxmemory of the primary nodes, not set when maxmemory is unlimited
+	MemoryUtilization *int32 `json:"memoryUtilization,omitempty"`
+
+	// OpsPerSec average number of commands processed per second by a primary node
+	OpsPerSec int64 `json:"opsPerSec,omitempty"`
+
+	// LastScaleTime time when the autoscaler last changed the number of primaries of the cluster
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// Message human-readable message indicating why the number of primaries is not changed
+	Message string `json:"message,omitempty"`
+}
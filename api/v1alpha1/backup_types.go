@@ -0,0 +1,166 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisClusterBackup represents a backup of the RDB files of a Redis Cluster, or a schedule of backups
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,shortName=rdcb
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RedisClusterBackup struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired RedisClusterBackup specification
+	Spec RedisClusterBackupSpec `json:"spec,omitempty"`
+
+	// Status represents the current RedisClusterBackup status
+	Status RedisClusterBackupStatus `json:"status,omitempty"`
+}
+
+// RedisClusterBackupList implements list of RedisClusterBackup.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RedisClusterBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of RedisClusterBackup
+	Items []RedisClusterBackup `json:"items"`
+}
+
+// RedisClusterBackupSpec contains RedisClusterBackup specification
+type RedisClusterBackupSpec struct {
+	// ClusterName name of the RedisCluster to back up, in the same namespace
+	ClusterName string `json:"clusterName"`
+
+	// Storage where the RDB files are copied
+	Storage BackupStorage `json:"storage"`
+
+	// Schedule cron expression, when set a new RedisClusterBackup is created from this one on each occurrence
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention number of finished backups created by the schedule to keep, older backups and their files are deleted
+	Retention *int32 `json:"retention,omitempty"`
+}
+
+// BackupStorage contains the configuration of the backup storage, exactly one backend must be set
+type BackupStorage struct {
+	// Local stores the backup files in a folder of the operator filesystem, usually a mounted persistent volume
+	Local *LocalBackupStorage `json:"local,omitempty"`
+
+	// S3 stores the backup files in an S3 compatible object storage
+	S3 *S3BackupStorage `json:"s3,omitempty"`
+}
+
+// LocalBackupStorage contains the configuration of the local backup storage
+type LocalBackupStorage struct {
+	// Path folder of the operator filesystem where the backup files are written
+	Path string `json:"path"`
+}
+
+// S3BackupStorage contains the configuration of the S3 compatible backup storage
+type S3BackupStorage struct {
+	// Endpoint URL of the S3 endpoint, for instance https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Endpoint string `json:"endpoint"`
+
+	// Bucket name of the bucket where the backup files are written
+	Bucket string `json:"bucket"`
+
+	// Prefix prepended to the name of the backup files
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region of the bucket, us-east-1 if empty
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret name of the secret in the RedisClusterBackup namespace containing the access key ID and the secret access key
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// RedisClusterBackupStatus contains RedisClusterBackup status
+type RedisClusterBackupStatus struct {
+	// Phase of the backup
+	Phase BackupPhase `json:"phase,omitempty"`
+
+	// StartTime time when the backup started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime time when the backup completed or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// LastScheduleTime time when the schedule last created a backup
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// Message human-readable message indicating details about the phase
+	Message string `json:"message,omitempty"`
+
+	// Shards progress of the backup of each shard
+	Shards []BackupShardStatus `json:"shards,omitempty"`
+}
+
+// BackupShardStatus contains the backup status of a shard, a primary node and its replicas
+type BackupShardStatus struct {
+	// PrimaryID ID of the primary node of the shard
+	PrimaryID string `json:"primaryID"`
+
+	// NodeID ID of the node the RDB file is taken from, a replica when the shard has one
+	NodeID string `json:"nodeID"`
+
+	// PodName name of the pod running the node the RDB file is taken from
+	PodName string `json:"podName"`
+
+	// Slots slot ranges covered by the RDB file
+	Slots []string `json:"slots,omitempty"`
+
+	// Phase of the backup of the shard
+	Phase BackupShardPhase `json:"phase,omitempty"`
+
+	// File name of the RDB file in the backup storage
+	File string `json:"file,omitempty"`
+
+	// Size of the RDB file in bytes
+	Size int64 `json:"size,omitempty"`
+
+	// Checksum sha256 checksum of the RDB file
+	Checksum string `json:"checksum,omitempty"`
+
+	// Message human-readable message indicating details about the phase
+	Message string `json:"message,omitempty"`
+}
+
+// BackupPhase is the phase of a RedisClusterBackup
+type BackupPhase string
+
+const (
+	// BackupPhasePending the backup waits for the cluster
+	BackupPhasePending BackupPhase = "Pending"
+	// BackupPhaseRunning the RDB files are being saved and copied
+	BackupPhaseRunning BackupPhase = "Running"
+	// BackupPhaseCompleted all the RDB files are copied in the backup storage
+	BackupPhaseCompleted BackupPhase = "Completed"
+	// BackupPhaseFailed the backup failed
+	BackupPhaseFailed BackupPhase = "Failed"
+)
+
+// BackupShardPhase is the phase of the backup of a shard
+type BackupShardPhase string
+
+const (
+	// BackupShardPhasePending the RDB file is not saved yet
+	BackupShardPhasePending BackupShardPhase = "Pending"
+	// BackupShardPhaseSaving the node is saving the RDB file in the background
+	BackupShardPhaseSaving BackupShardPhase = "Saving"
+	// BackupShardPhaseCompleted the RDB file is copied in the backup storage
+	BackupShardPhaseCompleted BackupShardPhase = "Completed"
+	// BackupShardPhaseFailed the backup of the shard failed
+	BackupShardPhaseFailed BackupShardPhase = "Failed"
+)
@@ -5,6 +5,48 @@ const (
 	ClusterNameLabelKey string = "redis-operator.k8s.io/cluster-name"
 	// PodSpecMD5LabelKey label key for the PodSpec MD5 hash
 	PodSpecMD5LabelKey string = "redis-operator.k8s.io/podspec-md5"
+	// PodMetadataMD5AnnotationKey annotation key for the MD5 hash of the PodTemplate labels and annotations
+	PodMetadataMD5AnnotationKey string = "redis-operator.k8s.io/podmetadata-md5"
+	// NodeIDAnnotationKey annotation key for the redis node ID stored in a persistent volume claim
+	NodeIDAnnotationKey string = "redis-operator.k8s.io/node-id"
+	// ClaimPodAnnotationKey annotation key for the name of the pod a persistent volume claim is reserved for
+	ClaimPodAnnotationKey string = "redis-operator.k8s.io/claim-pod"
+	// ClaimReservedAtAnnotationKey annotation key for the time a persistent volume claim was reserved for a pod
+	ClaimReservedAtAnnotationKey string = "redis-operator.k8s.io/claim-reserved-at"
+	// BackupScheduleLabelKey label key for the name of the RedisClusterBackup schedule that created a backup
+	BackupScheduleLabelKey string = "redis-operator.k8s.io/backup-schedule"
+	// RedisNodeContainerName name of the container running the redis-server process
+	RedisNodeContainerName string = "redis-node"
+	// RedisPortName name of the redis port of the redis-node container
+	RedisPortName string = "redis"
+	// FailoverAnnotationKey annotation key requesting the failover of the redis primary running in a pod
+	FailoverAnnotationKey string = "redis-operator.k8s.io/failover"
+	// FailoverModeDefault failover mode waiting for the replica to catch up with its primary
+	FailoverModeDefault string = "default"
+	// FailoverModeForce failover mode skipping the handshake with the primary, for an unreachable primary
+	FailoverModeForce string = "force"
+	// FailoverModeTakeover failover mode skipping the agreement of the other primaries
+	FailoverModeTakeover string = "takeover"
+	// RolloutPromoteAnnotationKey annotation key requesting the promotion of the paused or aborted rolling update of a RedisCluster
+	RolloutPromoteAnnotationKey string = "redis-operator.k8s.io/promote-rollout"
+	// ConditionsAnnotationKey annotation key for the v1alpha1 conditions of a RedisCluster converted to v1beta1
+	ConditionsAnnotationKey string = "redis-operator.k8s.io/v1alpha1-conditions"
+	// TeardownFinalizer finalizer of the RedisCluster removed once its resources are deleted
+	TeardownFinalizer string = "redis-operator.k8s.io/teardown"
 	// UnknownZone label for unknown zone
 	UnknownZone string = "unknown"
+	// AuthUsernameKey key of the username in the auth secret
+	AuthUsernameKey string = "username"
+	// AuthPasswordKey key of the password in the auth secret
+	AuthPasswordKey string = "password"
+	// TLSCertKey key of the certificate in the tls secret
+	TLSCertKey string = "tls.crt"
+	// TLSPrivateKeyKey key of the private key in the tls secret
+	TLSPrivateKeyKey string = "tls.key"
+	// TLSCAKey key of the CA certificate in the tls secret
+	TLSCAKey string = "ca.crt"
+	// S3AccessKeyIDKey key of the access key ID in the S3 credentials secret
+	S3AccessKeyIDKey string = "accessKeyID"
+	// S3SecretAccessKeyKey key of the secret access key in the S3 credentials secret
+	S3SecretAccessKeyKey string = "secretAccessKey"
 )
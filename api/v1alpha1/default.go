@@ -13,44 +13,34 @@ var (
 	defaultIdleTimeoutMillis = proto.Int32(30000)
 )
 
-// IsRedisClusterDefaulted check if the RedisCluster is already defaulted
-func IsRedisClusterDefaulted(rc *RedisCluster) bool {
-	if rc.Spec.NumberOfPrimaries == nil {
-		return false
-	}
-	if rc.Spec.ReplicationFactor == nil {
-		return false
-	}
-	if rc.Spec.RollingUpdate == nil {
-		return false
-	}
-	if rc.Spec.Scaling == nil {
-		return false
-	}
-	return true
-}
-
-// DefaultRedisCluster defaults RedisCluster
+// DefaultRedisCluster returns a copy of the RedisCluster with a defaulted spec and a reset cluster status
 func DefaultRedisCluster(baseRedisCluster *RedisCluster) *RedisCluster {
 	rc := baseRedisCluster.DeepCopy()
+	rc.Default()
+
+	rc.Status.Cluster.NumberOfPrimaries = 0
+	rc.Status.Cluster.MinReplicationFactor = 0
+	rc.Status.Cluster.MaxReplicationFactor = 0
+	rc.Status.Cluster.NumberOfPods = 0
+	rc.Status.Cluster.NumberOfPodsReady = 0
+	rc.Status.Cluster.NumberOfRedisNodesRunning = 0
+
+	return rc
+}
+
+// Default sets the default values of the RedisCluster spec, it implements webhook.Defaulter
+func (rc *RedisCluster) Default() {
 	if rc.Spec.NumberOfPrimaries == nil {
-		rc.Spec.NumberOfPrimaries = defaultNumberOfPrimaries
+		rc.Spec.NumberOfPrimaries = proto.Int32(*defaultNumberOfPrimaries)
 	}
 	if rc.Spec.ReplicationFactor == nil {
-		rc.Spec.ReplicationFactor = defaultReplicationFactor
+		rc.Spec.ReplicationFactor = proto.Int32(*defaultReplicationFactor)
 	}
 
 	if rc.Spec.PodTemplate == nil {
 		rc.Spec.PodTemplate = &kapiv1.PodTemplateSpec{}
 	}
 
-	rc.Status.Cluster.NumberOfPrimaries = 0
-	rc.Status.Cluster.MinReplicationFactor = 0
-	rc.Status.Cluster.MaxReplicationFactor = 0
-	rc.Status.Cluster.NumberOfPods = 0
-	rc.Status.Cluster.NumberOfPodsReady = 0
-	rc.Status.Cluster.NumberOfRedisNodesRunning = 0
-
 	if rc.Spec.ZoneAwareReplication == nil {
 		rc.Spec.ZoneAwareReplication = proto.Bool(true)
 	}
@@ -59,37 +49,33 @@ func DefaultRedisCluster(baseRedisCluster *RedisCluster) *RedisCluster {
 		rc.Spec.RollingUpdate = &RollingUpdate{}
 	}
 
+	if rc.Spec.RollingUpdate.Method == "" {
+		rc.Spec.RollingUpdate.Method = RollingUpdateMethodMigration
+	}
 	if rc.Spec.RollingUpdate.KeyMigration == nil {
 		rc.Spec.RollingUpdate.KeyMigration = proto.Bool(true)
 	}
-
-	if rc.Spec.RollingUpdate.KeyBatchSize == nil {
-		rc.Spec.RollingUpdate.KeyBatchSize = defaultKeyBatchSize
-	}
-
-	if rc.Spec.RollingUpdate.SlotBatchSize == nil {
-		rc.Spec.RollingUpdate.SlotBatchSize = defaultSlotBatchSize
-	}
-
-	if rc.Spec.RollingUpdate.IdleTimeoutMillis == nil {
-		rc.Spec.RollingUpdate.IdleTimeoutMillis = defaultIdleTimeoutMillis
-	}
+	defaultMigration(&rc.Spec.RollingUpdate.Migration)
 
 	if rc.Spec.Scaling == nil {
-		rc.Spec.Scaling = &Migration{}
+		rc.Spec.Scaling = &Scaling{}
 	}
-
-	if rc.Spec.Scaling.KeyBatchSize == nil {
-		rc.Spec.Scaling.KeyBatchSize = defaultKeyBatchSize
+	defaultMigration(&rc.Spec.Scaling.Migration)
+	if rc.Spec.Scaling.BalanceBy == "" {
+		rc.Spec.Scaling.BalanceBy = BalanceBySlots
 	}
+}
 
-	if rc.Spec.Scaling.SlotBatchSize == nil {
-		rc.Spec.Scaling.SlotBatchSize = defaultSlotBatchSize
+func defaultMigration(migration *Migration) {
+	if migration.KeyBatchSize == nil {
+		migration.KeyBatchSize = proto.Int32(*defaultKeyBatchSize)
 	}
 
-	if rc.Spec.Scaling.IdleTimeoutMillis == nil {
-		rc.Spec.Scaling.IdleTimeoutMillis = defaultIdleTimeoutMillis
+	if migration.SlotBatchSize == nil {
+		migration.SlotBatchSize = proto.Int32(*defaultSlotBatchSize)
 	}
 
-	return rc
+	if migration.IdleTimeoutMillis == nil {
+		migration.IdleTimeoutMillis = proto.Int32(*defaultIdleTimeoutMillis)
+	}
 }
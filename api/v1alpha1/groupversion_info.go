@@ -53,6 +53,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(GroupVersion,
 		&RedisCluster{},
 		&RedisClusterList{},
+		&RedisClusterBackup{},
+		&RedisClusterBackupList{},
+		&RedisSlotMigration{},
+		&RedisSlotMigrationList{},
+		&RedisClusterAutoscaler{},
+		&RedisClusterAutoscalerList{},
 	)
 	metav1.AddToGroupVersion(scheme, GroupVersion)
 	return nil
@@ -0,0 +1,503 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	kapiv1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/IBM/operator-for-redis-cluster/api/v1beta1"
+)
+
+var _ conversion.Convertible = &RedisCluster{}
+
+// conditionReasonRegexp is the format of the reason of a metav1.Condition
+var conditionReasonRegexp = regexp.MustCompile(`^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$`)
+
+// ConvertTo converts the RedisCluster to the v1beta1 hub version
+func (rc *RedisCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.RedisCluster)
+	if !ok {
+		return fmt.Errorf("unsupported conversion to %T", dstRaw)
+	}
+	src := rc.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = v1beta1.RedisClusterSpec{
+		NumberOfPrimaries:    src.Spec.NumberOfPrimaries,
+		ReplicationFactor:    src.Spec.ReplicationFactor,
+		ServiceName:          src.Spec.ServiceName,
+		PodTemplate:          src.Spec.PodTemplate,
+		ZoneAwareReplication: src.Spec.ZoneAwareReplication,
+		TopologyKeys:         src.Spec.TopologyKeys,
+		AdditionalLabels:     src.Spec.AdditionalLabels,
+		Paused:               src.Spec.Paused,
+		DeletionProtection:   src.Spec.DeletionProtection,
+		FinalBackup:          convertBackupStorageTo(src.Spec.FinalBackup),
+	}
+	if src.Spec.Scaling != nil {
+		dst.Spec.Scaling = &v1beta1.ScalingStrategy{
+			KeyBatchSize:      src.Spec.Scaling.KeyBatchSize,
+			SlotBatchSize:     src.Spec.Scaling.SlotBatchSize,
+			IdleTimeoutMillis: src.Spec.Scaling.IdleTimeoutMillis,
+			BalanceBy:         v1beta1.BalanceBy(src.Spec.Scaling.BalanceBy),
+		}
+	}
+	if src.Spec.RollingUpdate != nil {
+		dst.Spec.RollingUpdate = &v1beta1.RollingUpdateStrategy{
+			Method:               v1beta1.RollingUpdateMethod(src.Spec.RollingUpdate.Method),
+			KeyMigration:         src.Spec.RollingUpdate.KeyMigration,
+			KeyBatchSize:         src.Spec.RollingUpdate.KeyBatchSize,
+			SlotBatchSize:        src.Spec.RollingUpdate.SlotBatchSize,
+			IdleTimeoutMillis:    src.Spec.RollingUpdate.IdleTimeoutMillis,
+			WarmingDelayMillis:   src.Spec.RollingUpdate.WarmingDelayMillis,
+			MaxSurge:             src.Spec.RollingUpdate.MaxSurge,
+			MaxUnavailableShards: src.Spec.RollingUpdate.MaxUnavailableShards,
+			Strategy:             convertRolloutStrategyTo(src.Spec.RollingUpdate.Strategy),
+		}
+	}
+	if src.Spec.Auth != nil {
+		dst.Spec.Auth = &v1beta1.RedisAuth{SecretName: src.Spec.Auth.SecretName}
+	}
+	if src.Spec.TLS != nil {
+		dst.Spec.TLS = &v1beta1.RedisTLS{SecretName: src.Spec.TLS.SecretName}
+	}
+	if src.Spec.Storage != nil {
+		dst.Spec.Storage = &v1beta1.RedisStorage{VolumeClaimTemplate: src.Spec.Storage.VolumeClaimTemplate}
+	}
+	if restore := src.Spec.RestoreFrom; restore != nil {
+		dst.Spec.RestoreFrom = &v1beta1.RedisClusterRestore{
+			BackupName: restore.BackupName,
+			Storage:    convertBackupStorageTo(restore.Storage),
+			Path:       restore.Path,
+		}
+	}
+
+	cluster := src.Status.Cluster
+	dst.Status = v1beta1.RedisClusterStatus{
+		Phase:                v1beta1.ClusterPhase(cluster.Status),
+		StartTime:            src.Status.StartTime,
+		Selector:             cluster.LabelSelectorPath,
+		Primaries:            cluster.NumberOfPrimaries,
+		ReadyPrimaries:       cluster.NumberOfPrimariesReady,
+		MinReplicationFactor: cluster.MinReplicationFactor,
+		MaxReplicationFactor: cluster.MaxReplicationFactor,
+		NodesPlacement:       v1beta1.NodesPlacement(cluster.NodesPlacement),
+		Pods:                 cluster.NumberOfPods,
+		ReadyPods:            cluster.NumberOfPodsReady,
+		RunningRedisNodes:    cluster.NumberOfRedisNodesRunning,
+		ObservedGeneration:   src.Status.ObservedGeneration,
+	}
+	if src.Status.Progress != nil {
+		progress := v1beta1.ProgressStatus(*src.Status.Progress)
+		dst.Status.Progress = &progress
+	}
+	if src.Status.Config != nil {
+		config := v1beta1.ConfigStatus(*src.Status.Config)
+		dst.Status.Config = &config
+	}
+	if rollout := src.Status.Rollout; rollout != nil {
+		dst.Status.Rollout = &v1beta1.RolloutStatus{
+			PodTemplateHash: rollout.PodTemplateHash,
+			Phase:           v1beta1.RolloutPhase(rollout.Phase),
+			TotalShards:     rollout.TotalShards,
+			UpdatedShards:   rollout.UpdatedShards,
+			PromotedShards:  rollout.PromotedShards,
+			PauseTime:       rollout.PauseTime,
+			Message:         rollout.Message,
+		}
+		for podName, counters := range rollout.HealthCounters {
+			if dst.Status.Rollout.HealthCounters == nil {
+				dst.Status.Rollout.HealthCounters = make(map[string]v1beta1.RolloutCounters)
+			}
+			dst.Status.Rollout.HealthCounters[podName] = v1beta1.RolloutCounters(counters)
+		}
+	}
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, convertConditionTo(c))
+	}
+	if err := setConditionsAnnotation(&dst.ObjectMeta, src.Status.Conditions); err != nil {
+		return err
+	}
+	if cluster.NumberOfReplicasPerPrimary != nil {
+		dst.Status.ReplicasPerPrimary = make(map[string]int32, len(cluster.NumberOfReplicasPerPrimary))
+		for id, nbReplicas := range cluster.NumberOfReplicasPerPrimary {
+			dst.Status.ReplicasPerPrimary[id] = int32(nbReplicas)
+		}
+	}
+	for _, node := range cluster.Nodes {
+		dstNode := v1beta1.RedisClusterNode{
+			ID:         node.ID,
+			Role:       v1beta1.RedisClusterNodeRole(node.Role),
+			Zone:       node.Zone,
+			IP:         node.IP,
+			Port:       node.Port,
+			PodName:    node.PodName,
+			PrimaryRef: node.PrimaryRef,
+		}
+		if node.Stats != nil {
+			stats := v1beta1.RedisNodeStats(*node.Stats)
+			dstNode.Stats = &stats
+		}
+		for _, slots := range node.Slots {
+			slotRange, err := parseSlotRange(slots)
+			if err != nil {
+				return fmt.Errorf("invalid slots of node %s: %v", node.ID, err)
+			}
+			dstNode.Slots = append(dstNode.Slots, slotRange)
+		}
+		dst.Status.Nodes = append(dst.Status.Nodes, dstNode)
+	}
+	if restore := src.Status.Restore; restore != nil {
+		dst.Status.Restore = &v1beta1.RestoreStatus{
+			Phase:          v1beta1.RestorePhase(restore.Phase),
+			StartTime:      restore.StartTime,
+			CompletionTime: restore.CompletionTime,
+			Message:        restore.Message,
+			Shards:         restore.Shards,
+			ShardsRestored: restore.ShardsRestored,
+			KeysRestored:   restore.KeysRestored,
+			ShardKeysRead:  restore.ShardKeysRead,
+			Retries:        restore.Retries,
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this RedisCluster
+func (rc *RedisCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	hub, ok := srcRaw.(*v1beta1.RedisCluster)
+	if !ok {
+		return fmt.Errorf("unsupported conversion from %T", srcRaw)
+	}
+	src := hub.DeepCopy()
+	rc.ObjectMeta = src.ObjectMeta
+
+	rc.Spec = RedisClusterSpec{
+		NumberOfPrimaries:    src.Spec.NumberOfPrimaries,
+		ReplicationFactor:    src.Spec.ReplicationFactor,
+		ServiceName:          src.Spec.ServiceName,
+		PodTemplate:          src.Spec.PodTemplate,
+		ZoneAwareReplication: src.Spec.ZoneAwareReplication,
+		TopologyKeys:         src.Spec.TopologyKeys,
+		AdditionalLabels:     src.Spec.AdditionalLabels,
+		Paused:               src.Spec.Paused,
+		DeletionProtection:   src.Spec.DeletionProtection,
+		FinalBackup:          convertBackupStorageFrom(src.Spec.FinalBackup),
+	}
+	if src.Spec.Scaling != nil {
+		rc.Spec.Scaling = &Scaling{
+			Migration: Migration{
+				KeyBatchSize:      src.Spec.Scaling.KeyBatchSize,
+				SlotBatchSize:     src.Spec.Scaling.SlotBatchSize,
+				IdleTimeoutMillis: src.Spec.Scaling.IdleTimeoutMillis,
+			},
+			BalanceBy: BalanceBy(src.Spec.Scaling.BalanceBy),
+		}
+	}
+	if src.Spec.RollingUpdate != nil {
+		rc.Spec.RollingUpdate = &RollingUpdate{
+			Migration: Migration{
+				KeyBatchSize:      src.Spec.RollingUpdate.KeyBatchSize,
+				SlotBatchSize:     src.Spec.RollingUpdate.SlotBatchSize,
+				IdleTimeoutMillis: src.Spec.RollingUpdate.IdleTimeoutMillis,
+			},
+			Method:               RollingUpdateMethod(src.Spec.RollingUpdate.Method),
+			KeyMigration:         src.Spec.RollingUpdate.KeyMigration,
+			WarmingDelayMillis:   src.Spec.RollingUpdate.WarmingDelayMillis,
+			MaxSurge:             src.Spec.RollingUpdate.MaxSurge,
+			MaxUnavailableShards: src.Spec.RollingUpdate.MaxUnavailableShards,
+			Strategy:             convertRolloutStrategyFrom(src.Spec.RollingUpdate.Strategy),
+		}
+	}
+	if src.Spec.Auth != nil {
+		rc.Spec.Auth = &RedisAuth{SecretName: src.Spec.Auth.SecretName}
+	}
+	if src.Spec.TLS != nil {
+		rc.Spec.TLS = &RedisTLS{SecretName: src.Spec.TLS.SecretName}
+	}
+	if src.Spec.Storage != nil {
+		rc.Spec.Storage = &RedisStorage{VolumeClaimTemplate: src.Spec.Storage.VolumeClaimTemplate}
+	}
+	if restore := src.Spec.RestoreFrom; restore != nil {
+		rc.Spec.RestoreFrom = &RedisClusterRestore{
+			BackupName: restore.BackupName,
+			Storage:    convertBackupStorageFrom(restore.Storage),
+			Path:       restore.Path,
+		}
+	}
+
+	rc.Status = RedisClusterStatus{
+		StartTime:          src.Status.StartTime,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Cluster: RedisClusterState{
+			Status:                    ClusterStatus(src.Status.Phase),
+			NumberOfPrimaries:         src.Status.Primaries,
+			NumberOfPrimariesReady:    src.Status.ReadyPrimaries,
+			MinReplicationFactor:      src.Status.MinReplicationFactor,
+			MaxReplicationFactor:      src.Status.MaxReplicationFactor,
+			NodesPlacement:            NodesPlacementInfo(src.Status.NodesPlacement),
+			NumberOfPods:              src.Status.Pods,
+			NumberOfPodsReady:         src.Status.ReadyPods,
+			NumberOfRedisNodesRunning: src.Status.RunningRedisNodes,
+			LabelSelectorPath:         src.Status.Selector,
+		},
+	}
+	if src.Status.Progress != nil {
+		progress := ProgressStatus(*src.Status.Progress)
+		rc.Status.Progress = &progress
+	}
+	if src.Status.Config != nil {
+		config := ConfigStatus(*src.Status.Config)
+		rc.Status.Config = &config
+	}
+	if rollout := src.Status.Rollout; rollout != nil {
+		rc.Status.Rollout = &RolloutStatus{
+			PodTemplateHash: rollout.PodTemplateHash,
+			Phase:           RolloutPhase(rollout.Phase),
+			TotalShards:     rollout.TotalShards,
+			UpdatedShards:   rollout.UpdatedShards,
+			PromotedShards:  rollout.PromotedShards,
+			PauseTime:       rollout.PauseTime,
+			Message:         rollout.Message,
+		}
+		for podName, counters := range rollout.HealthCounters {
+			if rc.Status.Rollout.HealthCounters == nil {
+				rc.Status.Rollout.HealthCounters = make(map[string]RolloutCounters)
+			}
+			rc.Status.Rollout.HealthCounters[podName] = RolloutCounters(counters)
+		}
+	}
+	conditions, err := popConditionsAnnotation(&rc.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	for _, c := range src.Status.Conditions {
+		rc.Status.Conditions = append(rc.Status.Conditions, convertConditionFrom(c, conditions))
+	}
+	if src.Status.ReplicasPerPrimary != nil {
+		rc.Status.Cluster.NumberOfReplicasPerPrimary = make(map[string]int, len(src.Status.ReplicasPerPrimary))
+		for id, nbReplicas := range src.Status.ReplicasPerPrimary {
+			rc.Status.Cluster.NumberOfReplicasPerPrimary[id] = int(nbReplicas)
+		}
+	}
+	for _, node := range src.Status.Nodes {
+		rcNode := RedisClusterNode{
+			ID:         node.ID,
+			Role:       RedisClusterNodeRole(node.Role),
+			Zone:       node.Zone,
+			IP:         node.IP,
+			Port:       node.Port,
+			PodName:    node.PodName,
+			PrimaryRef: node.PrimaryRef,
+		}
+		if node.Stats != nil {
+			stats := RedisNodeStats(*node.Stats)
+			rcNode.Stats = &stats
+		}
+		for _, slotRange := range node.Slots {
+			rcNode.Slots = append(rcNode.Slots, fmt.Sprintf("%d-%d", slotRange.Start, slotRange.End))
+		}
+		rc.Status.Cluster.Nodes = append(rc.Status.Cluster.Nodes, rcNode)
+	}
+	if restore := src.Status.Restore; restore != nil {
+		rc.Status.Restore = &RestoreStatus{
+			Phase:          RestorePhase(restore.Phase),
+			StartTime:      restore.StartTime,
+			CompletionTime: restore.CompletionTime,
+			Message:        restore.Message,
+			Shards:         restore.Shards,
+			ShardsRestored: restore.ShardsRestored,
+			KeysRestored:   restore.KeysRestored,
+			ShardKeysRead:  restore.ShardKeysRead,
+			Retries:        restore.Retries,
+		}
+	}
+	return nil
+}
+
+func convertConditionTo(c RedisClusterCondition) metav1.Condition {
+	lastTransitionTime := c.LastTransitionTime
+	if lastTransitionTime.IsZero() {
+		lastTransitionTime = c.LastProbeTime
+	}
+	return metav1.Condition{
+		Type:               string(c.Type),
+		Status:             metav1.ConditionStatus(c.Status),
+		LastTransitionTime: lastTransitionTime,
+		Reason:             conditionReason(c.Reason),
+		Message:            c.Message,
+	}
+}
+
+// convertConditionFrom returns the original v1alpha1 condition when the v1beta1 condition was not modified since the
+// conversion, otherwise the probe time of the v1beta1 condition is its transition time
+func convertConditionFrom(c metav1.Condition, originals []RedisClusterCondition) RedisClusterCondition {
+	for _, original := range originals {
+		if original.Type == RedisClusterConditionType(c.Type) && apiequality.Semantic.DeepEqual(convertConditionTo(original), c) {
+			return original
+		}
+	}
+	return RedisClusterCondition{
+		Type:               RedisClusterConditionType(c.Type),
+		Status:             kapiv1.ConditionStatus(c.Status),
+		LastProbeTime:      c.LastTransitionTime,
+		LastTransitionTime: c.LastTransitionTime,
+		Reason:             c.Reason,
+		Message:            c.Message,
+	}
+}
+
+// setConditionsAnnotation keeps the v1alpha1 conditions in an annotation, metav1.Condition has no probe time and
+// requires CamelCase reasons
+func setConditionsAnnotation(meta *metav1.ObjectMeta, conditions []RedisClusterCondition) error {
+	if len(conditions) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(conditions)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the conditions: %v", err)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[ConditionsAnnotationKey] = string(data)
+	return nil
+}
+
+// popConditionsAnnotation returns the v1alpha1 conditions kept by setConditionsAnnotation and removes the annotation
+func popConditionsAnnotation(meta *metav1.ObjectMeta) ([]RedisClusterCondition, error) {
+	data, ok := meta.Annotations[ConditionsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(meta.Annotations, ConditionsAnnotationKey)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	var conditions []RedisClusterCondition
+	if err := json.Unmarshal([]byte(data), &conditions); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", ConditionsAnnotationKey, err)
+	}
+	// metav1.Time is decoded in the local time zone
+	for i := range conditions {
+		conditions[i].LastProbeTime = metav1.NewTime(conditions[i].LastProbeTime.UTC())
+		conditions[i].LastTransitionTime = metav1.NewTime(conditions[i].LastTransitionTime.UTC())
+	}
+	return conditions, nil
+}
+
+func convertBackupStorageTo(storage *BackupStorage) *v1beta1.BackupStorage {
+	if storage == nil {
+		return nil
+	}
+	dst := &v1beta1.BackupStorage{}
+	if storage.Local != nil {
+		dst.Local = &v1beta1.LocalBackupStorage{Path: storage.Local.Path}
+	}
+	if s3 := storage.S3; s3 != nil {
+		dst.S3 = &v1beta1.S3BackupStorage{
+			Endpoint:          s3.Endpoint,
+			Bucket:            s3.Bucket,
+			Prefix:            s3.Prefix,
+			Region:            s3.Region,
+			CredentialsSecret: s3.CredentialsSecret,
+		}
+	}
+	return dst
+}
+
+func convertBackupStorageFrom(storage *v1beta1.BackupStorage) *BackupStorage {
+	if storage == nil {
+		return nil
+	}
+	dst := &BackupStorage{}
+	if storage.Local != nil {
+		dst.Local = &LocalBackupStorage{Path: storage.Local.Path}
+	}
+	if s3 := storage.S3; s3 != nil {
+		dst.S3 = &S3BackupStorage{
+			Endpoint:          s3.Endpoint,
+			Bucket:            s3.Bucket,
+			Prefix:            s3.Prefix,
+			Region:            s3.Region,
+			CredentialsSecret: s3.CredentialsSecret,
+		}
+	}
+	return dst
+}
+
+// convertRolloutStrategyTo converts the rollout strategy of the rolling update to the v1beta1 hub version
+func convertRolloutStrategyTo(strategy *RolloutStrategy) *v1beta1.RolloutStrategy {
+	if strategy == nil {
+		return nil
+	}
+	dst := &v1beta1.RolloutStrategy{
+		Partition:        strategy.Partition,
+		PauseAfterShards: strategy.PauseAfterShards,
+		AutoPromote:      strategy.AutoPromote,
+		PauseSeconds:     strategy.PauseSeconds,
+		Abort:            strategy.Abort,
+	}
+	if strategy.HealthGates != nil {
+		dst.HealthGates = &v1beta1.RolloutHealthGates{MaxErrorRatePercent: strategy.HealthGates.MaxErrorRatePercent}
+	}
+	return dst
+}
+
+func convertRolloutStrategyFrom(strategy *v1beta1.RolloutStrategy) *RolloutStrategy {
+	if strategy == nil {
+		return nil
+	}
+	dst := &RolloutStrategy{
+		Partition:        strategy.Partition,
+		PauseAfterShards: strategy.PauseAfterShards,
+		AutoPromote:      strategy.AutoPromote,
+		PauseSeconds:     strategy.PauseSeconds,
+		Abort:            strategy.Abort,
+	}
+	if strategy.HealthGates != nil {
+		dst.HealthGates = &RolloutHealthGates{MaxErrorRatePercent: strategy.HealthGates.MaxErrorRatePercent}
+	}
+	return dst
+}
+
+// parseSlotRange parses a slot range of the node status, either a single slot "42" or a range "42-52"
+func parseSlotRange(slots string) (v1beta1.SlotRange, error) {
+	bounds := strings.SplitN(slots, "-", 2)
+	start, err := strconv.ParseInt(bounds[0], 10, 32)
+	if err != nil {
+		return v1beta1.SlotRange{}, fmt.Errorf("invalid slot range %q", slots)
+	}
+	end := start
+	if len(bounds) == 2 {
+		if end, err = strconv.ParseInt(bounds[1], 10, 32); err != nil {
+			return v1beta1.SlotRange{}, fmt.Errorf("invalid slot range %q", slots)
+		}
+	}
+	return v1beta1.SlotRange{Start: int32(start), End: int32(end)}, nil
+}
+
+// conditionReason returns the reason in the CamelCase format required by metav1.Condition,
+// for instance "cluster needs more pods" becomes "ClusterNeedsMorePods"
+func conditionReason(reason string) string {
+	if conditionReasonRegexp.MatchString(reason) {
+		return reason
+	}
+	var camelCase strings.Builder
+	for _, word := range strings.FieldsFunc(reason, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		camelCase.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if !conditionReasonRegexp.MatchString(camelCase.String()) {
+		return "Unknown"
+	}
+	return camelCase.String()
+}
@@ -0,0 +1,151 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM/operator-for-redis-cluster/api/v1beta1"
+)
+
+func TestRedisCluster_Conversion(t *testing.T) {
+	now := metav1.NewTime(time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(now.Add(time.Minute))
+	rc := newValidRedisCluster()
+	rc.Spec.Auth = &RedisAuth{SecretName: "auth"}
+	rc.Spec.Paused = true
+	rc.Spec.DeletionProtection = true
+	rc.Spec.TopologyKeys = []string{"topology.kubernetes.io/zone", "kubernetes.io/hostname"}
+	rc.Spec.FinalBackup = &BackupStorage{Local: &LocalBackupStorage{Path: "/backups"}}
+	rc.Spec.RollingUpdate.WarmingDelayMillis = 100
+	rc.Spec.RollingUpdate.Method = RollingUpdateMethodFailover
+	rc.Spec.RollingUpdate.MaxSurge = proto.Int32(0)
+	rc.Spec.RollingUpdate.MaxUnavailableShards = proto.Int32(2)
+	rc.Spec.RollingUpdate.Strategy = &RolloutStrategy{Partition: 1, PauseAfterShards: 1, AutoPromote: true, PauseSeconds: proto.Int32(30), HealthGates: &RolloutHealthGates{MaxErrorRatePercent: proto.Int32(2)}}
+	rc.Spec.Scaling.BalanceBy = BalanceByMemory
+	rc.Spec.RestoreFrom = &RedisClusterRestore{Storage: &BackupStorage{S3: &S3BackupStorage{Bucket: "backups", CredentialsSecret: "s3"}}, Path: "ns/backup"}
+	rc.Status = RedisClusterStatus{
+		StartTime: &now,
+		Conditions: []RedisClusterCondition{
+			{Type: RedisClusterOK, Status: kapiv1.ConditionTrue, LastProbeTime: now, LastTransitionTime: now, Reason: "ClusterIsCorrectlyConfigured", Message: "cluster is correctly configured"},
+			{Type: RedisClusterScaling, Status: kapiv1.ConditionTrue, LastProbeTime: later, LastTransitionTime: now, Reason: "cluster needs more pods", Message: "cluster needs more pods"},
+			{Type: RedisClusterRollingUpdate, Status: kapiv1.ConditionFalse, LastProbeTime: later},
+		},
+		Cluster: RedisClusterState{
+			Status:                     ClusterStatusOK,
+			NumberOfPrimaries:          2,
+			NumberOfPrimariesReady:     2,
+			NumberOfReplicasPerPrimary: map[string]int{"primary1": 0, "primary2": 0},
+			NodesPlacement:             NodesPlacementInfoOptimal,
+			NumberOfPods:               2,
+			NumberOfPodsReady:          2,
+			NumberOfRedisNodesRunning:  2,
+			LabelSelectorPath:          "redis-operator.k8s.io/cluster-name=cluster",
+			Nodes: []RedisClusterNode{
+				{ID: "primary1", Role: RedisClusterNodeRolePrimary, IP: "10.0.0.1", Port: "6379", PodName: "pod1", Slots: []string{"0-8190", "8191-8191"}, Stats: &RedisNodeStats{UsedMemory: 1024, Keys: 10, OpsPerSec: 5}},
+				{ID: "primary2", Role: RedisClusterNodeRolePrimary, IP: "10.0.0.2", Port: "6379", PodName: "pod2", Slots: []string{"8192-16383"}},
+			},
+		},
+		Restore:            &RestoreStatus{Phase: RestorePhaseRunning, Shards: 2, ShardsRestored: 1, KeysRestored: 10, ShardKeysRead: 4, Retries: 1},
+		Progress:           &ProgressStatus{SlotsPlanned: 100, SlotsMigrated: 40, KeysMoved: 1000},
+		ObservedGeneration: 3,
+		Config:             &ConfigStatus{RestartHash: "0cc175b9c0f1b6a831c399e269772661", BaselineRestartHash: "92eb5ffee6ae2fec3ad71c777531578f", PendingKeys: []string{"databases"}},
+		Rollout:            &RolloutStatus{PodTemplateHash: "hash", Phase: RolloutPhasePaused, TotalShards: 2, UpdatedShards: 1, PauseTime: &now, HealthCounters: map[string]RolloutCounters{"pod1": {CommandsProcessed: 100, ErrorReplies: 1}}},
+	}
+
+	hub := &v1beta1.RedisCluster{}
+	if err := rc.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() unexpected error: %v", err)
+	}
+	if hub.Status.Phase != v1beta1.ClusterPhaseOK || hub.Status.ReadyPrimaries != 2 || hub.Status.Selector != rc.Status.Cluster.LabelSelectorPath {
+		t.Errorf("ConvertTo() unexpected status: %v", hub.Status)
+	}
+	if !reflect.DeepEqual(hub.Status.Nodes[0].Slots, []v1beta1.SlotRange{{Start: 0, End: 8190}, {Start: 8191, End: 8191}}) {
+		t.Errorf("ConvertTo() slots = %v, want 0-8190 and 8191-8191", hub.Status.Nodes[0].Slots)
+	}
+	if reason := hub.Status.Conditions[1].Reason; reason != "ClusterNeedsMorePods" {
+		t.Errorf("ConvertTo() condition reason = %q, want ClusterNeedsMorePods", reason)
+	}
+	if transition := hub.Status.Conditions[2].LastTransitionTime; !transition.Equal(&later) {
+		t.Errorf("ConvertTo() condition transition time = %v, want the probe time %v", transition, later)
+	}
+	if *hub.Spec.RollingUpdate.SlotBatchSize != *rc.Spec.RollingUpdate.SlotBatchSize || hub.Spec.RestoreFrom.Storage.S3.Bucket != "backups" {
+		t.Errorf("ConvertTo() unexpected spec: %v", hub.Spec)
+	}
+
+	converted := &RedisCluster{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(converted, rc) {
+		t.Errorf("ConvertFrom() = %v, want %v", converted, rc)
+	}
+}
+
+func TestRedisCluster_ConvertFromModifiedConditions(t *testing.T) {
+	now := metav1.NewTime(time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(now.Add(time.Minute))
+	rc := newValidRedisCluster()
+	rc.Status.Conditions = []RedisClusterCondition{
+		{Type: RedisClusterScaling, Status: kapiv1.ConditionTrue, LastProbeTime: later, LastTransitionTime: now, Reason: "cluster needs more pods"},
+	}
+	hub := &v1beta1.RedisCluster{}
+	if err := rc.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() unexpected error: %v", err)
+	}
+	hub.Status.Conditions[0].Status = metav1.ConditionFalse
+	hub.Status.Conditions[0].Reason = "ClusterScaled"
+	hub.Status.Conditions[0].LastTransitionTime = later
+
+	converted := &RedisCluster{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() unexpected error: %v", err)
+	}
+	want := []RedisClusterCondition{
+		{Type: RedisClusterScaling, Status: kapiv1.ConditionFalse, LastProbeTime: later, LastTransitionTime: later, Reason: "ClusterScaled"},
+	}
+	if !reflect.DeepEqual(converted.Status.Conditions, want) {
+		t.Errorf("ConvertFrom() conditions = %v, want %v", converted.Status.Conditions, want)
+	}
+	if _, ok := converted.Annotations[ConditionsAnnotationKey]; ok {
+		t.Errorf("ConvertFrom() should remove the %s annotation", ConditionsAnnotationKey)
+	}
+}
+
+func TestRedisCluster_ConvertToErrors(t *testing.T) {
+	rc := newValidRedisCluster()
+	rc.Status.Cluster.Nodes = []RedisClusterNode{{ID: "primary1", Slots: []string{"[42->-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]"}}}
+	if err := rc.ConvertTo(&v1beta1.RedisCluster{}); err == nil {
+		t.Errorf("ConvertTo() should return an error with an invalid slot range")
+	}
+}
+
+func Test_conditionReason(t *testing.T) {
+	tests := map[string]string{
+		"ClusterOK":                       "ClusterOK",
+		"cluster needs more pods":         "ClusterNeedsMorePods",
+		"cluster is correctly configured": "ClusterIsCorrectlyConfigured",
+		"":                                "Unknown",
+		"42":                              "Unknown",
+	}
+	for reason, want := range tests {
+		if got := conditionReason(reason); got != want {
+			t.Errorf("conditionReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
+
+func TestRedisCluster_ConvertFromDefaults(t *testing.T) {
+	hub := &v1beta1.RedisCluster{Spec: v1beta1.RedisClusterSpec{NumberOfPrimaries: proto.Int32(3)}}
+	rc := &RedisCluster{}
+	if err := rc.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() unexpected error: %v", err)
+	}
+	if *rc.Spec.NumberOfPrimaries != 3 || rc.Spec.Scaling != nil || rc.Spec.RollingUpdate != nil || rc.Status.Cluster.Nodes != nil {
+		t.Errorf("ConvertFrom() unexpected cluster: %v", rc)
+	}
+}
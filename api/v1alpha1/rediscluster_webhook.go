@@ -0,0 +1,167 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	kapiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/mutate-db-ibm-com-v1alpha1-rediscluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=db.ibm.com,resources=redisclusters,verbs=create;update,versions=v1alpha1,name=mrediscluster.db.ibm.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-db-ibm-com-v1alpha1-rediscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=db.ibm.com,resources=redisclusters,verbs=create;update;delete,versions=v1alpha1,name=vrediscluster.db.ibm.com,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &RedisCluster{}
+var _ webhook.Validator = &RedisCluster{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the RedisCluster in the manager webhook server
+func (rc *RedisCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(rc).Complete()
+}
+
+// ValidateCreate validates the RedisCluster spec on creation
+func (rc *RedisCluster) ValidateCreate() error {
+	return rc.toAPIError(rc.validateSpec())
+}
+
+// ValidateUpdate validates the RedisCluster spec and the transition from the old spec
+func (rc *RedisCluster) ValidateUpdate(old runtime.Object) error {
+	oldCluster, ok := old.(*RedisCluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a RedisCluster, got %T", old))
+	}
+	allErrs := rc.validateSpec()
+	if oldCluster.GetServiceName() != rc.GetServiceName() {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "serviceName"), fmt.Sprintf("cannot be changed from %q once the cluster is created", oldCluster.GetServiceName())))
+	}
+	// the TLS and plaintext nodes cannot join the same cluster bus, so a rolling update cannot switch between them
+	if oldCluster.isTLSEnabled() != rc.isTLSEnabled() {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "tls"), "cannot be enabled or disabled once the cluster is created"))
+	}
+	return rc.toAPIError(allErrs)
+}
+
+// ValidateDelete rejects the deletion of a RedisCluster protected by deletionProtection
+func (rc *RedisCluster) ValidateDelete() error {
+	if rc.Spec.DeletionProtection {
+		return apierrors.NewForbidden(GroupVersion.WithResource(ResourcePlural).GroupResource(), rc.Name, fmt.Errorf("spec.deletionProtection must be cleared before deleting the cluster"))
+	}
+	return nil
+}
+
+func (rc *RedisCluster) isTLSEnabled() bool {
+	return rc.Spec.TLS != nil && rc.Spec.TLS.SecretName != ""
+}
+
+// GetServiceName returns the name of the service fronting the redis nodes, the RedisCluster name if ServiceName is empty
+func (rc *RedisCluster) GetServiceName() string {
+	if rc.Spec.ServiceName != "" {
+		return rc.Spec.ServiceName
+	}
+	return rc.Name
+}
+
+func (rc *RedisCluster) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	if rc.Spec.NumberOfPrimaries != nil && *rc.Spec.NumberOfPrimaries < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("numberOfPrimaries"), *rc.Spec.NumberOfPrimaries, "must be greater than 0"))
+	}
+	if rc.Spec.ReplicationFactor != nil && *rc.Spec.ReplicationFactor < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicationFactor"), *rc.Spec.ReplicationFactor, "must be greater than or equal to 0"))
+	}
+	if rc.Spec.RollingUpdate != nil {
+		allErrs = append(allErrs, validateMigration(&rc.Spec.RollingUpdate.Migration, specPath.Child("rollingUpdate"))...)
+		if rc.Spec.RollingUpdate.WarmingDelayMillis < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("rollingUpdate", "warmingDelayMillis"), rc.Spec.RollingUpdate.WarmingDelayMillis, "must be greater than or equal to 0"))
+		}
+		if maxSurge := rc.Spec.RollingUpdate.MaxSurge; maxSurge != nil && *maxSurge < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("rollingUpdate", "maxSurge"), *maxSurge, "must be greater than or equal to 0"))
+		}
+		if maxUnavailable := rc.Spec.RollingUpdate.MaxUnavailableShards; maxUnavailable != nil && *maxUnavailable < 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("rollingUpdate", "maxUnavailableShards"), *maxUnavailable, "must be greater than 0"))
+		}
+		switch rc.Spec.RollingUpdate.Method {
+		case "", RollingUpdateMethodMigration, RollingUpdateMethodFailover:
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("rollingUpdate", "method"), rc.Spec.RollingUpdate.Method, []string{string(RollingUpdateMethodMigration), string(RollingUpdateMethodFailover)}))
+		}
+	}
+	if rc.Spec.Scaling != nil {
+		allErrs = append(allErrs, validateMigration(&rc.Spec.Scaling.Migration, specPath.Child("scaling"))...)
+		switch rc.Spec.Scaling.BalanceBy {
+		case "", BalanceBySlots, BalanceByKeys, BalanceByMemory:
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("scaling", "balanceBy"), rc.Spec.Scaling.BalanceBy, []string{string(BalanceBySlots), string(BalanceByKeys), string(BalanceByMemory)}))
+		}
+	}
+	topologyKeys := make(map[string]bool)
+	for i, key := range rc.Spec.TopologyKeys {
+		keyPath := specPath.Child("topologyKeys").Index(i)
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(keyPath, key, msg))
+		}
+		if topologyKeys[key] {
+			allErrs = append(allErrs, field.Duplicate(keyPath, key))
+		}
+		topologyKeys[key] = true
+	}
+	allErrs = append(allErrs, validatePodTemplate(rc.Spec.PodTemplate, specPath.Child("podTemplate"))...)
+	if restore := rc.Spec.RestoreFrom; restore != nil {
+		restorePath := specPath.Child("restoreFrom")
+		if restore.BackupName != "" && (restore.Storage != nil || restore.Path != "") {
+			allErrs = append(allErrs, field.Invalid(restorePath, restore.BackupName, "backupName cannot be set along with storage and path"))
+		} else if restore.BackupName == "" && (restore.Storage == nil || restore.Path == "") {
+			allErrs = append(allErrs, field.Required(restorePath, "either backupName, or storage and path must be set"))
+		}
+	}
+	if rc.Spec.FinalBackup != nil && rc.Spec.FinalBackup.Local == nil && rc.Spec.FinalBackup.S3 == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("finalBackup"), "either local or s3 must be set"))
+	}
+	return allErrs
+}
+
+func validateMigration(migration *Migration, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if migration.KeyBatchSize != nil && *migration.KeyBatchSize < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("keyBatchSize"), *migration.KeyBatchSize, "must be greater than 0"))
+	}
+	if migration.SlotBatchSize != nil && *migration.SlotBatchSize < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("slotBatchSize"), *migration.SlotBatchSize, "must be greater than 0"))
+	}
+	if migration.IdleTimeoutMillis != nil && *migration.IdleTimeoutMillis < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("idleTimeoutMillis"), *migration.IdleTimeoutMillis, "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
+
+// validatePodTemplate checks that the pod template runs the redis-server process in a redis-node container exposing a redis port
+func validatePodTemplate(podTemplate *kapiv1.PodTemplateSpec, path *field.Path) field.ErrorList {
+	if podTemplate == nil {
+		return field.ErrorList{field.Required(path, "the pod template must contain a "+RedisNodeContainerName+" container")}
+	}
+	containersPath := path.Child("spec", "containers")
+	for i, container := range podTemplate.Spec.Containers {
+		if container.Name != RedisNodeContainerName {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.Name == RedisPortName {
+				return nil
+			}
+		}
+		return field.ErrorList{field.Required(containersPath.Index(i).Child("ports"), "the "+RedisNodeContainerName+" container must expose a port named "+RedisPortName)}
+	}
+	return field.ErrorList{field.Required(containersPath, "the pod template must contain a "+RedisNodeContainerName+" container")}
+}
+
+func (rc *RedisCluster) toAPIError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(ResourceKind).GroupKind(), rc.Name, allErrs)
+}
@@ -0,0 +1,163 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	kapiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newValidRedisCluster() *RedisCluster {
+	rc := &RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "ns"},
+		Spec: RedisClusterSpec{
+			PodTemplate: &kapiv1.PodTemplateSpec{
+				Spec: kapiv1.PodSpec{
+					Containers: []kapiv1.Container{{
+						Name:  RedisNodeContainerName,
+						Ports: []kapiv1.ContainerPort{{Name: RedisPortName, ContainerPort: 6379}},
+					}},
+				},
+			},
+		},
+	}
+	rc.Default()
+	return rc
+}
+
+func TestRedisCluster_Default(t *testing.T) {
+	rc := &RedisCluster{}
+	rc.Default()
+	if *rc.Spec.NumberOfPrimaries != 3 || *rc.Spec.ReplicationFactor != 1 {
+		t.Errorf("Default() numberOfPrimaries = %d, replicationFactor = %d, want 3 and 1", *rc.Spec.NumberOfPrimaries, *rc.Spec.ReplicationFactor)
+	}
+	if *rc.Spec.Scaling.SlotBatchSize != 16 || rc.Spec.Scaling.BalanceBy != BalanceBySlots || *rc.Spec.RollingUpdate.KeyBatchSize != 10000 || !*rc.Spec.RollingUpdate.KeyMigration || rc.Spec.RollingUpdate.Method != RollingUpdateMethodMigration {
+		t.Errorf("Default() unexpected migration defaults: scaling %v, rollingUpdate %v", rc.Spec.Scaling, rc.Spec.RollingUpdate)
+	}
+	*rc.Spec.Scaling.SlotBatchSize = 1
+	other := &RedisCluster{}
+	other.Default()
+	if *other.Spec.Scaling.SlotBatchSize != 16 {
+		t.Errorf("Default() the default values should not be shared between clusters")
+	}
+}
+
+func TestRedisCluster_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(rc *RedisCluster)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(rc *RedisCluster) {},
+		},
+		{
+			name:    "negative replication factor",
+			mutate:  func(rc *RedisCluster) { rc.Spec.ReplicationFactor = proto.Int32(-1) },
+			wantErr: "spec.replicationFactor",
+		},
+		{
+			name:    "zero slot batch size",
+			mutate:  func(rc *RedisCluster) { rc.Spec.Scaling.SlotBatchSize = proto.Int32(0) },
+			wantErr: "spec.scaling.slotBatchSize",
+		},
+		{
+			name:    "unsupported balancing",
+			mutate:  func(rc *RedisCluster) { rc.Spec.Scaling.BalanceBy = "cpu" },
+			wantErr: "spec.scaling.balanceBy",
+		},
+		{
+			name:    "zero unavailable shards",
+			mutate:  func(rc *RedisCluster) { rc.Spec.RollingUpdate.MaxUnavailableShards = proto.Int32(0) },
+			wantErr: "spec.rollingUpdate.maxUnavailableShards",
+		},
+		{
+			name:    "unsupported rolling update method",
+			mutate:  func(rc *RedisCluster) { rc.Spec.RollingUpdate.Method = "recreate" },
+			wantErr: "spec.rollingUpdate.method",
+		},
+		{
+			name:    "duplicate topology key",
+			mutate:  func(rc *RedisCluster) { rc.Spec.TopologyKeys = []string{"rack", "rack"} },
+			wantErr: "spec.topologyKeys[1]",
+		},
+		{
+			name:    "invalid topology key",
+			mutate:  func(rc *RedisCluster) { rc.Spec.TopologyKeys = []string{"rack/"} },
+			wantErr: "spec.topologyKeys[0]",
+		},
+		{
+			name:    "missing redis-node container",
+			mutate:  func(rc *RedisCluster) { rc.Spec.PodTemplate.Spec.Containers[0].Name = "redis" },
+			wantErr: "spec.podTemplate.spec.containers",
+		},
+		{
+			name:    "missing redis port",
+			mutate:  func(rc *RedisCluster) { rc.Spec.PodTemplate.Spec.Containers[0].Ports[0].Name = "http" },
+			wantErr: "spec.podTemplate.spec.containers[0].ports",
+		},
+		{
+			name:    "restore without backup name nor path",
+			mutate:  func(rc *RedisCluster) { rc.Spec.RestoreFrom = &RedisClusterRestore{} },
+			wantErr: "spec.restoreFrom",
+		},
+		{
+			name:    "final backup without storage",
+			mutate:  func(rc *RedisCluster) { rc.Spec.FinalBackup = &BackupStorage{} },
+			wantErr: "spec.finalBackup",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := newValidRedisCluster()
+			tt.mutate(rc)
+			err := rc.ValidateCreate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCreate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCreate() error = %v, want an error on %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedisCluster_ValidateUpdate(t *testing.T) {
+	old := newValidRedisCluster()
+	rc := newValidRedisCluster()
+	rc.Spec.ServiceName = old.Name
+	if err := rc.ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() setting the default service name unexpected error: %v", err)
+	}
+	rc.Spec.ServiceName = "other"
+	if err := rc.ValidateUpdate(old); err == nil || !strings.Contains(err.Error(), "spec.serviceName") {
+		t.Errorf("ValidateUpdate() error = %v, want an error on spec.serviceName", err)
+	}
+	rc.Spec.ServiceName = ""
+	rc.Spec.TLS = &RedisTLS{SecretName: "redis-tls"}
+	if err := rc.ValidateUpdate(old); err == nil || !strings.Contains(err.Error(), "spec.tls") {
+		t.Errorf("ValidateUpdate() error = %v, want an error on spec.tls", err)
+	}
+	old.Spec.TLS = &RedisTLS{SecretName: "other-tls"}
+	if err := rc.ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() changing the TLS secret unexpected error: %v", err)
+	}
+}
+
+func TestRedisCluster_ValidateDelete(t *testing.T) {
+	rc := newValidRedisCluster()
+	if err := rc.ValidateDelete(); err != nil {
+		t.Errorf("ValidateDelete() unexpected error: %v", err)
+	}
+	rc.Spec.DeletionProtection = true
+	if err := rc.ValidateDelete(); !apierrors.IsForbidden(err) {
+		t.Errorf("ValidateDelete() error = %v, want a forbidden error", err)
+	}
+}
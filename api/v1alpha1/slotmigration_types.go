@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisSlotMigration represents the migration of slots of a Redis Cluster to a destination primary
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,shortName=rdsm
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="Destination",type=string,JSONPath=`.status.destinationID`
+// +kubebuilder:printcolumn:name="Migrated",type=integer,JSONPath=`.status.migratedSlots`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.totalSlots`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RedisSlotMigration struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired RedisSlotMigration specification
+	Spec RedisSlotMigrationSpec `json:"spec,omitempty"`
+
+	// Status represents the current RedisSlotMigration status
+	Status RedisSlotMigrationStatus `json:"status,omitempty"`
+}
+
+// RedisSlotMigrationList implements list of RedisSlotMigration.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RedisSlotMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of RedisSlotMigration
+	Items []RedisSlotMigration `json:"items"`
+}
+
+// RedisSlotMigrationSpec contains RedisSlotMigration specification
+type RedisSlotMigrationSpec struct {
+	// ClusterName name of the RedisCluster owning the slots, in the same namespace
+	ClusterName string `json:"clusterName"`
+
+	// Slots slot ranges to migrate, for instance 42 or 100-200
+	Slots []string `json:"slots"`
+
+	// Destination primary node receiving the slots
+	Destination SlotMigrationDestination `json:"destination"`
+}
+
+// SlotMigrationDestination identifies the destination primary node, exactly one field must be set
+type SlotMigrationDestination struct {
+	// NodeID ID of the destination primary node
+	NodeID string `json:"nodeID,omitempty"`
+
+	// PodName name of the pod running the destination primary node
+	PodName string `json:"podName,omitempty"`
+}
+
+// RedisSlotMigrationStatus contains RedisSlotMigration status
+type RedisSlotMigrationStatus struct {
+	// Phase of the migration
+	Phase SlotMigrationPhase `json:"phase,omitempty"`
+
+	// StartTime time when the migration started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime time when the migration completed or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message human-readable message indicating details about the phase
+	Message string `json:"message,omitempty"`
+
+	// DestinationID ID of the destination primary node, resolved when the migration starts
+	DestinationID string `json:"destinationID,omitempty"`
+
+	// TotalSlots number of slots to migrate
+	TotalSlots int32 `json:"totalSlots,omitempty"`
+
+	// MigratedSlots number of slots owned by the destination primary node
+	MigratedSlots int32 `json:"migratedSlots,omitempty"`
+
+	// Slots progress of the migration of each slot range
+	Slots []SlotRangeMigrationStatus `json:"slots,omitempty"`
+}
+
+// SlotRangeMigrationStatus contains the migration status of a range of slots owned by the same source primary node
+type SlotRangeMigrationStatus struct {
+	// Slots slot range, for instance 42 or 100-115
+	Slots string `json:"slots"`
+
+	// SourceID ID of the primary node owning the slots before the migration
+	SourceID string `json:"sourceID,omitempty"`
+
+	// Phase of the migration of the slots
+	Phase SlotMigrationPhase `json:"phase,omitempty"`
+
+	// Message human-readable message indicating details about the phase
+	Message string `json:"message,omitempty"`
+}
+
+// SlotMigrationPhase is the phase of a RedisSlotMigration, or of one of its slot ranges
+type SlotMigrationPhase string
+
+const (
+	// SlotMigrationPhasePending the migration waits for the cluster, or the slots are not migrated yet
+	SlotMigrationPhasePending SlotMigrationPhase = "Pending"
+	// SlotMigrationPhaseRunning the slots are being migrated
+	SlotMigrationPhaseRunning SlotMigrationPhase = "Running"
+	// SlotMigrationPhaseCompleted the slots are owned by the destination primary node
+	SlotMigrationPhaseCompleted SlotMigrationPhase = "Completed"
+	// SlotMigrationPhaseFailed the migration failed
+	SlotMigrationPhaseFailed SlotMigrationPhase = "Failed"
+)
@@ -10,6 +10,96 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupShardStatus) DeepCopyInto(out *BackupShardStatus) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupShardStatus.
+func (in *BackupShardStatus) DeepCopy() *BackupShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorage) DeepCopyInto(out *BackupStorage) {
+	*out = *in
+	if in.Local != nil {
+		in, out := &in.Local, &out.Local
+		*out = new(LocalBackupStorage)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupStorage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorage.
+func (in *BackupStorage) DeepCopy() *BackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigStatus) DeepCopyInto(out *ConfigStatus) {
+	*out = *in
+	if in.PendingKeys != nil {
+		in, out := &in.PendingKeys, &out.PendingKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedKeys != nil {
+		in, out := &in.FailedKeys, &out.FailedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftedNodes != nil {
+		in, out := &in.DriftedNodes, &out.DriftedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigStatus.
+func (in *ConfigStatus) DeepCopy() *ConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalBackupStorage) DeepCopyInto(out *LocalBackupStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalBackupStorage.
+func (in *LocalBackupStorage) DeepCopy() *LocalBackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalBackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Migration) DeepCopyInto(out *Migration) {
 	*out = *in
@@ -40,6 +130,48 @@ func (in *Migration) DeepCopy() *Migration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressStatus) DeepCopyInto(out *ProgressStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressStatus.
+func (in *ProgressStatus) DeepCopy() *ProgressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisAuth) DeepCopyInto(out *RedisAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisAuth.
+func (in *RedisAuth) DeepCopy() *RedisAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisCluster) DeepCopyInto(out *RedisCluster) {
 	*out = *in
@@ -68,48 +200,58 @@ func (in *RedisCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterCondition) DeepCopyInto(out *RedisClusterCondition) {
+func (in *RedisClusterAutoscaler) DeepCopyInto(out *RedisClusterAutoscaler) {
 	*out = *in
-	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterCondition.
-func (in *RedisClusterCondition) DeepCopy() *RedisClusterCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterAutoscaler.
+func (in *RedisClusterAutoscaler) DeepCopy() *RedisClusterAutoscaler {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterCondition)
+	out := new(RedisClusterAutoscaler)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterAutoscaler) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
+func (in *RedisClusterAutoscalerList) DeepCopyInto(out *RedisClusterAutoscalerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]RedisCluster, len(*in))
+		*out = make([]RedisClusterAutoscaler, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterList.
-func (in *RedisClusterList) DeepCopy() *RedisClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterAutoscalerList.
+func (in *RedisClusterAutoscalerList) DeepCopy() *RedisClusterAutoscalerList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterList)
+	out := new(RedisClusterAutoscalerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RedisClusterList) DeepCopyObject() runtime.Object {
+func (in *RedisClusterAutoscalerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -117,155 +259,787 @@ func (in *RedisClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterNode) DeepCopyInto(out *RedisClusterNode) {
+func (in *RedisClusterAutoscalerSpec) DeepCopyInto(out *RedisClusterAutoscalerSpec) {
 	*out = *in
-	if in.Slots != nil {
-		in, out := &in.Slots, &out.Slots
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.TargetMemoryUtilization != nil {
+		in, out := &in.TargetMemoryUtilization, &out.TargetMemoryUtilization
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Pod != nil {
-		in, out := &in.Pod, &out.Pod
-		*out = new(v1.Pod)
-		(*in).DeepCopyInto(*out)
+	if in.TargetOpsPerSec != nil {
+		in, out := &in.TargetOpsPerSec, &out.TargetOpsPerSec
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CooldownSeconds != nil {
+		in, out := &in.CooldownSeconds, &out.CooldownSeconds
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterNode.
-func (in *RedisClusterNode) DeepCopy() *RedisClusterNode {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterAutoscalerSpec.
+func (in *RedisClusterAutoscalerSpec) DeepCopy() *RedisClusterAutoscalerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterNode)
+	out := new(RedisClusterAutoscalerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+func (in *RedisClusterAutoscalerStatus) DeepCopyInto(out *RedisClusterAutoscalerStatus) {
 	*out = *in
-	if in.NumberOfPrimaries != nil {
-		in, out := &in.NumberOfPrimaries, &out.NumberOfPrimaries
+	if in.MemoryUtilization != nil {
+		in, out := &in.MemoryUtilization, &out.MemoryUtilization
 		*out = new(int32)
 		**out = **in
 	}
-	if in.ReplicationFactor != nil {
-		in, out := &in.ReplicationFactor, &out.ReplicationFactor
-		*out = new(int32)
-		**out = **in
-	}
-	if in.PodTemplate != nil {
-		in, out := &in.PodTemplate, &out.PodTemplate
-		*out = new(v1.PodTemplateSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ZoneAwareReplication != nil {
-		in, out := &in.ZoneAwareReplication, &out.ZoneAwareReplication
-		*out = new(bool)
-		**out = **in
-	}
-	if in.RollingUpdate != nil {
-		in, out := &in.RollingUpdate, &out.RollingUpdate
-		*out = new(RollingUpdate)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Scaling != nil {
-		in, out := &in.Scaling, &out.Scaling
-		*out = new(Migration)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AdditionalLabels != nil {
-		in, out := &in.AdditionalLabels, &out.AdditionalLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
-func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterAutoscalerStatus.
+func (in *RedisClusterAutoscalerStatus) DeepCopy() *RedisClusterAutoscalerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterSpec)
+	out := new(RedisClusterAutoscalerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterState) DeepCopyInto(out *RedisClusterState) {
+func (in *RedisClusterBackup) DeepCopyInto(out *RedisClusterBackup) {
 	*out = *in
-	if in.NumberOfReplicasPerPrimary != nil {
-		in, out := &in.NumberOfReplicasPerPrimary, &out.NumberOfReplicasPerPrimary
-		*out = make(map[string]int, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterBackup.
+func (in *RedisClusterBackup) DeepCopy() *RedisClusterBackup {
+	if in == nil {
+		return nil
 	}
-	if in.Nodes != nil {
-		in, out := &in.Nodes, &out.Nodes
-		*out = make([]RedisClusterNode, len(*in))
+	out := new(RedisClusterBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterBackupList) DeepCopyInto(out *RedisClusterBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisClusterBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterState.
-func (in *RedisClusterState) DeepCopy() *RedisClusterState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterBackupList.
+func (in *RedisClusterBackupList) DeepCopy() *RedisClusterBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterState)
+	out := new(RedisClusterBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+func (in *RedisClusterBackupSpec) DeepCopyInto(out *RedisClusterBackupSpec) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]RedisClusterCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
 	}
-	in.Cluster.DeepCopyInto(&out.Cluster)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
-func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterBackupSpec.
+func (in *RedisClusterBackupSpec) DeepCopy() *RedisClusterBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisClusterStatus)
+	out := new(RedisClusterBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RollingUpdate) DeepCopyInto(out *RollingUpdate) {
+func (in *RedisClusterBackupStatus) DeepCopyInto(out *RedisClusterBackupStatus) {
 	*out = *in
-	in.Migration.DeepCopyInto(&out.Migration)
-	if in.KeyMigration != nil {
-		in, out := &in.KeyMigration, &out.KeyMigration
-		*out = new(bool)
-		**out = **in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]BackupShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdate.
-func (in *RollingUpdate) DeepCopy() *RollingUpdate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterBackupStatus.
+func (in *RedisClusterBackupStatus) DeepCopy() *RedisClusterBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RollingUpdate)
+	out := new(RedisClusterBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterCondition) DeepCopyInto(out *RedisClusterCondition) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterCondition.
+func (in *RedisClusterCondition) DeepCopy() *RedisClusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterList) DeepCopyInto(out *RedisClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterList.
+func (in *RedisClusterList) DeepCopy() *RedisClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterNode) DeepCopyInto(out *RedisClusterNode) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(RedisNodeStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(v1.Pod)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterNode.
+func (in *RedisClusterNode) DeepCopy() *RedisClusterNode {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterRestore) DeepCopyInto(out *RedisClusterRestore) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(BackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterRestore.
+func (in *RedisClusterRestore) DeepCopy() *RedisClusterRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+	*out = *in
+	if in.NumberOfPrimaries != nil {
+		in, out := &in.NumberOfPrimaries, &out.NumberOfPrimaries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZoneAwareReplication != nil {
+		in, out := &in.ZoneAwareReplication, &out.ZoneAwareReplication
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(Scaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalLabels != nil {
+		in, out := &in.AdditionalLabels, &out.AdditionalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RedisAuth)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(RedisTLS)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(RedisStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(RedisClusterRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FinalBackup != nil {
+		in, out := &in.FinalBackup, &out.FinalBackup
+		*out = new(BackupStorage)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
+func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterState) DeepCopyInto(out *RedisClusterState) {
+	*out = *in
+	if in.NumberOfReplicasPerPrimary != nil {
+		in, out := &in.NumberOfReplicasPerPrimary, &out.NumberOfReplicasPerPrimary
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]RedisClusterNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterState.
+func (in *RedisClusterState) DeepCopy() *RedisClusterState {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RedisClusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	in.Cluster.DeepCopyInto(&out.Cluster)
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ProgressStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(ConfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
+func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisNodeStats) DeepCopyInto(out *RedisNodeStats) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisNodeStats.
+func (in *RedisNodeStats) DeepCopy() *RedisNodeStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisNodeStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSlotMigration) DeepCopyInto(out *RedisSlotMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSlotMigration.
+func (in *RedisSlotMigration) DeepCopy() *RedisSlotMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSlotMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisSlotMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSlotMigrationList) DeepCopyInto(out *RedisSlotMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisSlotMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSlotMigrationList.
+func (in *RedisSlotMigrationList) DeepCopy() *RedisSlotMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSlotMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisSlotMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSlotMigrationSpec) DeepCopyInto(out *RedisSlotMigrationSpec) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Destination = in.Destination
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSlotMigrationSpec.
+func (in *RedisSlotMigrationSpec) DeepCopy() *RedisSlotMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSlotMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSlotMigrationStatus) DeepCopyInto(out *RedisSlotMigrationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]SlotRangeMigrationStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSlotMigrationStatus.
+func (in *RedisSlotMigrationStatus) DeepCopy() *RedisSlotMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSlotMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisStorage) DeepCopyInto(out *RedisStorage) {
+	*out = *in
+	in.VolumeClaimTemplate.DeepCopyInto(&out.VolumeClaimTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisStorage.
+func (in *RedisStorage) DeepCopy() *RedisStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisTLS) DeepCopyInto(out *RedisTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisTLS.
+func (in *RedisTLS) DeepCopy() *RedisTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStatus.
+func (in *RestoreStatus) DeepCopy() *RestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdate) DeepCopyInto(out *RollingUpdate) {
+	*out = *in
+	in.Migration.DeepCopyInto(&out.Migration)
+	if in.KeyMigration != nil {
+		in, out := &in.KeyMigration, &out.KeyMigration
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnavailableShards != nil {
+		in, out := &in.MaxUnavailableShards, &out.MaxUnavailableShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdate.
+func (in *RollingUpdate) DeepCopy() *RollingUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutCounters) DeepCopyInto(out *RolloutCounters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutCounters.
+func (in *RolloutCounters) DeepCopy() *RolloutCounters {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutCounters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutHealthGates) DeepCopyInto(out *RolloutHealthGates) {
+	*out = *in
+	if in.MaxErrorRatePercent != nil {
+		in, out := &in.MaxErrorRatePercent, &out.MaxErrorRatePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutHealthGates.
+func (in *RolloutHealthGates) DeepCopy() *RolloutHealthGates {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutHealthGates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.PauseTime != nil {
+		in, out := &in.PauseTime, &out.PauseTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HealthCounters != nil {
+		in, out := &in.HealthCounters, &out.HealthCounters
+		*out = make(map[string]RolloutCounters, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.PauseSeconds != nil {
+		in, out := &in.PauseSeconds, &out.PauseSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthGates != nil {
+		in, out := &in.HealthGates, &out.HealthGates
+		*out = new(RolloutHealthGates)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupStorage) DeepCopyInto(out *S3BackupStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupStorage.
+func (in *S3BackupStorage) DeepCopy() *S3BackupStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scaling) DeepCopyInto(out *Scaling) {
+	*out = *in
+	in.Migration.DeepCopyInto(&out.Migration)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scaling.
+func (in *Scaling) DeepCopy() *Scaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Scaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlotMigrationDestination) DeepCopyInto(out *SlotMigrationDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlotMigrationDestination.
+func (in *SlotMigrationDestination) DeepCopy() *SlotMigrationDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(SlotMigrationDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlotRangeMigrationStatus) DeepCopyInto(out *SlotRangeMigrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlotRangeMigrationStatus.
+func (in *SlotRangeMigrationStatus) DeepCopy() *SlotRangeMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlotRangeMigrationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
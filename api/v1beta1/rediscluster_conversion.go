@@ -0,0 +1,4 @@
+package v1beta1
+
+// Hub marks v1beta1 as the conversion hub of the RedisCluster, the other versions are converted to and from it
+func (*RedisCluster) Hub() {}
@@ -0,0 +1,503 @@
+package v1beta1
+
+import (
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisCluster represents a Redis Cluster
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,shortName=rdc
+// +kubebuilder:subresource:scale:specpath=.spec.numberOfPrimaries,statuspath=.status.readyPrimaries,selectorpath=.status.selector
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Primaries",type=integer,JSONPath=`.status.primaries`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyPrimaries`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type RedisCluster struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired RedisCluster specification
+	Spec RedisClusterSpec `json:"spec,omitempty"`
+
+	// Status represents the observed RedisCluster status
+	Status RedisClusterStatus `json:"status,omitempty"`
+}
+
+// RedisClusterList implements list of RedisCluster.
+// +kubebuilder:object:root=true
+type RedisClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	// More info: http://releases.k8s.io/HEAD/docs/devel/api-conventions.md#metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of RedisCluster
+	Items []RedisCluster `json:"items"`
+}
+
+// RedisClusterSpec contains RedisCluster specification
+type RedisClusterSpec struct {
+	// NumberOfPrimaries number of primary nodes
+	NumberOfPrimaries *int32 `json:"numberOfPrimaries,omitempty"`
+
+	// ReplicationFactor number of replica nodes per primary node
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+
+	// ServiceName name of the kubernetes service that fronts the redis nodes, the RedisCluster name if empty
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// PodTemplate contains the pod specification that should run the redis-server process
+	PodTemplate *kapiv1.PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// ZoneAwareReplication spreads primary and replica nodes across all available zones
+	ZoneAwareReplication *bool `json:"zoneAwareReplication,omitempty"`
+
+	// TopologyKeys ordered list of the node labels the primary and replica nodes are spread across, the zone label
+	// topology.kubernetes.io/zone by default. The nodes are spread across the first label values, then across the
+	// values of the next labels, e.g. the zone then the host.
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
+
+	// AdditionalLabels labels added to the resources created for the RedisCluster
+	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
+
+	// Scaling strategy used when primaries are added or removed
+	Scaling *ScalingStrategy `json:"scaling,omitempty"`
+
+	// RollingUpdate strategy used when the redis nodes are replaced
+	RollingUpdate *RollingUpdateStrategy `json:"rollingUpdate,omitempty"`
+
+	// Auth references the secret holding the credentials used to connect to the redis nodes
+	Auth *RedisAuth `json:"auth,omitempty"`
+
+	// TLS references the secret holding the certificates used to encrypt the client, replication and cluster bus traffic
+	TLS *RedisTLS `json:"tls,omitempty"`
+
+	// Storage configuration for persistent redis data, each redis node keeps its data folder and node identity in its own persistent volume claim
+	Storage *RedisStorage `json:"storage,omitempty"`
+
+	// RestoreFrom backup loaded in the cluster when it is created
+	RestoreFrom *RedisClusterRestore `json:"restoreFrom,omitempty"`
+
+	// Paused suspends the reconciliation of the cluster, the status is refreshed but the redis nodes and pods are not modified
+	Paused bool `json:"paused,omitempty"`
+
+	// DeletionProtection blocks the deletion of the RedisCluster until it is cleared. The validating webhook rejects the deletion,
+	// without it the deletion is recorded but the cluster keeps running and its teardown starts once the flag is cleared
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// FinalBackup storage where a backup of the cluster is taken when it is deleted, before its pods are deleted
+	FinalBackup *BackupStorage `json:"finalBackup,omitempty"`
+}
+
+// ScalingStrategy contains the configuration of the slot and key migration when primaries are added or removed
+type ScalingStrategy struct {
+	// KeyBatchSize number of keys migrated from a slot on each iteration
+	KeyBatchSize *int32 `json:"keyBatchSize,omitempty"`
+	// SlotBatchSize number of slots migrated on each iteration
+	SlotBatchSize *int32 `json:"slotBatchSize,omitempty"`
+	// IdleTimeoutMillis maximum idle time at any point during the key migration
+	IdleTimeoutMillis *int32 `json:"idleTimeoutMillis,omitempty"`
+	// BalanceBy load balanced across the primaries when the slots are dispatched
+	// +kubebuilder:validation:Enum=slots;keys;memory
+	BalanceBy BalanceBy `json:"balanceBy,omitempty"`
+}
+
+// BalanceBy load balanced across the primaries: slots, keys or memory
+type BalanceBy string
+
+const (
+	// BalanceBySlots each primary holds the same number of slots
+	BalanceBySlots BalanceBy = "slots"
+	// BalanceByKeys each slot is weighted by its number of keys
+	BalanceByKeys BalanceBy = "keys"
+	// BalanceByMemory each slot is weighted by the sampled memory usage of its keys
+	BalanceByMemory BalanceBy = "memory"
+)
+
+// RollingUpdateStrategy contains the configuration of the replacement of the redis nodes
+type RollingUpdateStrategy struct {
+	// Method replacement of the primaries: migration moves their slots to new primaries, failover promotes new replicas
+	Method RollingUpdateMethod `json:"method,omitempty"`
+	// KeyMigration whether or not the keys of the replaced primaries are migrated
+	KeyMigration *bool `json:"keyMigration,omitempty"`
+	// KeyBatchSize number of keys migrated from a slot on each iteration
+	KeyBatchSize *int32 `json:"keyBatchSize,omitempty"`
+	// SlotBatchSize number of slots migrated on each iteration
+	SlotBatchSize *int32 `json:"slotBatchSize,omitempty"`
+	// IdleTimeoutMillis maximum idle time at any point during the key migration
+	IdleTimeoutMillis *int32 `json:"idleTimeoutMillis,omitempty"`
+	// WarmingDelayMillis amount of time in between each slot batch iteration
+	WarmingDelayMillis int32 `json:"warmingDelayMillis,omitempty"`
+	// MaxSurge maximum number of pods created above the pods of the cluster, maxUnavailableShards x (1 + replicationFactor) by default.
+	// Below the pods of a shard, the old replicas are deleted to make room for the new pods.
+	MaxSurge *int32 `json:"maxSurge,omitempty"`
+	// MaxUnavailableShards maximum number of shards replaced at the same time, 1 by default
+	MaxUnavailableShards *int32 `json:"maxUnavailableShards,omitempty"`
+	// Strategy controls the progression of the rolling update shard by shard
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+}
+
+// RollingUpdateMethod replacement of the primaries during a rolling update
+type RollingUpdateMethod string
+
+const (
+	// RollingUpdateMethodMigration the slots of the replaced primaries are migrated to new primaries
+	RollingUpdateMethodMigration RollingUpdateMethod = "migration"
+	// RollingUpdateMethodFailover the replicas are replaced first, then a new replica takes over the slots of its primary with a failover
+	RollingUpdateMethodFailover RollingUpdateMethod = "failover"
+)
+
+// RolloutStrategy controls how many shards a rolling update replaces, and when it pauses
+type RolloutStrategy struct {
+	// Partition number of shards left on the previous pod template, the rolling update stops once the other shards are replaced
+	Partition int32 `json:"partition,omitempty"`
+	// PauseAfterShards number of shards replaced between two pauses of the rolling update, the rolling update does not pause when it is 0
+	PauseAfterShards int32 `json:"pauseAfterShards,omitempty"`
+	// AutoPromote resumes a paused rolling update once the health gates pass, a failed health gate aborts the rolling update
+	AutoPromote bool `json:"autoPromote,omitempty"`
+	// PauseSeconds minimum duration of a pause before its automatic promotion, 60 by default
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+	// Abort stops the replacement of the shards, the shards already replaced keep the new pod template
+	Abort bool `json:"abort,omitempty"`
+	// HealthGates checks of the replaced shards before the automatic promotion of a paused rolling update
+	HealthGates *RolloutHealthGates `json:"healthGates,omitempty"`
+}
+
+// RolloutHealthGates contains the thresholds checked on the replaced shards, the cluster state and the replication links of the replicas are always checked
+type RolloutHealthGates struct {
+	// MaxErrorRatePercent maximum percentage of the commands processed by the replaced primaries answered with an error, 1 by default
+	MaxErrorRatePercent *int32 `json:"maxErrorRatePercent,omitempty"`
+}
+
+// RedisAuth contains the reference to the redis credentials
+type RedisAuth struct {
+	// SecretName name of the secret in the RedisCluster namespace containing the password and optionally the username
+	SecretName string `json:"secretName"`
+}
+
+// RedisTLS contains the reference to the redis certificates
+type RedisTLS struct {
+	// SecretName name of the secret in the RedisCluster namespace containing the certificate, the private key and the CA certificate
+	SecretName string `json:"secretName"`
+}
+
+// RedisStorage contains the persistent storage configuration
+type RedisStorage struct {
+	// VolumeClaimTemplate template of the persistent volume claim created for each redis node
+	VolumeClaimTemplate kapiv1.PersistentVolumeClaimTemplate `json:"volumeClaimTemplate"`
+}
+
+// RedisClusterRestore contains the location of the backup restored in a new cluster, either a RedisClusterBackup or a folder of a backup storage
+type RedisClusterRestore struct {
+	// BackupName name of a completed RedisClusterBackup in the RedisCluster namespace
+	BackupName string `json:"backupName,omitempty"`
+	// Storage backup storage containing the backup, used when BackupName is empty
+	Storage *BackupStorage `json:"storage,omitempty"`
+	// Path folder of the backup in the storage, containing the manifest.json file
+	Path string `json:"path,omitempty"`
+}
+
+// BackupStorage contains the configuration of the backup storage, exactly one backend must be set
+type BackupStorage struct {
+	// Local stores the backup files in a folder of the operator filesystem, usually a mounted persistent volume
+	Local *LocalBackupStorage `json:"local,omitempty"`
+	// S3 stores the backup files in an S3 compatible object storage
+	S3 *S3BackupStorage `json:"s3,omitempty"`
+}
+
+// LocalBackupStorage contains the configuration of the local backup storage
+type LocalBackupStorage struct {
+	// Path folder of the operator filesystem where the backup files are written
+	Path string `json:"path"`
+}
+
+// S3BackupStorage contains the configuration of the S3 compatible backup storage
+type S3BackupStorage struct {
+	// Endpoint URL of the S3 endpoint, for instance https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Endpoint string `json:"endpoint"`
+	// Bucket name of the bucket where the backup files are written
+	Bucket string `json:"bucket"`
+	// Prefix prepended to the name of the backup files
+	Prefix string `json:"prefix,omitempty"`
+	// Region of the bucket, us-east-1 if empty
+	Region string `json:"region,omitempty"`
+	// CredentialsSecret name of the secret in the RedisClusterBackup namespace containing the access key ID and the secret access key
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// RedisClusterStatus contains the observed state of the RedisCluster
+type RedisClusterStatus struct {
+	// Conditions represent the latest available observations of the RedisCluster state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Phase summary of the current operation of the operator on the cluster
+	Phase ClusterPhase `json:"phase,omitempty"`
+	// StartTime time when the RedisCluster was first reconciled
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Selector label selector of the redis node pods, used by the scale subresource
+	Selector string `json:"selector,omitempty"`
+	// Primaries number of primary nodes owning slots
+	Primaries int32 `json:"primaries,omitempty"`
+	// ReadyPrimaries number of primary nodes owning slots whose pod is ready
+	ReadyPrimaries int32 `json:"readyPrimaries,omitempty"`
+	// ReplicasPerPrimary number of replica nodes of each primary node, by primary node ID
+	ReplicasPerPrimary map[string]int32 `json:"replicasPerPrimary,omitempty"`
+	// MinReplicationFactor smallest number of replica nodes of a primary node
+	MinReplicationFactor int32 `json:"minReplicationFactor,omitempty"`
+	// MaxReplicationFactor largest number of replica nodes of a primary node
+	MaxReplicationFactor int32 `json:"maxReplicationFactor,omitempty"`
+	// NodesPlacement placement of the primary nodes on the kubernetes nodes
+	NodesPlacement NodesPlacement `json:"nodesPlacement,omitempty"`
+	// Pods number of redis node pods
+	Pods int32 `json:"pods,omitempty"`
+	// ReadyPods number of ready redis node pods
+	ReadyPods int32 `json:"readyPods,omitempty"`
+	// RunningRedisNodes number of pods running a redis node that joined the cluster
+	RunningRedisNodes int32 `json:"runningRedisNodes,omitempty"`
+	// Nodes redis nodes of the cluster
+	Nodes []RedisClusterNode `json:"nodes,omitempty"`
+	// Restore progress of the restore of the backup set in RestoreFrom
+	Restore *RestoreStatus `json:"restore,omitempty"`
+	// Progress progress of the slot migrations of the current or last cluster operation
+	Progress *ProgressStatus `json:"progress,omitempty"`
+	// ObservedGeneration generation of the spec fully applied to the cluster
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Config state of the redis server configuration of the cluster
+	Config *ConfigStatus `json:"config,omitempty"`
+	// Rollout state of the rolling update of the shards to the pod template
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+}
+
+// ConfigStatus contains the state of the redis server configuration stored in the config map of the cluster
+type ConfigStatus struct {
+	// RestartHash hash of the settings that can only be applied by restarting the redis nodes, the pods created
+	// with another hash are replaced by a rolling update
+	RestartHash string `json:"restartHash,omitempty"`
+	// BaselineRestartHash hash of the settings that can only be applied by restarting the redis nodes when the
+	// operator first handled the cluster, the pods are only rolled once RestartHash differs from it
+	BaselineRestartHash string `json:"baselineRestartHash,omitempty"`
+	// PendingKeys settings changed in the config map that are applied by the rolling update of the pods
+	PendingKeys []string `json:"pendingKeys,omitempty"`
+	// FailedKeys settings changed in the config map that the redis nodes rejected at runtime
+	FailedKeys []string `json:"failedKeys,omitempty"`
+	// DriftedNodes pods of the redis nodes whose runtime settings drifted from the config map while the other nodes
+	// matched it at the last check, their settings are set again with the values of the config map
+	DriftedNodes []string `json:"driftedNodes,omitempty"`
+}
+
+// RolloutStatus contains the state of the rolling update of the shards to the pod template
+type RolloutStatus struct {
+	// PodTemplateHash hash of the pod template the shards are updated to
+	PodTemplateHash string `json:"podTemplateHash"`
+	// Phase of the rolling update
+	Phase RolloutPhase `json:"phase"`
+	// TotalShards number of shards of the cluster
+	TotalShards int32 `json:"totalShards"`
+	// UpdatedShards number of shards whose primary runs the pod template
+	UpdatedShards int32 `json:"updatedShards"`
+	// PromotedShards number of updated shards when the rolling update started or was last promoted
+	PromotedShards int32 `json:"promotedShards,omitempty"`
+	// PauseTime time when the rolling update paused
+	PauseTime *metav1.Time `json:"pauseTime,omitempty"`
+	// Message human-readable message indicating details about the phase
+	Message string `json:"message,omitempty"`
+	// HealthCounters command counters of the primaries running the pod template at the last check of the health
+	// gates, by pod name: the error rate of the next check is measured since then
+	HealthCounters map[string]RolloutCounters `json:"healthCounters,omitempty"`
+}
+
+// RolloutCounters contains the command counters of a redis node
+type RolloutCounters struct {
+	// CommandsProcessed number of commands processed by the redis node since it started
+	CommandsProcessed int64 `json:"commandsProcessed"`
+	// ErrorReplies number of commands answered with an error by the redis node since it started
+	ErrorReplies int64 `json:"errorReplies"`
+}
+
+// RolloutPhase is the phase of the rolling update of the shards
+type RolloutPhase string
+
+const (
+	// RolloutPhaseProgressing the shards are being replaced
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+	// RolloutPhasePaused the rolling update waits for its promotion
+	RolloutPhasePaused RolloutPhase = "Paused"
+	// RolloutPhasePartitioned the shards outside of the partition are replaced
+	RolloutPhasePartitioned RolloutPhase = "Partitioned"
+	// RolloutPhaseAborted the rolling update was aborted by the strategy or by a failed health gate
+	RolloutPhaseAborted RolloutPhase = "Aborted"
+	// RolloutPhaseCompleted all the redis nodes run the pod template
+	RolloutPhaseCompleted RolloutPhase = "Completed"
+)
+
+// ProgressStatus contains the progress of the slot migrations of a cluster operation, such as a scaling or a rolling update
+type ProgressStatus struct {
+	// SlotsPlanned number of slots to migrate
+	SlotsPlanned int32 `json:"slotsPlanned"`
+	// SlotsMigrated number of slots migrated
+	SlotsMigrated int32 `json:"slotsMigrated"`
+	// SlotsFailed number of slots whose keys could not all be migrated
+	SlotsFailed int32 `json:"slotsFailed"`
+	// KeysMoved number of keys migrated
+	KeysMoved int64 `json:"keysMoved"`
+	// StartTime time when the first slot migration of the operation started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EstimatedCompletionTime estimated time of the end of the slot migrations, from the rate of the migrated slots
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+	// CompletionTime time when the cluster operation completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RedisClusterNode represents a redis node of the cluster
+type RedisClusterNode struct {
+	// ID redis node ID
+	ID string `json:"id"`
+	// Role of the redis node in the cluster
+	Role RedisClusterNodeRole `json:"role"`
+	// Zone topology domain of the kubernetes node running the pod, the values of its topology keys joined with a slash
+	Zone string `json:"zone"`
+	// IP of the pod
+	IP string `json:"ip"`
+	// Port of the redis node
+	Port string `json:"port"`
+	// PodName name of the pod running the redis node
+	PodName string `json:"podName"`
+	// PrimaryRef ID of the primary node of a replica node
+	PrimaryRef string `json:"primaryRef,omitempty"`
+	// Slots ranges of slots owned by a primary node
+	Slots []SlotRange `json:"slots,omitempty"`
+	// Stats runtime statistics of the redis node
+	Stats *RedisNodeStats `json:"stats,omitempty"`
+}
+
+// RedisNodeStats contains runtime statistics of a redis node, collected with the INFO command
+type RedisNodeStats struct {
+	// UsedMemory number of bytes allocated by the redis node
+	UsedMemory int64 `json:"usedMemory"`
+	// MaxMemory maxmemory configuration of the redis node, 0 when unlimited
+	MaxMemory int64 `json:"maxMemory"`
+	// Keys number of keys stored on the redis node
+	Keys int64 `json:"keys"`
+	// ReplicationOffset replication offset of the redis node
+	ReplicationOffset int64 `json:"replicationOffset"`
+	// ReplicationLag number of bytes a replica node is behind its primary node
+	ReplicationLag int64 `json:"replicationLag,omitempty"`
+	// ConnectedClients number of client connections
+	ConnectedClients int64 `json:"connectedClients"`
+	// OpsPerSec number of commands processed per second
+	OpsPerSec int64 `json:"opsPerSec"`
+	// LastUpdateTime time when the statistics were collected
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// SlotRange represents a range of slots, bounds included
+type SlotRange struct {
+	// Start first slot of the range
+	Start int32 `json:"start"`
+	// End last slot of the range
+	End int32 `json:"end"`
+}
+
+// RestoreStatus contains the progress of the restore of a backup
+type RestoreStatus struct {
+	// Phase of the restore
+	Phase RestorePhase `json:"phase,omitempty"`
+	// StartTime time when the restore started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime time when the restore completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message details about the last error
+	Message string `json:"message,omitempty"`
+	// Shards number of RDB files in the backup
+	Shards int32 `json:"shards,omitempty"`
+	// ShardsRestored number of RDB files loaded in the cluster
+	ShardsRestored int32 `json:"shardsRestored,omitempty"`
+	// KeysRestored number of keys loaded in the cluster
+	KeysRestored int64 `json:"keysRestored,omitempty"`
+	// ShardKeysRead number of keys of the RDB file being loaded that are already read, the keys of a RDB file
+	// are loaded in batches across reconciles
+	ShardKeysRead int64 `json:"shardKeysRead,omitempty"`
+	// Retries number of consecutive failed attempts, the restore fails once it reaches the maximum
+	Retries int32 `json:"retries,omitempty"`
+}
+
+// RestorePhase phase of the restore of a backup
+type RestorePhase string
+
+const (
+	// RestorePhaseRunning the keys of the backup are being loaded
+	RestorePhaseRunning RestorePhase = "Running"
+	// RestorePhaseCompleted all the keys of the backup are loaded
+	RestorePhaseCompleted RestorePhase = "Completed"
+	// RestorePhaseFailed the backup cannot be restored
+	RestorePhaseFailed RestorePhase = "Failed"
+)
+
+// NodesPlacement Redis Nodes placement mode information
+type NodesPlacement string
+
+const (
+	// NodesPlacementBestEffort the cluster nodes placement is in best effort,
+	// it means you can have 2 (or more) primaries on the same kubernetes node
+	NodesPlacementBestEffort NodesPlacement = "BestEffort"
+	// NodesPlacementOptimal the cluster nodes placement is optimal,
+	// it means one primary per kubernetes node
+	NodesPlacementOptimal NodesPlacement = "Optimal"
+)
+
+// RedisClusterNodeRole RedisCluster Node Role type
+type RedisClusterNodeRole string
+
+const (
+	// RedisClusterNodeRolePrimary RedisCluster Primary node role
+	RedisClusterNodeRolePrimary RedisClusterNodeRole = "Primary"
+	// RedisClusterNodeRoleReplica RedisCluster Replica node role
+	RedisClusterNodeRoleReplica RedisClusterNodeRole = "Replica"
+	// RedisClusterNodeRoleHandshake RedisCluster Handshake node role
+	RedisClusterNodeRoleHandshake RedisClusterNodeRole = "Handshake"
+	// RedisClusterNodeRoleNone None node role
+	RedisClusterNodeRoleNone RedisClusterNodeRole = "None"
+)
+
+// ClusterPhase phase of the RedisCluster
+type ClusterPhase string
+
+const (
+	// ClusterPhaseOK the cluster is correctly configured
+	ClusterPhaseOK ClusterPhase = "OK"
+	// ClusterPhaseKO the cluster is not correctly configured
+	ClusterPhaseKO ClusterPhase = "KO"
+	// ClusterPhaseScaling primaries or replicas are being added or removed
+	ClusterPhaseScaling ClusterPhase = "Scaling"
+	// ClusterPhaseRebalancing the slots are being rebalanced
+	ClusterPhaseRebalancing ClusterPhase = "Rebalancing"
+	// ClusterPhaseRollingUpdate the redis nodes are being replaced
+	ClusterPhaseRollingUpdate ClusterPhase = "RollingUpdate"
+	// ClusterPhaseRestoring the keys of a backup are being loaded
+	ClusterPhaseRestoring ClusterPhase = "Restoring"
+)
+
+const (
+	// ConditionClusterOK the RedisCluster is in a good shape
+	ConditionClusterOK = "ClusterOK"
+	// ConditionScaling the RedisCluster is currently in a scaling stage
+	ConditionScaling = "Scaling"
+	// ConditionRebalancing the RedisCluster is currently rebalancing slots and keys
+	ConditionRebalancing = "Rebalancing"
+	// ConditionRollingUpdate the RedisCluster is currently performing a rolling update of its nodes
+	ConditionRollingUpdate = "RollingUpdate"
+	// ConditionRestoring the RedisCluster is currently loading the keys of a backup
+	ConditionRestoring = "Restoring"
+	// ConditionPaused the reconciliation of the RedisCluster is suspended
+	ConditionPaused = "Paused"
+	// ConditionDeletionBlocked the RedisCluster is deleted but its teardown waits for deletionProtection to be cleared
+	ConditionDeletionBlocked = "DeletionBlocked"
+)
@@ -67,6 +67,12 @@ Builds the argument list for the redis operator
 {{- define "operator-for-redis.arglist" -}}
 {{- $logLevel := (print "--v=" .Values.logLevel) -}}
 {{- $args := concat (prepend .Values.args $logLevel) .Values.extraArgs -}}
+{{- if .Values.webhook.enabled }}
+{{- $args = concat $args (list "--webhook-enabled=true" (print "--webhook-port=" .Values.webhook.port)) }}
+{{- end }}
+{{- if .Values.tracing.enabled }}
+{{- $args = concat $args (list "--tracing-exporter=otlp" (print "--tracing-endpoint=" .Values.tracing.endpoint) (print "--tracing-insecure=" .Values.tracing.insecure) (print "--tracing-sampling-ratio=" .Values.tracing.samplingRatio)) }}
+{{- end }}
 {{- $argsList := list }}
 {{- range $args }}{{- $argAsStr := . | quote }}{{- $argsList = append $argsList $argAsStr}}{{- end}}
 {{- join "," (compact $argsList) }}
@@ -64,6 +64,9 @@ func (cs *ClusterStatus) populatePodInfoStats(restConfig *rest.Config, podInfoMa
 
 	wg := sync.WaitGroup{}
 	for _, pod := range podInfoMap {
+		if pod.hasStats() {
+			continue
+		}
 		wg.Add(1)
 		pod := pod
 		go func() {
@@ -81,7 +84,7 @@ func (cs *ClusterStatus) addToPodInfo(restConfig *rest.Config, clientset *kubern
 		glog.Infof("couldn't find match in podNameToInfo for pod %s", pod.Name)
 		return
 	}
-	stdout, err := execCommandOnPod(restConfig, clientset, pod, parameterCodec, noCommandOverride)
+	stdout, err := execCommandOnPod(restConfig, clientset, pod, parameterCodec, redisCliCommand(cs.cluster, "info"))
 	if err != nil {
 		glog.Infof("failed to exec command on pod %s", pod.Name)
 	} else {
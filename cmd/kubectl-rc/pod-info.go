@@ -10,8 +10,7 @@ import (
 )
 
 var (
-	dbKeysRegex       = regexp.MustCompile("^([a-zA-Z0-9]+):keys=([0-9]+)")
-	noCommandOverride []string
+	dbKeysRegex = regexp.MustCompile("^([a-zA-Z0-9]+):keys=([0-9]+)")
 )
 
 type PodInfo struct {
@@ -29,7 +28,7 @@ type PodInfo struct {
 }
 
 func NewPodInfo(pod *kapiv1.Pod, node rapi.RedisClusterNode) *PodInfo {
-	return &PodInfo{
+	podInfo := &PodInfo{
 		name:  pod.Name,
 		ip:    pod.Status.PodIP,
 		node:  pod.Status.HostIP,
@@ -39,6 +38,37 @@ func NewPodInfo(pod *kapiv1.Pod, node rapi.RedisClusterNode) *PodInfo {
 		role:  string(node.Role),
 		pod:   pod,
 	}
+	// the statistics collected by the operator save an exec in the pod
+	if node.Stats != nil {
+		podInfo.usedMemory = humanBytes(node.Stats.UsedMemory)
+		podInfo.maxMemory = humanBytes(node.Stats.MaxMemory)
+		// the operator collects the number of keys of all the databases
+		podInfo.keys = fmt.Sprintf("total=%d", node.Stats.Keys)
+	}
+	return podInfo
+}
+
+// hasStats returns true if the memory and key statistics are known
+func (pi *PodInfo) hasStats() bool {
+	return pi.usedMemory != "" && pi.maxMemory != "" && pi.keys != ""
+}
+
+// humanBytes formats a number of bytes like the *_human fields of the INFO command
+func humanBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	value := float64(bytes)
+	suffix := ""
+	for _, s := range []string{"K", "M", "G", "T", "P"} {
+		if value < unit {
+			break
+		}
+		value /= unit
+		suffix = s
+	}
+	return fmt.Sprintf("%.2f%s", value, suffix)
 }
 
 func NewPodInfoFromClusterStatus(pod *kapiv1.Pod) *PodInfo {
@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"path"
 	"strings"
 
 	"github.com/golang/glog"
@@ -11,6 +13,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
 )
 
 var option = &kapiv1.PodExecOptions{
@@ -28,11 +33,11 @@ func execCommandOnPod(restConfig *rest.Config, clientset *kubernetes.Clientset,
 		Name(pod.Name).
 		Namespace(pod.Namespace).
 		SubResource("exec")
-	tmpOption := option
+	tmpOption := *option
 	if len(commandOverride) > 0 {
 		tmpOption.Command = commandOverride
 	}
-	req.VersionedParams(tmpOption, parameterCodec)
+	req.VersionedParams(&tmpOption, parameterCodec)
 	exec, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
 	if err != nil {
 		glog.Fatalf("exec to pod %s failed while retrieving `info`", pod.Name)
@@ -45,6 +50,26 @@ func execCommandOnPod(restConfig *rest.Config, clientset *kubernetes.Clientset,
 	return stdout, err
 }
 
+// redisCliCommand builds the redis-cli command run in the redis-node container, with the cluster tls and auth settings
+func redisCliCommand(cluster *rapi.RedisCluster, args ...string) []string {
+	cmd := []string{"redis-cli"}
+	if cluster.Spec.TLS != nil && cluster.Spec.TLS.SecretName != "" {
+		cmd = append(cmd,
+			"--tls",
+			"--cert", path.Join(pod.TLSMountPath, rapi.TLSCertKey),
+			"--key", path.Join(pod.TLSMountPath, rapi.TLSPrivateKeyKey),
+			"--cacert", path.Join(pod.TLSMountPath, rapi.TLSCAKey),
+		)
+	}
+	if cluster.Spec.Auth == nil || cluster.Spec.Auth.SecretName == "" {
+		return append(cmd, args...)
+	}
+	// the credentials are only available in the environment of the redis-node container
+	script := fmt.Sprintf(`REDISCLI_AUTH="$%s" exec %s ${%s:+--user "$%s"} %s`,
+		pod.RedisPasswordEnv, strings.Join(cmd, " "), pod.RedisUsernameEnv, pod.RedisUsernameEnv, strings.Join(args, " "))
+	return []string{"sh", "-c", script}
+}
+
 func parseCommandOutput(stdout bytes.Buffer, pod *kapiv1.Pod) []string {
 	var lines []string
 	for {
@@ -6,9 +6,9 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
-	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
@@ -16,9 +16,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -29,77 +28,180 @@ import (
 
 const (
 	metricsPath = "/metrics"
+	// resyncPeriod time between two recordings of the metrics of an unchanged cluster
+	resyncPeriod = time.Minute
 )
 
 var (
 	namespace   = os.Getenv("NAMESPACE")
 	clusterName = os.Getenv("CLUSTER_NAME")
 	serverPort  = os.Getenv("SERVER_PORT")
+	// allClusters watches every RedisCluster instead of the CLUSTER_NAME cluster
+	allClusters = os.Getenv("ALL_CLUSTERS") == "true"
+	// clusterSelector label selector of the RedisClusters watched
+	clusterSelector = os.Getenv("CLUSTER_SELECTOR")
 
 	nodePerZone = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "node_per_zone",
 		Help: "Number of Redis nodes per zone",
-	}, []string{"name"})
+	}, []string{"namespace", "cluster", "name"})
 
 	availableZones = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "available_zones",
 		Help: "Zones available to Redis cluster",
-	}, []string{"name"})
+	}, []string{"namespace", "cluster", "name"})
 
 	zoneSkew = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "zone_skew",
 		Help: "Zone skew of Redis nodes",
-	}, []string{"name"})
+	}, []string{"namespace", "cluster", "name"})
+
+	fetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fetch_errors_total",
+		Help: "Number of errors fetching the resources of Redis clusters",
+	}, []string{"namespace", "cluster", "resource"})
+
+	zoneSkewRoles = []string{"primaries", "replicas"}
 )
 
-func recordMetrics(cluster *rapi.RedisCluster, k8sNodes []v1.Node) {
-	zones := utils.GetZones(k8sNodes)
+// clusterSeries zones recorded for a cluster, used to delete the series of the zones that are gone
+type clusterSeries struct {
+	nodeZones      map[string]bool
+	availableZones map[string]bool
+}
+
+// recorder records the metrics of the watched clusters
+type recorder struct {
+	client kclient.Client
+	mu     sync.Mutex
+	series map[types.NamespacedName]*clusterSeries
+}
+
+func newRecorder(client kclient.Client) *recorder {
+	return &recorder{
+		client: client,
+		series: make(map[types.NamespacedName]*clusterSeries),
+	}
+}
+
+func (r *recorder) recordMetrics(cluster *rapi.RedisCluster) {
+	k8sNodes, err := utils.GetKubeNodes(context.Background(), r.client, cluster.Spec.PodTemplate.Spec.NodeSelector)
+	if err != nil {
+		// the metrics are recorded again on the next resync
+		log.Printf("unable to get kube nodes of redis cluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+		fetchErrors.WithLabelValues(cluster.Namespace, cluster.Name, "nodes").Inc()
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	previous := r.series[key]
+	current := &clusterSeries{nodeZones: map[string]bool{}, availableZones: map[string]bool{}}
+
+	zones := utils.GetZones(k8sNodes, utils.GetTopologyKeys(cluster))
 	zoneToNodeCount := map[string]int{}
 	for _, zone := range zones {
 		zoneToNodeCount[zone] = 0
-		availableZones.With(prometheus.Labels{"name": zone}).Set(float64(0))
+		availableZones.WithLabelValues(cluster.Namespace, cluster.Name, zone).Set(float64(0))
+		current.availableZones[zone] = true
 	}
 	for _, node := range cluster.Status.Cluster.Nodes {
 		zoneToNodeCount[node.Zone] += 1
 	}
 	for zone, count := range zoneToNodeCount {
-		nodePerZone.With(prometheus.Labels{"name": zone}).Set(float64(count))
+		nodePerZone.WithLabelValues(cluster.Namespace, cluster.Name, zone).Set(float64(count))
+		current.nodeZones[zone] = true
 	}
 	zoneToPrimaries, zoneToReplicas := utils.ZoneToRole(cluster.Status.Cluster.Nodes)
 	primarySkew, replicaSkew, _ := utils.GetZoneSkewByRole(zoneToPrimaries, zoneToReplicas)
-	zoneSkew.With(prometheus.Labels{"name": "primaries"}).Set(float64(primarySkew))
-	zoneSkew.With(prometheus.Labels{"name": "replicas"}).Set(float64(replicaSkew))
+	zoneSkew.WithLabelValues(cluster.Namespace, cluster.Name, "primaries").Set(float64(primarySkew))
+	zoneSkew.WithLabelValues(cluster.Namespace, cluster.Name, "replicas").Set(float64(replicaSkew))
+
+	if previous != nil {
+		for zone := range previous.nodeZones {
+			if !current.nodeZones[zone] {
+				nodePerZone.DeleteLabelValues(cluster.Namespace, cluster.Name, zone)
+			}
+		}
+		for zone := range previous.availableZones {
+			if !current.availableZones[zone] {
+				availableZones.DeleteLabelValues(cluster.Namespace, cluster.Name, zone)
+			}
+		}
+	}
+	r.series[key] = current
 }
 
-func watchRedisCluster(client kclient.Client, rClient *rest.RESTClient, cluster *rapi.RedisCluster) chan struct{} {
+// deleteMetrics deletes the series of a deleted cluster
+func (r *recorder) deleteMetrics(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if series, ok := r.series[key]; ok {
+		for zone := range series.nodeZones {
+			nodePerZone.DeleteLabelValues(key.Namespace, key.Name, zone)
+		}
+		for zone := range series.availableZones {
+			availableZones.DeleteLabelValues(key.Namespace, key.Name, zone)
+		}
+		delete(r.series, key)
+	}
+	for _, role := range zoneSkewRoles {
+		zoneSkew.DeleteLabelValues(key.Namespace, key.Name, role)
+	}
+	fetchErrors.DeleteLabelValues(key.Namespace, key.Name, "nodes")
+}
+
+// watchRedisClusters watches the CLUSTER_NAME cluster, or every cluster matching CLUSTER_SELECTOR in all clusters mode,
+// with a shared informer
+func watchRedisClusters(r *recorder, rClient *rest.RESTClient) chan struct{} {
+	watchNamespace, watchName := namespace, clusterName
+	if allClusters {
+		watchNamespace, watchName = metav1.NamespaceAll, ""
+	}
 	options := func(options *metav1.ListOptions) {
-		options.LabelSelector = labels.FormatLabels(cluster.Labels)
+		options.LabelSelector = clusterSelector
+		if watchName != "" {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", watchName).String()
+		}
 	}
 	watchlist := cache.NewFilteredListWatchFromClient(
 		rClient,
 		rapi.ResourcePlural,
-		namespace,
+		watchNamespace,
 		options,
 	)
 
-	_, controller := cache.NewInformer(
-		watchlist,
-		&rapi.RedisCluster{},
-		0*time.Second,
-		cache.ResourceEventHandlerFuncs{
-			UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-				if newCluster, ok := newObj.(*rapi.RedisCluster); ok {
-					k8sNodes, err := utils.GetKubeNodes(context.Background(), client, cluster.Spec.PodTemplate.Spec.NodeSelector)
-					if err != nil {
-						log.Fatalf("unable to get kube nodes: %v", err)
-					}
-					recordMetrics(newCluster, k8sNodes)
-				}
-			},
+	informer := cache.NewSharedIndexInformer(watchlist, &rapi.RedisCluster{}, resyncPeriod, cache.Indexers{})
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if cluster, ok := obj.(*rapi.RedisCluster); ok {
+				r.recordMetrics(cluster)
+			}
 		},
-	)
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			if newCluster, ok := newObj.(*rapi.RedisCluster); ok {
+				r.recordMetrics(newCluster)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cluster, ok := obj.(*rapi.RedisCluster); ok {
+				r.deleteMetrics(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+			}
+		},
+	})
+	if err := informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		// the informer retries the list and watch of the clusters
+		log.Printf("unable to watch redis clusters: %v", err)
+		fetchErrors.WithLabelValues(watchNamespace, watchName, rapi.ResourcePlural).Inc()
+	}); err != nil {
+		log.Printf("unable to set the watch error handler: %v", err)
+	}
 	stop := make(chan struct{})
-	go controller.Run(stop)
+	go informer.Run(stop)
 	return stop
 }
 
@@ -115,16 +217,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("unable to create k8s client: %v", err)
 	}
-	cluster := &rapi.RedisCluster{}
-	namespacedName := types.NamespacedName{Namespace: namespace, Name: clusterName}
-	if err = client.Get(context.Background(), namespacedName, cluster); err != nil && !apierrors.IsNotFound(err) {
-		log.Fatalf("unable to get redis cluster %s/%s: %v", namespace, clusterName, err)
-	}
 	rClient, err := rapi.NewClient(restConfig)
 	if err != nil {
 		log.Fatalf("unable to create k8s client: %v", err)
 	}
-	watchCh := watchRedisCluster(client, rClient, cluster)
+	if !allClusters && clusterName == "" {
+		log.Fatalf("CLUSTER_NAME is required unless ALL_CLUSTERS is true")
+	}
+	watchCh := watchRedisClusters(newRecorder(client), rClient)
 	defer close(watchCh)
 	http.Handle(metricsPath, promhttp.Handler())
 	log.Printf("Serving requests on port %s\n", serverPort)
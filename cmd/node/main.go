@@ -18,6 +18,10 @@ func main() {
 	utils.BuildInfos()
 	config := redisnode.NewRedisNodeConfig()
 	config.AddFlags(pflag.CommandLine)
+	if err := config.ParseEnvironment(); err != nil {
+		glog.Errorf("unable to parse environment variables: %v", err)
+		os.Exit(1)
+	}
 
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
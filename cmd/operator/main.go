@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"time"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/api/v1beta1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/backup"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller"
+	"github.com/IBM/operator-for-redis-cluster/pkg/crd"
 	"github.com/IBM/operator-for-redis-cluster/pkg/garbagecollector"
 	"github.com/IBM/operator-for-redis-cluster/pkg/operator"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -19,12 +26,16 @@ import (
 
 const (
 	leaderElectionID = "redis-operator-leader-election-lock"
+	// tracingShutdownTimeout maximum duration to export the remaining spans when the operator stops
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 func main() {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	utilruntime.Must(rapi.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
 
 	opts := zap.Options{
 		Development: true,
@@ -53,6 +64,8 @@ func main() {
 		HealthProbeBindAddress: config.HealthCheckAddr,
 		LeaderElection:         config.LeaderElectionEnabled,
 		LeaderElectionID:       leaderElectionID,
+		Port:                   config.WebhookPort,
+		CertDir:                config.WebhookCertDir,
 	})
 	if err != nil {
 		glog.Fatalf("unable to start manager: %v", err)
@@ -64,6 +77,24 @@ func main() {
 		glog.Fatalf("unable to set up rediscluster controller: %v", err)
 	}
 
+	if config.WebhookEnabled {
+		if err = (&rapi.RedisCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			glog.Fatalf("unable to set up rediscluster webhooks: %v", err)
+		}
+		if err = mgr.Add(crd.NewStorageVersionMigrator(mgr.GetClient(), mgr.GetAPIReader())); err != nil {
+			glog.Fatalf("unable to set up the storage version migration: %v", err)
+		}
+	}
+
+	podExec, err := backup.NewPodExec(restConfig)
+	if err != nil {
+		glog.Fatalf("unable to create the pod exec client: %v", err)
+	}
+	backupCtrl := controller.NewBackupController(ctrlCfg, mgr, mgr.GetClient(), mgr.GetEventRecorderFor("redisclusterbackup-controller"), podExec)
+	if err = controller.SetupRedisClusterBackupController(mgr, backupCtrl); err != nil {
+		glog.Fatalf("unable to set up redisclusterbackup controller: %v", err)
+	}
+
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		glog.Fatalf("unable to set up health check: %v", err)
 	}
@@ -73,11 +104,23 @@ func main() {
 
 	ctx := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Setup(ctx, &config.Tracing)
+	if err != nil {
+		glog.Fatalf("unable to set up tracing: %v", err)
+	}
+
 	gc := garbagecollector.NewGarbageCollector(mgr.GetClient())
 	go gc.Run(ctx.Done())
 
 	glog.Info("starting manager")
-	if err = mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+	// flush the spans of the last reconciliations
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if errShutdown := shutdownTracing(shutdownCtx); errShutdown != nil {
+		glog.Errorf("unable to shut down tracing: %v", errShutdown)
+	}
+	if err != nil {
 		glog.Fatalf("problem running manager: %v", err)
 	}
 }
@@ -18,6 +18,7 @@ module.exports = {
     'scaling',
     'rolling-update',
     'key-migration',
+    'backup',
     'CONTRIBUTING',
   ],
 };
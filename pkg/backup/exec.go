@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// PodFileCopier interface to copy files out of a pod container
+type PodFileCopier interface {
+	// FileSize returns the size in bytes of the file of the pod container
+	FileSize(ctx context.Context, pod *kapiv1.Pod, container, file string) (int64, error)
+	// CopyFile writes the content of the file of the pod container in w
+	CopyFile(ctx context.Context, pod *kapiv1.Pod, container, file string, w io.Writer) error
+}
+
+// PodExec copies files out of pod containers by running commands through the pods exec subresource
+type PodExec struct {
+	restConfig *rest.Config
+	clientset  kubernetes.Interface
+}
+
+// NewPodExec returns a new PodExec
+func NewPodExec(restConfig *rest.Config) (*PodExec, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &PodExec{restConfig: restConfig, clientset: clientset}, nil
+}
+
+// FileSize returns the size in bytes of the file of the pod container, with stat
+func (e *PodExec) FileSize(ctx context.Context, pod *kapiv1.Pod, container, file string) (int64, error) {
+	var stdout bytes.Buffer
+	if err := e.exec(ctx, pod, container, []string{"stat", "-c", "%s", file}, &stdout); err != nil {
+		return 0, fmt.Errorf("unable to get the size of %s: %v", file, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(stdout.String()), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse the size of %s: %v", file, err)
+	}
+	return size, nil
+}
+
+// CopyFile writes the content of the file of the pod container in w, with cat
+func (e *PodExec) CopyFile(ctx context.Context, pod *kapiv1.Pod, container, file string, w io.Writer) error {
+	if err := e.exec(ctx, pod, container, []string{"cat", file}, w); err != nil {
+		return fmt.Errorf("unable to copy %s: %v", file, err)
+	}
+	return nil
+}
+
+func (e *PodExec) exec(ctx context.Context, pod *kapiv1.Pod, container string, command []string, w io.Writer) error {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec")
+	req.VersionedParams(&kapiv1.PodExecOptions{
+		Command:   command,
+		Container: container,
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	err = exec.Stream(remotecommand.StreamOptions{
+		Stdout: w,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("%s failed in pod %s/%s: %v: %s", command[0], pod.Namespace, pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores the backup files in a folder of the local filesystem
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage returns a storage backend writing the files in the root folder
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+func (s *LocalStorage) path(name string) (string, error) {
+	p := filepath.Join(s.root, filepath.FromSlash(name))
+	if !strings.HasPrefix(p, filepath.Clean(s.root)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid backup file name %q", name)
+	}
+	return p, nil
+}
+
+// Write writes the file in a temporary file renamed once complete, so that a partial file is never visible
+func (s *LocalStorage) Write(ctx context.Context, name string, r io.Reader, size int64) (int64, error) {
+	p, err := s.path(name)
+	if err != nil {
+		return 0, err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p))
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), p)
+}
+
+// Read opens the file for reading
+func (s *LocalStorage) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	p, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Delete deletes the file, and its folder once empty
+func (s *LocalStorage) Delete(ctx context.Context, name string) error {
+	p, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if dir := filepath.Dir(p); dir != filepath.Clean(s.root) {
+		// fails while other files are left in the folder
+		_ = os.Remove(dir)
+	}
+	return nil
+}
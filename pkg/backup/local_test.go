@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := os.MkdirTemp("", "redis-backup")
+	if err != nil {
+		t.Fatalf("unable to create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	storage := NewLocalStorage(dir)
+	ctx := context.Background()
+
+	if _, err = storage.Write(ctx, "../escape.rdb", bytes.NewReader(nil), 0); err == nil {
+		t.Errorf("Write() should reject a file outside of the root folder")
+	}
+	if n, err := storage.Write(ctx, "ns/other/shard-0.rdb", bytes.NewReader([]byte("REDIS")), 5); err != nil || n != 5 {
+		t.Errorf("Write() = %d, %v, want 5 bytes written", n, err)
+	}
+
+	manifest := &Manifest{
+		Namespace:   "ns",
+		ClusterName: "cluster",
+		Shards:      []ManifestShard{{File: ShardFileName("ns", "backup", 0), Slots: []string{"0-16383"}, Size: 5, Checksum: "foo"}},
+	}
+	name := ManifestFile("ns", "backup")
+	if err = WriteManifest(ctx, storage, name, manifest); err != nil {
+		t.Fatalf("WriteManifest() unexpected error: %v", err)
+	}
+	got, err := ReadManifest(ctx, storage, name)
+	if err != nil {
+		t.Fatalf("ReadManifest() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, manifest) {
+		t.Errorf("ReadManifest() = %v, want %v", got, manifest)
+	}
+
+	if err = storage.Delete(ctx, name); err != nil {
+		t.Errorf("Delete() unexpected error: %v", err)
+	}
+	if err = storage.Delete(ctx, name); err != nil {
+		t.Errorf("Delete() of a missing file unexpected error: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "ns", "backup")); !os.IsNotExist(err) {
+		t.Errorf("Delete() expected the empty backup folder to be removed")
+	}
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+)
+
+// ManifestFileName name of the file describing the content of a backup, written once all the RDB files are copied
+const ManifestFileName = "manifest.json"
+
+// Manifest describes the RDB files of a backup and the slots they cover
+type Manifest struct {
+	// Namespace of the backed up RedisCluster
+	Namespace string `json:"namespace"`
+	// ClusterName name of the backed up RedisCluster
+	ClusterName string `json:"clusterName"`
+	// CreationTime time when the backup completed
+	CreationTime time.Time `json:"creationTime"`
+	// Shards RDB files of the backup, one per shard
+	Shards []ManifestShard `json:"shards"`
+}
+
+// ManifestShard describes the RDB file of a shard
+type ManifestShard struct {
+	// File name of the RDB file in the backup storage
+	File string `json:"file"`
+	// Slots slot ranges covered by the RDB file
+	Slots []string `json:"slots"`
+	// Size of the RDB file in bytes
+	Size int64 `json:"size"`
+	// Checksum sha256 checksum of the RDB file
+	Checksum string `json:"checksum"`
+}
+
+// ManifestFile returns the name of the manifest file of a backup
+func ManifestFile(namespace, backupName string) string {
+	return path.Join(namespace, backupName, ManifestFileName)
+}
+
+// WriteManifest writes the manifest file in the storage
+func WriteManifest(ctx context.Context, storage Storage, name string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = storage.Write(ctx, name, bytes.NewReader(data), int64(len(data)))
+	return err
+}
+
+// ReadManifest reads the manifest file from the storage
+func ReadManifest(ctx context.Context, storage Storage, name string) (*Manifest, error) {
+	r, err := storage.Read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	manifest := &Manifest{}
+	if err = json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("unable to decode backup manifest %s: %v", name, err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,632 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"strconv"
+
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+// RDB opcodes
+const (
+	rdbOpcodeSlotInfo      = 244
+	rdbOpcodeFunction2     = 245
+	rdbOpcodeFunctionPreGA = 246
+	rdbOpcodeModuleAux     = 247
+	rdbOpcodeIdle          = 248
+	rdbOpcodeFreq          = 249
+	rdbOpcodeAux           = 250
+	rdbOpcodeResizeDB      = 251
+	rdbOpcodeExpireTimeMs  = 252
+	rdbOpcodeExpireTime    = 253
+	rdbOpcodeSelectDB      = 254
+	rdbOpcodeEOF           = 255
+)
+
+// RDB value types
+const (
+	rdbTypeString              = 0
+	rdbTypeList                = 1
+	rdbTypeSet                 = 2
+	rdbTypeZSet                = 3
+	rdbTypeHash                = 4
+	rdbTypeZSet2               = 5
+	rdbTypeModule2             = 7
+	rdbTypeHashZipmap          = 9
+	rdbTypeListZiplist         = 10
+	rdbTypeSetIntset           = 11
+	rdbTypeZSetZiplist         = 12
+	rdbTypeHashZiplist         = 13
+	rdbTypeListQuicklist       = 14
+	rdbTypeStreamListpacks     = 15
+	rdbTypeHashListpack        = 16
+	rdbTypeZSetListpack        = 17
+	rdbTypeListQuicklist2      = 18
+	rdbTypeStreamListpacks2    = 19
+	rdbTypeSetListpack         = 20
+	rdbTypeStreamListpacks3    = 21
+	rdbTypeHashMetadataPreGA   = 22
+	rdbTypeHashListpackExPreGA = 23
+	rdbTypeHashMetadata        = 24
+	rdbTypeHashListpackEx      = 25
+)
+
+// RDB length encodings
+const (
+	rdbLen6Bit    = 0
+	rdbLen14Bit   = 1
+	rdbLen32Bit   = 0x80
+	rdbLen64Bit   = 0x81
+	rdbEncVal     = 3
+	rdbEncInt8    = 0
+	rdbEncInt16   = 1
+	rdbEncInt32   = 2
+	rdbEncLZF     = 3
+	rdbModuleEOF  = 0
+	rdbModuleSInt = 1
+	rdbModuleUInt = 2
+	rdbModuleFlt  = 3
+	rdbModuleDbl  = 4
+	rdbModuleStr  = 5
+)
+
+// crc64Table table of the CRC-64 Jones polynomial, in its reflected form, used by redis to checksum the DUMP payloads
+var crc64Table = crc64.MakeTable(0x95ac9329ac4bc9b5)
+
+// RDBReader reads the keys of a RDB file and serializes them in the DUMP format,
+// so that they can be loaded in any node of a cluster with the RESTORE command.
+// Only the keys of the database 0, the only one used by a redis cluster, are returned.
+type RDBReader struct {
+	r       *bufio.Reader
+	version int
+	db      uint64
+	// value buffer of the value being read, nil when the read bytes are not captured
+	value *bytes.Buffer
+}
+
+// NewRDBReader reads the header of the RDB file and returns a RDBReader
+func NewRDBReader(r io.Reader) (*RDBReader, error) {
+	reader := &RDBReader{r: bufio.NewReader(r)}
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(reader.r, header); err != nil {
+		return nil, fmt.Errorf("unable to read the RDB header: %v", err)
+	}
+	if string(header[:5]) != "REDIS" {
+		return nil, errors.New("invalid RDB file, missing REDIS header")
+	}
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RDB version %q", header[5:])
+	}
+	reader.version = version
+	return reader, nil
+}
+
+// Version returns the RDB version of the file
+func (r *RDBReader) Version() int {
+	return r.version
+}
+
+// Next returns the next key of the RDB file, io.EOF is returned once all the keys are read
+func (r *RDBReader) Next() (*redis.DumpEntry, error) {
+	var expireAt int64
+	for {
+		opcode, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case rdbOpcodeEOF:
+			return nil, io.EOF
+		case rdbOpcodeSelectDB:
+			if r.db, err = r.readLength(); err != nil {
+				return nil, err
+			}
+		case rdbOpcodeResizeDB:
+			err = r.skipLengths(2)
+		case rdbOpcodeSlotInfo:
+			err = r.skipLengths(3)
+		case rdbOpcodeExpireTimeMs:
+			var ms uint64
+			ms, err = r.readUint64()
+			expireAt = int64(ms)
+		case rdbOpcodeExpireTime:
+			var buf []byte
+			if buf, err = r.readBytes(4); err == nil {
+				expireAt = int64(binary.LittleEndian.Uint32(buf)) * 1000
+			}
+		case rdbOpcodeIdle:
+			err = r.skipLengths(1)
+		case rdbOpcodeFreq:
+			_, err = r.readByte()
+		case rdbOpcodeAux:
+			if err = r.skipString(); err == nil {
+				err = r.skipString()
+			}
+		case rdbOpcodeFunction2:
+			err = r.skipString()
+		case rdbOpcodeModuleAux:
+			// module id, when opcode and when
+			if err = r.skipLengths(3); err == nil {
+				err = r.skipModuleValue()
+			}
+		case rdbOpcodeFunctionPreGA:
+			return nil, errors.New("functions saved by a redis 7.0 release candidate are not supported")
+		default:
+			key, err := r.readString()
+			if err != nil {
+				return nil, fmt.Errorf("unable to read key: %v", err)
+			}
+			payload, err := r.readValue(opcode)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the value of key %q: %v", key, err)
+			}
+			if r.db != 0 {
+				expireAt = 0
+				continue
+			}
+			return &redis.DumpEntry{Key: string(key), Payload: payload, ExpireAt: expireAt}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readValue reads a value of the given type and returns it in the DUMP format:
+// the type and the value as encoded in the RDB file, followed by the RDB version and a CRC-64 checksum.
+func (r *RDBReader) readValue(valueType byte) ([]byte, error) {
+	r.value = &bytes.Buffer{}
+	defer func() { r.value = nil }()
+	r.value.WriteByte(valueType)
+	if err := r.skipValue(valueType); err != nil {
+		return nil, err
+	}
+	footer := make([]byte, 10)
+	binary.LittleEndian.PutUint16(footer, uint16(r.version))
+	r.value.Write(footer[:2])
+	binary.LittleEndian.PutUint64(footer[2:], redisCRC64(r.value.Bytes()))
+	r.value.Write(footer[2:])
+	return r.value.Bytes(), nil
+}
+
+func (r *RDBReader) skipValue(valueType byte) error {
+	switch valueType {
+	case rdbTypeString, rdbTypeHashZipmap, rdbTypeListZiplist, rdbTypeSetIntset, rdbTypeZSetZiplist, rdbTypeHashZiplist,
+		rdbTypeHashListpack, rdbTypeZSetListpack, rdbTypeSetListpack, rdbTypeHashListpackExPreGA:
+		return r.skipString()
+	case rdbTypeList, rdbTypeSet, rdbTypeListQuicklist:
+		return r.skipStrings(1)
+	case rdbTypeHash:
+		return r.skipStrings(2)
+	case rdbTypeZSet, rdbTypeZSet2:
+		n, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err = r.skipString(); err != nil {
+				return err
+			}
+			if valueType == rdbTypeZSet2 {
+				_, err = r.readBytes(8)
+			} else {
+				err = r.skipDoubleString()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case rdbTypeListQuicklist2:
+		n, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			// container type and node content
+			if err = r.skipLengths(1); err != nil {
+				return err
+			}
+			if err = r.skipString(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case rdbTypeHashListpackEx:
+		// minimum expiration time of the fields
+		if _, err := r.readBytes(8); err != nil {
+			return err
+		}
+		return r.skipString()
+	case rdbTypeHashMetadata, rdbTypeHashMetadataPreGA:
+		if valueType == rdbTypeHashMetadata {
+			if _, err := r.readBytes(8); err != nil {
+				return err
+			}
+		}
+		n, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			// field expiration time, field and value
+			if valueType == rdbTypeHashMetadata {
+				err = r.skipLengths(1)
+			} else {
+				_, err = r.readBytes(8)
+			}
+			if err == nil {
+				err = r.skipString()
+			}
+			if err == nil {
+				err = r.skipString()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case rdbTypeStreamListpacks, rdbTypeStreamListpacks2, rdbTypeStreamListpacks3:
+		return r.skipStream(valueType)
+	case rdbTypeModule2:
+		if err := r.skipLengths(1); err != nil {
+			return err
+		}
+		return r.skipModuleValue()
+	}
+	return fmt.Errorf("unsupported RDB value type %d", valueType)
+}
+
+// skipStrings reads a number of elements followed by n strings per element
+func (r *RDBReader) skipStrings(n uint64) error {
+	count, err := r.readLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < count*n; i++ {
+		if err := r.skipString(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RDBReader) skipStream(valueType byte) error {
+	// listpacks of the entries, each one with its master ID
+	if err := r.skipStrings(2); err != nil {
+		return err
+	}
+	// number of elements and last ID
+	nbLengths := 3
+	if valueType >= rdbTypeStreamListpacks2 {
+		// first ID, max deleted entry ID and entries added
+		nbLengths += 5
+	}
+	if err := r.skipLengths(nbLengths); err != nil {
+		return err
+	}
+	nbGroups, err := r.readLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nbGroups; i++ {
+		if err = r.skipString(); err != nil {
+			return err
+		}
+		// last ID, and entries read since version 2
+		nbLengths = 2
+		if valueType >= rdbTypeStreamListpacks2 {
+			nbLengths++
+		}
+		if err = r.skipLengths(nbLengths); err != nil {
+			return err
+		}
+		// pending entries list: raw ID, delivery time and delivery count
+		nbPending, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < nbPending; j++ {
+			if _, err = r.readBytes(16 + 8); err != nil {
+				return err
+			}
+			if err = r.skipLengths(1); err != nil {
+				return err
+			}
+		}
+		nbConsumers, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < nbConsumers; j++ {
+			if err = r.skipString(); err != nil {
+				return err
+			}
+			// seen time, and active time since version 3
+			timeSize := 8
+			if valueType >= rdbTypeStreamListpacks3 {
+				timeSize += 8
+			}
+			if _, err = r.readBytes(timeSize); err != nil {
+				return err
+			}
+			nbConsumerPending, err := r.readLength()
+			if err != nil {
+				return err
+			}
+			if _, err = r.readBytes(int(nbConsumerPending) * 16); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipModuleValue reads the opcodes of a value serialized by a module, until the module EOF opcode
+func (r *RDBReader) skipModuleValue() error {
+	for {
+		opcode, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case rdbModuleEOF:
+			return nil
+		case rdbModuleSInt, rdbModuleUInt:
+			err = r.skipLengths(1)
+		case rdbModuleFlt:
+			_, err = r.readBytes(4)
+		case rdbModuleDbl:
+			_, err = r.readBytes(8)
+		case rdbModuleStr:
+			err = r.skipString()
+		default:
+			return fmt.Errorf("unknown module opcode %d", opcode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skipDoubleString reads a double encoded as a string, used by the first version of sorted sets
+func (r *RDBReader) skipDoubleString() error {
+	n, err := r.readByte()
+	if err != nil {
+		return err
+	}
+	// 253, 254 and 255 encode NaN, +inf and -inf
+	if n >= 253 {
+		return nil
+	}
+	_, err = r.readBytes(int(n))
+	return err
+}
+
+func (r *RDBReader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	if r.value != nil {
+		r.value.WriteByte(b)
+	}
+	return b, nil
+}
+
+func (r *RDBReader) readBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if r.value != nil {
+		r.value.Write(buf)
+	}
+	return buf, nil
+}
+
+func (r *RDBReader) readUint64() (uint64, error) {
+	buf, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// readLengthEncoding returns a length, or the encoding of the string if encoded is true
+func (r *RDBReader) readLengthEncoding() (length uint64, encoded bool, err error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case rdbLen6Bit:
+		return uint64(b & 0x3f), false, nil
+	case rdbLen14Bit:
+		next, err := r.readByte()
+		return uint64(b&0x3f)<<8 | uint64(next), false, err
+	case rdbEncVal:
+		return uint64(b & 0x3f), true, nil
+	}
+	switch b {
+	case rdbLen32Bit:
+		buf, err := r.readBytes(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(buf)), false, nil
+	case rdbLen64Bit:
+		buf, err := r.readBytes(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(buf), false, nil
+	}
+	return 0, false, fmt.Errorf("unknown length encoding %d", b)
+}
+
+func (r *RDBReader) readLength() (uint64, error) {
+	length, encoded, err := r.readLengthEncoding()
+	if err == nil && encoded {
+		err = errors.New("unexpected encoded value instead of a length")
+	}
+	return length, err
+}
+
+func (r *RDBReader) skipLengths(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := r.readLength(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readString reads and decodes a string
+func (r *RDBReader) readString() ([]byte, error) {
+	length, encoded, err := r.readLengthEncoding()
+	if err != nil {
+		return nil, err
+	}
+	if !encoded {
+		return r.readBytes(int(length))
+	}
+	switch length {
+	case rdbEncInt8:
+		buf, err := r.readBytes(1)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int8(buf[0])))), nil
+	case rdbEncInt16:
+		buf, err := r.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int16(binary.LittleEndian.Uint16(buf))))), nil
+	case rdbEncInt32:
+		buf, err := r.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int32(binary.LittleEndian.Uint32(buf))))), nil
+	case rdbEncLZF:
+		compressedLen, err := r.readLength()
+		if err != nil {
+			return nil, err
+		}
+		length, err := r.readLength()
+		if err != nil {
+			return nil, err
+		}
+		compressed, err := r.readBytes(int(compressedLen))
+		if err != nil {
+			return nil, err
+		}
+		return lzfDecompress(compressed, int(length))
+	}
+	return nil, fmt.Errorf("unknown string encoding %d", length)
+}
+
+// skipString reads a string without decoding it
+func (r *RDBReader) skipString() error {
+	length, encoded, err := r.readLengthEncoding()
+	if err != nil {
+		return err
+	}
+	if !encoded {
+		return r.discard(int64(length))
+	}
+	switch length {
+	case rdbEncInt8:
+		return r.discard(1)
+	case rdbEncInt16:
+		return r.discard(2)
+	case rdbEncInt32:
+		return r.discard(4)
+	case rdbEncLZF:
+		compressedLen, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		if err = r.skipLengths(1); err != nil {
+			return err
+		}
+		return r.discard(int64(compressedLen))
+	}
+	return fmt.Errorf("unknown string encoding %d", length)
+}
+
+// discard reads n bytes, copied in the value buffer if a value is captured
+func (r *RDBReader) discard(n int64) error {
+	var w io.Writer = io.Discard
+	if r.value != nil {
+		w = r.value
+	}
+	written, err := io.CopyN(w, r.r, n)
+	if written < n && (err == nil || err == io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// lzfDecompress decompresses a string compressed by redis with the LZF algorithm
+func lzfDecompress(in []byte, length int) ([]byte, error) {
+	out := make([]byte, 0, length)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// literal run of ctrl+1 bytes
+			end := i + ctrl + 1
+			if end > len(in) {
+				return nil, errors.New("invalid LZF literal run")
+			}
+			out = append(out, in[i:end]...)
+			i = end
+			continue
+		}
+		// back reference of n+2 bytes
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, errors.New("invalid LZF back reference")
+			}
+			n += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errors.New("invalid LZF back reference")
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errors.New("invalid LZF back reference offset")
+		}
+		for j := 0; j < n+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != length {
+		return nil, fmt.Errorf("invalid LZF string length %d, expected %d", len(out), length)
+	}
+	return out, nil
+}
+
+// redisCRC64 returns the CRC-64 Jones checksum used by redis, without the initial and final inversions of hash/crc64
+func redisCRC64(data []byte) uint64 {
+	return ^crc64.Update(^uint64(0), crc64Table, data)
+}
@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+func Test_redisCRC64(t *testing.T) {
+	if got := redisCRC64([]byte("123456789")); got != 0xe9c6d914c4b8d9ca {
+		t.Errorf("redisCRC64() = %x, want e9c6d914c4b8d9ca", got)
+	}
+}
+
+func Test_lzfDecompress(t *testing.T) {
+	// literal "a", then a back reference of 9 bytes at offset 1
+	got, err := lzfDecompress([]byte{0x00, 'a', 0xe0, 0x00, 0x00}, 10)
+	if err != nil {
+		t.Fatalf("lzfDecompress() unexpected error: %v", err)
+	}
+	if string(got) != "aaaaaaaaaa" {
+		t.Errorf("lzfDecompress() = %q, want %q", got, "aaaaaaaaaa")
+	}
+	if _, err = lzfDecompress([]byte{0x00, 'a', 0xe0, 0x00, 0x00}, 11); err == nil {
+		t.Errorf("lzfDecompress() should return an error when the length does not match")
+	}
+	if _, err = lzfDecompress([]byte{0x20, 0x00}, 2); err == nil {
+		t.Errorf("lzfDecompress() should return an error with a back reference before the start")
+	}
+}
+
+func TestRDBReader(t *testing.T) {
+	rdb := &bytes.Buffer{}
+	rdb.WriteString("REDIS0010")
+	// aux fields, database selection and resize
+	rdb.Write([]byte{rdbOpcodeAux, 9})
+	rdb.WriteString("redis-ver")
+	rdb.Write([]byte{5})
+	rdb.WriteString("6.2.7")
+	rdb.Write([]byte{rdbOpcodeSelectDB, 0, rdbOpcodeResizeDB, 3, 1})
+	// "mykey" integer value 10
+	rdb.Write([]byte{rdbTypeString, 5})
+	rdb.WriteString("mykey")
+	rdb.Write([]byte{0xc0, 10})
+	// LZF compressed key with an expiration and a list value
+	rdb.Write([]byte{rdbOpcodeExpireTimeMs, 0xe8, 0x03, 0, 0, 0, 0, 0, 0})
+	rdb.Write([]byte{rdbTypeList, 0xc3, 5, 10, 0x00, 'a', 0xe0, 0x00, 0x00})
+	listValue := []byte{2, 1, 'x', 0xc1, 0x39, 0x30}
+	rdb.Write(listValue)
+	// LFU frequency, then a sorted set with a 14 bits length member and an infinite score
+	rdb.Write([]byte{rdbOpcodeFreq, 5, rdbTypeZSet, 4})
+	rdb.WriteString("zset")
+	member := bytes.Repeat([]byte("m"), 100)
+	zsetValue := append(append([]byte{1, 0x40, 100}, member...), 254)
+	rdb.Write(zsetValue)
+	// key of another database
+	rdb.Write([]byte{rdbOpcodeSelectDB, 1, rdbTypeString, 3})
+	rdb.WriteString("foo")
+	rdb.Write([]byte{3})
+	rdb.WriteString("bar")
+	rdb.Write([]byte{rdbOpcodeEOF, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	reader, err := NewRDBReader(rdb)
+	if err != nil {
+		t.Fatalf("NewRDBReader() unexpected error: %v", err)
+	}
+	if reader.Version() != 10 {
+		t.Errorf("Version() = %d, want 10", reader.Version())
+	}
+	var entries []*redis.DumpEntry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	dumpPayload := func(value []byte) []byte {
+		payload := append(append([]byte{}, value...), 10, 0)
+		crc := redisCRC64(payload)
+		for i := 0; i < 8; i++ {
+			payload = append(payload, byte(crc>>(8*i)))
+		}
+		return payload
+	}
+	expected := []*redis.DumpEntry{
+		// payload returned by DUMP mykey in the redis documentation
+		{Key: "mykey", Payload: []byte("\x00\xc0\n\n\x00n\x9fWE\x0e\xaec\xbb")},
+		{Key: "aaaaaaaaaa", Payload: dumpPayload(append([]byte{rdbTypeList}, listValue...)), ExpireAt: 1000},
+		{Key: "zset", Payload: dumpPayload(append([]byte{rdbTypeZSet}, zsetValue...))},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Next() entries = %v, want %v", entries, expected)
+	}
+}
+
+func TestRDBReaderErrors(t *testing.T) {
+	if _, err := NewRDBReader(bytes.NewReader([]byte("RDB00010"))); err == nil {
+		t.Errorf("NewRDBReader() should return an error without the REDIS header")
+	}
+	reader, err := NewRDBReader(bytes.NewReader([]byte{'R', 'E', 'D', 'I', 'S', '0', '0', '1', '0', 100, 3, 'f', 'o', 'o', 0}))
+	if err != nil {
+		t.Fatalf("NewRDBReader() unexpected error: %v", err)
+	}
+	if _, err = reader.Next(); err == nil {
+		t.Errorf("Next() should return an error with an unknown value type")
+	}
+	reader, _ = NewRDBReader(bytes.NewReader([]byte{'R', 'E', 'D', 'I', 'S', '0', '0', '1', '0', rdbTypeString, 3, 'f', 'o', 'o', 10, 'b'}))
+	if _, err = reader.Next(); err == nil {
+		t.Errorf("Next() should return an error with a truncated value")
+	}
+}
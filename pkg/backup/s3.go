@@ -0,0 +1,415 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	// unsignedPayload is used instead of the payload hash, so that the RDB files are streamed to the endpoint
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	emptyPayloadSHA = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	amzDateFormat   = "20060102T150405Z"
+	// defaultS3PartSize minimum size of the parts of the multipart uploads: S3 requires at least 5MiB except for
+	// the last part, files larger than 312GiB are uploaded with larger parts to fit in the maximum number of parts
+	defaultS3PartSize = 32 << 20
+	// maxS3Parts maximum number of parts of a multipart upload
+	maxS3Parts = 10000
+	// maxS3PartSize maximum size of a part of a multipart upload
+	maxS3PartSize = 5 << 30
+)
+
+// S3Credentials credentials used to sign the S3 requests
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3Storage stores the backup files in an S3 compatible object storage, such as AWS S3 or MinIO.
+// Objects are addressed with path-style URLs and requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint    *url.URL
+	bucket      string
+	prefix      string
+	region      string
+	credentials S3Credentials
+	client      *http.Client
+	now         func() time.Time
+	partSize    int
+	maxParts    int
+}
+
+// NewS3Storage returns a storage backend writing the files in the bucket, under the prefix
+func NewS3Storage(endpoint, bucket, prefix, region string, credentials S3Credentials) (*S3Storage, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %v", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: scheme must be http or https", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is empty")
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return nil, errors.New("S3 credentials are empty")
+	}
+	if region == "" {
+		region = defaultS3Region
+	}
+	return &S3Storage{
+		endpoint:    u,
+		bucket:      bucket,
+		prefix:      prefix,
+		region:      region,
+		credentials: credentials,
+		client:      http.DefaultClient,
+		now:         time.Now,
+		partSize:    defaultS3PartSize,
+		maxParts:    maxS3Parts,
+	}, nil
+}
+
+// Write uploads the file while it is read: a file smaller than a part is uploaded in a single PUT request,
+// larger files with a multipart upload, so that the whole file is never held in memory
+func (s *S3Storage) Write(ctx context.Context, name string, r io.Reader, size int64) (int64, error) {
+	partSize, err := s.getPartSize(size)
+	if err != nil {
+		return 0, fmt.Errorf("unable to upload %s: %v", name, err)
+	}
+	part := make([]byte, partSize)
+	n, err := io.ReadFull(r, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return int64(n), s.putObject(ctx, name, part[:n])
+	}
+	if err != nil {
+		return 0, err
+	}
+	uploadID, err := s.createMultipartUpload(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	written, err := s.uploadParts(ctx, name, uploadID, r, part)
+	if err != nil {
+		// the uploaded parts are stored until the upload is aborted
+		if abortErr := s.abortMultipartUpload(ctx, name, uploadID); abortErr != nil {
+			return written, fmt.Errorf("%v, and unable to abort the multipart upload: %v", err, abortErr)
+		}
+		return written, err
+	}
+	return written, nil
+}
+
+// getPartSize returns the size of the parts of the multipart upload of a file of the given size, so that the file
+// fits in the maximum number of parts
+func (s *S3Storage) getPartSize(size int64) (int, error) {
+	partSize := int64(s.partSize)
+	maxParts := int64(s.maxParts)
+	if size > partSize*maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	if partSize > maxS3PartSize {
+		return 0, fmt.Errorf("%d bytes exceed the maximum size of a multipart upload", size)
+	}
+	return int(partSize), nil
+}
+
+func (s *S3Storage) putObject(ctx context.Context, name string, data []byte) error {
+	req, err := s.newRequest(ctx, http.MethodPut, name, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		// an empty body must be sent with an explicit content length
+		req.Body = http.NoBody
+	}
+	resp, err := s.do(req, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// s3CompletedPart part of a multipart upload, in the CompleteMultipartUpload request
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadParts uploads the first part, already read in the buffer, and the parts read from r until EOF,
+// then completes the multipart upload
+func (s *S3Storage) uploadParts(ctx context.Context, name, uploadID string, r io.Reader, part []byte) (int64, error) {
+	var written int64
+	var parts []s3CompletedPart
+	n := len(part)
+	for n > 0 {
+		if len(parts) == s.maxParts {
+			// the file is larger than its expected size
+			return written, fmt.Errorf("unable to upload %s in %d parts of %d bytes", name, s.maxParts, len(part))
+		}
+		etag, err := s.uploadPart(ctx, name, uploadID, len(parts)+1, part[:n])
+		if err != nil {
+			return written, err
+		}
+		written += int64(n)
+		parts = append(parts, s3CompletedPart{PartNumber: len(parts) + 1, ETag: etag})
+		n, err = io.ReadFull(r, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return written, err
+		}
+	}
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return written, err
+	}
+	req, err := s.newRequest(ctx, http.MethodPost, name, bytes.NewReader(body))
+	if err != nil {
+		return written, err
+	}
+	req.URL.RawQuery = url.Values{"uploadId": {uploadID}}.Encode()
+	resp, err := s.do(req, hexSHA256(body))
+	if err != nil {
+		return written, err
+	}
+	defer resp.Body.Close()
+	// the endpoint may answer with a 200 status and an error in the body once the parts are assembled
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return written, err
+	}
+	if bytes.Contains(data, []byte("<Error>")) {
+		return written, &S3Error{Method: req.Method, Path: req.URL.Path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	return written, nil
+}
+
+func (s *S3Storage) createMultipartUpload(ctx context.Context, name string) (string, error) {
+	req, err := s.newRequest(ctx, http.MethodPost, name, nil)
+	if err != nil {
+		return "", err
+	}
+	req.URL.RawQuery = "uploads="
+	resp, err := s.do(req, emptyPayloadSHA)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	result := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+	if err = xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("unable to decode the multipart upload of %s: %v", name, err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("no upload ID for the multipart upload of %s", name)
+	}
+	return result.UploadID, nil
+}
+
+func (s *S3Storage) uploadPart(ctx context.Context, name, uploadID string, partNumber int, data []byte) (string, error) {
+	req, err := s.newRequest(ctx, http.MethodPut, name, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.URL.RawQuery = url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}.Encode()
+	resp, err := s.do(req, unsignedPayload)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (s *S3Storage) abortMultipartUpload(ctx context.Context, name, uploadID string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = url.Values{"uploadId": {uploadID}}.Encode()
+	resp, err := s.do(req, emptyPayloadSHA)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Read downloads the file
+func (s *S3Storage) Read(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadSHA)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete deletes the file
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadSHA)
+	if err != nil {
+		var s3Err *S3Error
+		if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, name string, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = "/" + path.Join(strings.Trim(u.Path, "/"), s.bucket, s.prefix, name)
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	signRequest(req, s.credentials, s.region, payloadHash, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &S3Error{
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+	return resp, nil
+}
+
+// S3Error error returned when the S3 endpoint answers with an unexpected status code
+type S3Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("S3 %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// signRequest adds the AWS Signature Version 4 authorization header to the request,
+// the host header and all the headers already set on the request are signed
+func signRequest(req *http.Request, credentials S3Credentials, region, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{date, region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		vals := append([]string{}, values[key]...)
+		sort.Strings(vals)
+		for _, val := range vals {
+			params = append(params, uriEncode(key, true)+"="+uriEncode(val, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes every byte except the unreserved characters, as specified by AWS Signature Version 4
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
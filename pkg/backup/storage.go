@@ -0,0 +1,58 @@
+// Package backup contains the storage backends where the RDB files of the redis nodes are copied, and the reader used to restore them
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+// Storage interface of a backup storage backend, files are identified by a slash separated name
+type Storage interface {
+	// Write writes the content read from r in the file until EOF, and returns the number of bytes written.
+	// size is the expected size of the content, or -1 when it is unknown.
+	Write(ctx context.Context, name string, r io.Reader, size int64) (int64, error)
+	// Read opens the file for reading
+	Read(ctx context.Context, name string) (io.ReadCloser, error)
+	// Delete deletes the file, deleting a missing file is not an error
+	Delete(ctx context.Context, name string) error
+}
+
+// NewStorage returns the storage backend configured in the backup storage spec, the credentials are read
+// from secrets of the given namespace
+func NewStorage(ctx context.Context, kubeClient kclient.Client, namespace string, spec rapi.BackupStorage) (Storage, error) {
+	switch {
+	case spec.Local != nil && spec.S3 != nil:
+		return nil, errors.New("only one backup storage backend can be set")
+	case spec.Local != nil:
+		if spec.Local.Path == "" {
+			return nil, errors.New("local backup storage path is empty")
+		}
+		return NewLocalStorage(spec.Local.Path), nil
+	case spec.S3 != nil:
+		secret := &kapiv1.Secret{}
+		namespacedName := types.NamespacedName{Name: spec.S3.CredentialsSecret, Namespace: namespace}
+		if err := kubeClient.Get(ctx, namespacedName, secret); err != nil {
+			return nil, fmt.Errorf("unable to get S3 credentials secret %s: %v", namespacedName, err)
+		}
+		credentials := S3Credentials{
+			AccessKeyID:     string(secret.Data[rapi.S3AccessKeyIDKey]),
+			SecretAccessKey: string(secret.Data[rapi.S3SecretAccessKeyKey]),
+		}
+		return NewS3Storage(spec.S3.Endpoint, spec.S3.Bucket, spec.S3.Prefix, spec.S3.Region, credentials)
+	}
+	return nil, errors.New("no backup storage backend set")
+}
+
+// ShardFileName returns the name of the RDB file of a shard of a backup
+func ShardFileName(namespace, backupName string, shard int) string {
+	return path.Join(namespace, backupName, fmt.Sprintf("shard-%d.rdb", shard))
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
 	"path"
 
@@ -46,6 +47,10 @@ type Redis struct {
 	MaxMemory           uint64
 	MaxMemoryPolicy     string
 	ConfigFiles         []string
+	// Username, Password and TLSConfig of the redis nodes, only set on the copy of the configuration built for a RedisCluster
+	Username  string
+	Password  string
+	TLSConfig *tls.Config
 }
 
 // AddFlags use to add the Redis config flags to the command line
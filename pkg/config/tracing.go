@@ -0,0 +1,26 @@
+package config
+
+import "github.com/spf13/pflag"
+
+const (
+	// TracingExporterNone disables the export of the spans
+	TracingExporterNone = "none"
+	// TracingExporterOTLP exports the spans to an OTLP gRPC endpoint
+	TracingExporterOTLP = "otlp"
+)
+
+// Tracing used to store the tracing configuration
+type Tracing struct {
+	Exporter      string
+	Endpoint      string
+	Insecure      bool
+	SamplingRatio float64
+}
+
+// AddFlags use to add the tracing config flags to the command line
+func (t *Tracing) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&t.Exporter, "tracing-exporter", TracingExporterNone, "Exporter of the tracing spans: none or otlp")
+	fs.StringVar(&t.Endpoint, "tracing-endpoint", "", "Address of the OTLP gRPC endpoint receiving the spans, OTEL_EXPORTER_OTLP_ENDPOINT is used when empty")
+	fs.BoolVar(&t.Insecure, "tracing-insecure", false, "Disable TLS on the connection to the OTLP endpoint")
+	fs.Float64Var(&t.SamplingRatio, "tracing-sampling-ratio", 1, "Ratio of the reconciliations traced, between 0 and 1")
+}
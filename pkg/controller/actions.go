@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
@@ -15,26 +16,29 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/api/core/v1"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/config"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller/clustering"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller/sanitycheck"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
 )
 
-func (c *Controller) clusterAction(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos) (ctrl.Result, error) {
+func (c *Controller) clusterAction(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, redisConfig *config.Redis) (ctrl.Result, error) {
 	var err error
 	result := ctrl.Result{}
 	// run sanity check if needed
-	needSanity, err := sanitycheck.RunSanityChecks(ctx, admin, &c.config.redis, c.podControl, cluster, infos, true)
+	needSanity, err := sanitycheck.RunSanityChecks(ctx, admin, redisConfig, c.podControl, cluster, infos, true)
 	if err != nil {
 		glog.Errorf("[clusterAction] cluster %s/%s, an error occurs during sanity check: %v ", cluster.Namespace, cluster.Name, err)
 		return result, err
 	}
 	if needSanity {
 		glog.V(3).Infof("[clusterAction] run sanity check cluster: %s/%s", cluster.Namespace, cluster.Name)
-		result.Requeue, err = sanitycheck.RunSanityChecks(ctx, admin, &c.config.redis, c.podControl, cluster, infos, false)
+		result.Requeue, err = sanitycheck.RunSanityChecks(ctx, admin, redisConfig, c.podControl, cluster, infos, false)
 		return result, err
 	}
 
@@ -51,10 +55,11 @@ func (c *Controller) clusterAction(ctx context.Context, admin redis.AdminInterfa
 // applyConfiguration apply new configuration if needed:
 // - add or delete pods
 // - configure the redis-server process
-func (c *Controller) applyConfiguration(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster) (ctrl.Result, error) {
+func (c *Controller) applyConfiguration(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster) (result ctrl.Result, err error) {
 	glog.V(6).Info("applyConfiguration START")
 	defer glog.V(6).Info("applyConfiguration STOP")
-	result := ctrl.Result{}
+	ctx, span := tracing.Start(ctx, "Controller.applyConfiguration")
+	defer func() { tracing.End(span, err) }()
 	newCluster, nodes, err := newRedisCluster(ctx, admin, cluster, c.client)
 	if err != nil {
 		glog.Errorf("unable to create the RedisCluster view: %v", err)
@@ -70,6 +75,9 @@ func (c *Controller) applyConfiguration(ctx context.Context, admin redis.AdminIn
 	}
 	if c.needsRollingUpdate(cluster) {
 		glog.Info("applyConfiguration needRollingUpdate")
+		if cluster.Spec.RollingUpdate != nil && cluster.Spec.RollingUpdate.Method == rapi.RollingUpdateMethodFailover {
+			return c.manageFailoverRollingUpdate(ctx, admin, cluster, nodes)
+		}
 		pods, err := c.createMigrationPods(ctx, cluster)
 		if err != nil {
 			return result, err
@@ -82,7 +90,13 @@ func (c *Controller) applyConfiguration(ctx context.Context, admin redis.AdminIn
 		if err != nil {
 			return result, err
 		}
-		return result, c.manageRollingUpdate(ctx, admin, cluster, newCluster, oldNodes, newNodes)
+		nbPrimariesToReplace := getNbPrimariesToReplace(cluster, newNodes)
+		if nbPrimariesToReplace == 0 {
+			// the surge is below the pods of a shard: the pods of the old replicas are recreated with the new pod template
+			result.RequeueAfter = requeueDelay
+			return result, c.deleteOldReplica(ctx, admin, cluster, oldNodes)
+		}
+		return result, c.manageRollingUpdate(ctx, admin, cluster, newCluster, oldNodes, newNodes, nbPrimariesToReplace)
 	}
 	if c.needsLessPods(cluster) {
 		glog.Info("applyConfiguration needLessPods")
@@ -96,6 +110,11 @@ func (c *Controller) applyConfiguration(ctx context.Context, admin redis.AdminIn
 		return result, err
 	}
 
+	if c.needsRestore(cluster) {
+		glog.Info("applyConfiguration needsRestore")
+		return c.restoreCluster(ctx, admin, cluster, newCluster, nodes)
+	}
+
 	result.Requeue, err = scalingOperations(ctx, admin, cluster, newCluster, nodes)
 	if err != nil {
 		return result, err
@@ -119,10 +138,12 @@ func (c *Controller) createMigrationPods(ctx context.Context, cluster *rapi.Redi
 }
 
 // manageRollingUpdate used to manage properly a cluster rolling update if the pod template spec has changed
-func (c *Controller) manageRollingUpdate(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, oldNodes, newNodes redis.Nodes) error {
+func (c *Controller) manageRollingUpdate(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, oldNodes, newNodes redis.Nodes, nbPrimariesToReplace int32) (err error) {
+	ctx, span := tracing.Start(ctx, "Controller.manageRollingUpdate", attribute.Int("redis.nodes.old", len(oldNodes)), attribute.Int("redis.nodes.new", len(newNodes)), attribute.Int("redis.primaries.replaced", int(nbPrimariesToReplace)))
+	defer func() { tracing.End(span, err) }()
 	oldPrimaries, oldReplicas, _ := clustering.ClassifyNodesByRole(oldNodes)
 	newPrimaries, newReplicas, newPrimariesNoSlots := clustering.ClassifyNodesByRole(newNodes)
-	selectedPrimaries, selectedNewPrimaries, err := clustering.SelectPrimariesToReplace(oldPrimaries, newPrimaries, newPrimariesNoSlots, *cluster.Spec.NumberOfPrimaries, 1)
+	selectedPrimaries, selectedNewPrimaries, err := clustering.SelectPrimariesToReplace(oldPrimaries, newPrimaries, newPrimariesNoSlots, *cluster.Spec.NumberOfPrimaries, nbPrimariesToReplace)
 	if err != nil {
 		glog.Errorf("error while selecting primaries to replace: %v", err)
 	}
@@ -167,6 +188,45 @@ func (c *Controller) manageRollingUpdate(ctx context.Context, admin redis.AdminI
 	return nil
 }
 
+// getNbPrimariesToReplace returns the number of primaries replaced by the next pass of the rolling update, up to the
+// maximum number of unavailable shards, with the new nodes without slots. It returns 0 when the surge is below the pods
+// of a shard and the new nodes are not enough to replace a primary and its replicas.
+func getNbPrimariesToReplace(cluster *rapi.RedisCluster, newNodes redis.Nodes) int32 {
+	nbShardPods := 1 + *cluster.Spec.ReplicationFactor
+	_, _, newPrimariesNoSlots := clustering.ClassifyNodesByRole(newNodes)
+	nbPrimaries := int32(len(newPrimariesNoSlots)) / nbShardPods
+	if maxUnavailable := utils.GetMaxUnavailableShards(cluster); nbPrimaries > maxUnavailable {
+		nbPrimaries = maxUnavailable
+	}
+	if nbPrimaries == 0 && utils.GetMaxSurge(cluster) >= nbShardPods {
+		nbPrimaries = 1
+	}
+	return nbPrimaries
+}
+
+// deleteOldReplica deletes a replica running a previous pod template to make room for a pod running the pod template.
+// The replicas of the primary replaced next are deleted first. A pod is created above the pods of the cluster when
+// there is no old replica left to delete.
+func (c *Controller) deleteOldReplica(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, oldNodes redis.Nodes) error {
+	oldPrimaries, oldReplicas, _ := clustering.ClassifyNodesByRole(oldNodes)
+	if len(oldReplicas) == 0 {
+		_, err := c.createPod(ctx, cluster)
+		return err
+	}
+	replica := oldReplicas[0]
+	// the rolling update keeps the first old primaries in the sort order
+	oldPrimaries = oldPrimaries.SortNodes()
+	for i := len(oldPrimaries) - 1; i >= 0; i-- {
+		primaryID := oldPrimaries[i].ID
+		if replicas := oldReplicas.FilterByFunc(func(n *redis.Node) bool { return n.PrimaryReferent == primaryID }); len(replicas) > 0 {
+			replica = replicas[0]
+			break
+		}
+	}
+	glog.Infof("rolling update without surge: deleting replica %s to make room for a new pod", replica.ID)
+	return c.detachForgetDeleteNode(ctx, admin, cluster, replica)
+}
+
 // managePodScaleDown used to manage the scale down of a cluster
 func (c *Controller) managePodScaleDown(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, newCluster *redis.Cluster, nodes redis.Nodes) (bool, error) {
 	glog.V(6).Info("managePodScaleDown START")
@@ -206,6 +266,19 @@ func (c *Controller) needsRollingUpdate(cluster *rapi.RedisCluster) bool {
 	return needsUpdate
 }
 
+// isPaused sets the Paused condition, and emits an event when the reconciliation is paused or resumed
+func (c *Controller) isPaused(cluster *rapi.RedisCluster) bool {
+	paused := cluster.Spec.Paused
+	wasPaused := hasCondition(&cluster.Status, rapi.RedisClusterPaused)
+	setPausedCondition(&cluster.Status, paused)
+	if paused && !wasPaused {
+		c.recorder.Event(cluster, v1.EventTypeNormal, "Paused", "Reconciliation paused, the redis nodes and pods are not modified")
+	} else if !paused && wasPaused {
+		c.recorder.Event(cluster, v1.EventTypeNormal, "Resumed", "Reconciliation resumed")
+	}
+	return paused
+}
+
 func (c *Controller) needsMorePods(cluster *rapi.RedisCluster) bool {
 	needsMorePods := needMorePods(cluster)
 	if setScalingCondition(&cluster.Status, needsMorePods) {
@@ -433,6 +506,7 @@ func newRedisCluster(ctx context.Context, admin redis.AdminInterface, cluster *r
 	}
 
 	rCluster.KubeNodes = kubeNodes
+	rCluster.TopologyKeys = utils.GetTopologyKeys(cluster)
 	for _, node := range nodes {
 		rCluster.Nodes[node.ID] = node
 	}
@@ -444,6 +518,13 @@ func newRedisCluster(ctx context.Context, admin redis.AdminInterface, cluster *r
 		}
 	}
 
+	pinnedSlots, err := getPinnedSlots(ctx, kubeClient, cluster)
+	if err != nil {
+		glog.Errorf("error getting the slots pinned by slot migrations: %v", err)
+		return nil, nil, err
+	}
+	rCluster.PinnedSlots = pinnedSlots
+
 	return rCluster, nodes, nil
 }
 
@@ -547,7 +628,7 @@ func scaleDown(ctx context.Context, admin redis.AdminInterface, cluster *rapi.Re
 }
 
 func getNodesWithNewHash(cluster *rapi.RedisCluster, nodes redis.Nodes) (redis.Nodes, redis.Nodes, error) {
-	clusterPodSpecHash, err := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
+	clusterPodSpecHash, err := podctrl.GeneratePodHash(cluster)
 	if err != nil {
 		return redis.Nodes{}, redis.Nodes{}, err
 	}
@@ -620,19 +701,22 @@ func getOldNodesToRemove(currPrimaries, newPrimaries, nodes redis.Nodes) (redis.
 	return removedPrimaries, removedReplicas
 }
 
-func updateConfig(ctx context.Context, admin redis.AdminInterface, config map[string]string) {
+// updateConfig applies the config on the redis node, returns the sorted fields rejected by the node
+func updateConfig(ctx context.Context, admin redis.AdminInterface, addr string, config map[string]string) []string {
 	var errs []error
+	var failedFields []string
 	for field, val := range config {
-		glog.V(6).Infof("updating config option from %s to %s", field, val)
-		for addr := range admin.Connections().GetAll() {
-			if err := admin.SetConfig(ctx, addr, []string{field, val}); err != nil {
-				errs = append(errs, err)
-			}
+		glog.V(6).Infof("updating config option of node %s from %s to %s", addr, field, val)
+		if err := admin.SetConfig(ctx, addr, []string{field, val}); err != nil {
+			errs = append(errs, err)
+			failedFields = append(failedFields, field)
 		}
 	}
 	if len(errs) > 0 {
-		glog.Errorf("unable to update config: %v", errors.NewAggregate(errs))
+		glog.Errorf("unable to update config of node %s: %v", addr, errors.NewAggregate(errs))
 	}
+	sort.Strings(failedFields)
+	return failedFields
 }
 
 func detachAndForgetNodes(ctx context.Context, admin redis.AdminInterface, primaries, replicas redis.Nodes) (redis.Nodes, error) {
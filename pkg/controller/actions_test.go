@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"reflect"
+	"strings"
 
 	"github.com/IBM/operator-for-redis-cluster/internal/testutil"
 
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"testing"
@@ -319,7 +321,9 @@ func Test_newRedisCluster(t *testing.T) {
 					redis1.ID: &redis1,
 					redis2.ID: &redis2,
 				},
-				KubeNodes: []kapiv1.Node{*node1, *node2},
+				KubeNodes:    []kapiv1.Node{*node1, *node2},
+				TopologyKeys: []string{kapiv1.LabelTopologyZone},
+				PinnedSlots:  map[redis.Slot]string{},
 			},
 			want1:   redis.Nodes{&redis1, &redis2},
 			wantErr: false,
@@ -476,6 +480,51 @@ func TestController_applyConfiguration(t *testing.T) {
 	}
 }
 
+func Test_getNbPrimariesToReplace(t *testing.T) {
+	_, newNode1 := testutil.NewRedisPrimaryNode("new1", "zone1", "pod1", "node1", []string{})
+	_, newNode2 := testutil.NewRedisPrimaryNode("new2", "zone2", "pod2", "node2", []string{})
+	_, newNode3 := testutil.NewRedisPrimaryNode("new3", "zone3", "pod3", "node3", []string{})
+	_, newNode4 := testutil.NewRedisPrimaryNode("new4", "zone1", "pod4", "node1", []string{})
+	_, newNode5 := testutil.NewRedisPrimaryNode("new5", "zone2", "pod5", "node2", []string{})
+	tests := []struct {
+		name          string
+		rollingUpdate *rapi.RollingUpdate
+		newNodes      redis.Nodes
+		want          int32
+	}{
+		{
+			name:     "one shard by default",
+			newNodes: redis.Nodes{&newNode1, &newNode2, &newNode3, &newNode4},
+			want:     1,
+		},
+		{
+			name:          "parallel shards",
+			rollingUpdate: &rapi.RollingUpdate{MaxUnavailableShards: proto.Int32(3)},
+			newNodes:      redis.Nodes{&newNode1, &newNode2, &newNode3, &newNode4, &newNode5},
+			want:          2,
+		},
+		{
+			name:          "zero surge waiting for the pods of a shard",
+			rollingUpdate: &rapi.RollingUpdate{MaxSurge: proto.Int32(0)},
+			newNodes:      redis.Nodes{&newNode1},
+			want:          0,
+		},
+		{
+			name:     "missing surge pods",
+			newNodes: redis.Nodes{&newNode1},
+			want:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{NumberOfPrimaries: proto.Int32(3), ReplicationFactor: proto.Int32(1), RollingUpdate: tt.rollingUpdate}}
+			if got := getNbPrimariesToReplace(cluster, tt.newNodes); got != tt.want {
+				t.Errorf("getNbPrimariesToReplace() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_getOldNodesToRemove(t *testing.T) {
 	redis1 := &redis.Node{ID: "redis1", Role: "replica", PrimaryReferent: "redis2", IP: "10.0.0.1", Pod: testutil.NewPod("pod1", "node1")}
 	redis2 := &redis.Node{ID: "redis2", Role: "primary", IP: "10.0.0.2", Pod: testutil.NewPod("pod2", "node2"), Slots: redis.SlotSlice{1}}
@@ -518,3 +567,31 @@ func Test_getOldNodesToRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestController_isPaused(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+	cluster := &rapi.RedisCluster{}
+
+	if c.isPaused(cluster) || len(recorder.Events) != 0 {
+		t.Errorf("Controller.isPaused() should not pause the cluster nor emit an event")
+	}
+	cluster.Spec.Paused = true
+	if !c.isPaused(cluster) || !hasCondition(&cluster.Status, rapi.RedisClusterPaused) {
+		t.Errorf("Controller.isPaused() should set the Paused condition")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "Paused") {
+		t.Errorf("Controller.isPaused() event = %q, want a Paused event", event)
+	}
+	c.isPaused(cluster)
+	if len(recorder.Events) != 0 {
+		t.Errorf("Controller.isPaused() should emit a single event while the cluster is paused")
+	}
+	cluster.Spec.Paused = false
+	if c.isPaused(cluster) || hasCondition(&cluster.Status, rapi.RedisClusterPaused) {
+		t.Errorf("Controller.isPaused() should reset the Paused condition")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "Resumed") {
+		t.Errorf("Controller.isPaused() event = %q, want a Resumed event", event)
+	}
+}
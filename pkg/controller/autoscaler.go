@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+const (
+	// defaultTargetMemoryUtilization target percentage of used_memory/maxmemory when the autoscaler does not set it
+	defaultTargetMemoryUtilization = 75
+	// defaultAutoscalerCooldown minimum time between two scaling operations when the autoscaler does not set it
+	defaultAutoscalerCooldown = 5 * time.Minute
+)
+
+// autoscalerClusterRequest maps a RedisClusterAutoscaler to the reconciliation of its RedisCluster
+func autoscalerClusterRequest(obj kclient.Object) []reconcile.Request {
+	autoscaler, ok := obj.(*rapi.RedisClusterAutoscaler)
+	if !ok || autoscaler.Spec.ClusterName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: kclient.ObjectKey{Namespace: autoscaler.Namespace, Name: autoscaler.Spec.ClusterName}}}
+}
+
+// getAutoscalers returns the RedisClusterAutoscalers of the cluster, oldest first
+func getAutoscalers(ctx context.Context, kubeClient kclient.Client, cluster *rapi.RedisCluster) ([]rapi.RedisClusterAutoscaler, error) {
+	autoscalerList := &rapi.RedisClusterAutoscalerList{}
+	if err := kubeClient.List(ctx, autoscalerList, kclient.InNamespace(cluster.Namespace)); err != nil {
+		return nil, err
+	}
+	var autoscalers []rapi.RedisClusterAutoscaler
+	for _, autoscaler := range autoscalerList.Items {
+		if autoscaler.Spec.ClusterName == cluster.Name {
+			autoscalers = append(autoscalers, autoscaler)
+		}
+	}
+	sort.Slice(autoscalers, func(i, j int) bool {
+		if !autoscalers[i].CreationTimestamp.Equal(&autoscalers[j].CreationTimestamp) {
+			return autoscalers[i].CreationTimestamp.Before(&autoscalers[j].CreationTimestamp)
+		}
+		return autoscalers[i].Name < autoscalers[j].Name
+	})
+	return autoscalers, nil
+}
+
+// autoscaling is the evaluation of the statistics of the primary nodes of a cluster by an autoscaler
+type autoscaling struct {
+	current           int32
+	desired           int32
+	memoryUtilization *int32
+	opsPerSec         int64
+	// refused reason why the number of primaries must not change, empty when the cluster can be scaled
+	refused string
+}
+
+// evaluateAutoscaling computes the number of primaries of the cluster keeping the memory utilization and the load of
+// the primary nodes under the targets of the autoscaler, within its bounds. A scale down is refused when the data of
+// the primary nodes would not fit in the remaining primaries.
+func evaluateAutoscaling(cluster *rapi.RedisCluster, spec *rapi.RedisClusterAutoscalerSpec) autoscaling {
+	eval := autoscaling{current: *cluster.Spec.NumberOfPrimaries}
+	eval.desired = eval.current
+	if spec.MinPrimaries < 1 || spec.MaxPrimaries < spec.MinPrimaries {
+		eval.refused = fmt.Sprintf("invalid bounds: minPrimaries %d, maxPrimaries %d", spec.MinPrimaries, spec.MaxPrimaries)
+		return eval
+	}
+
+	var primaries []rapi.RedisClusterNode
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role == rapi.RedisClusterNodeRolePrimary {
+			primaries = append(primaries, node)
+		}
+	}
+	if int32(len(primaries)) != eval.current {
+		eval.refused = fmt.Sprintf("waiting for the cluster to have %d primaries, %d running", eval.current, len(primaries))
+		return eval
+	}
+	var usedMemory, maxMemory, minMaxMemory, opsPerSec int64
+	for _, node := range primaries {
+		if node.Stats == nil {
+			eval.refused = fmt.Sprintf("statistics of primary %s are not collected yet", node.PodName)
+			return eval
+		}
+		usedMemory += node.Stats.UsedMemory
+		opsPerSec += node.Stats.OpsPerSec
+		if node.Stats.MaxMemory <= 0 {
+			minMaxMemory = -1
+		} else if minMaxMemory >= 0 {
+			maxMemory += node.Stats.MaxMemory
+			if minMaxMemory == 0 || node.Stats.MaxMemory < minMaxMemory {
+				minMaxMemory = node.Stats.MaxMemory
+			}
+		}
+	}
+	eval.opsPerSec = opsPerSec / int64(eval.current)
+
+	desired := int32(0)
+	if minMaxMemory > 0 {
+		utilization := int32(usedMemory * 100 / maxMemory)
+		eval.memoryUtilization = &utilization
+		target := int32(defaultTargetMemoryUtilization)
+		if spec.TargetMemoryUtilization != nil && *spec.TargetMemoryUtilization > 0 {
+			target = *spec.TargetMemoryUtilization
+		}
+		avgMaxMemory := float64(maxMemory) / float64(eval.current)
+		desired = int32(math.Ceil(float64(usedMemory) * 100 / (float64(target) * avgMaxMemory)))
+	}
+	if spec.TargetOpsPerSec != nil && *spec.TargetOpsPerSec > 0 {
+		if byOps := int32(math.Ceil(float64(opsPerSec) / float64(*spec.TargetOpsPerSec))); byOps > desired {
+			desired = byOps
+		}
+	}
+	if desired == 0 {
+		// no usable metric: only the bounds are enforced
+		desired = eval.current
+	}
+	if desired < spec.MinPrimaries {
+		desired = spec.MinPrimaries
+	}
+	if desired > spec.MaxPrimaries {
+		desired = spec.MaxPrimaries
+	}
+	eval.desired = desired
+
+	if desired < eval.current {
+		if minMaxMemory <= 0 {
+			eval.refused = "scale down refused: maxmemory is not set on the primary nodes"
+		} else if usedMemory > int64(desired)*minMaxMemory {
+			eval.refused = fmt.Sprintf("scale down refused: %d bytes used by the primary nodes do not fit in %d primaries", usedMemory, desired)
+		}
+	}
+	return eval
+}
+
+// reconcileAutoscalers evaluates the oldest RedisClusterAutoscaler of the cluster, and changes the number of primaries
+// of the cluster when the statistics of the primary nodes are out of its targets. It is only called when no operation
+// is in progress on the cluster.
+func (c *Controller) reconcileAutoscalers(ctx context.Context, cluster *rapi.RedisCluster, now metav1.Time) error {
+	autoscalers, err := getAutoscalers(ctx, c.client, cluster)
+	if err != nil || len(autoscalers) == 0 {
+		return err
+	}
+	for i := range autoscalers[1:] {
+		ignored := &autoscalers[i+1]
+		status := ignored.Status.DeepCopy()
+		ignored.Status.Message = fmt.Sprintf("RedisCluster %s is scaled by the RedisClusterAutoscaler %s", cluster.Name, autoscalers[0].Name)
+		if !reflect.DeepEqual(status, &ignored.Status) {
+			if err = c.client.Status().Update(ctx, ignored); err != nil {
+				return err
+			}
+			c.recorder.Event(ignored, v1.EventTypeWarning, "AutoscalerIgnored", ignored.Status.Message)
+		}
+	}
+
+	autoscaler := &autoscalers[0]
+	previousStatus := autoscaler.Status.DeepCopy()
+	eval := evaluateAutoscaling(cluster, &autoscaler.Spec)
+	status := &autoscaler.Status
+	status.CurrentPrimaries = eval.current
+	status.DesiredPrimaries = eval.desired
+	status.MemoryUtilization = eval.memoryUtilization
+	status.OpsPerSec = eval.opsPerSec
+	status.Message = eval.refused
+
+	cooldown := defaultAutoscalerCooldown
+	if autoscaler.Spec.CooldownSeconds != nil {
+		cooldown = time.Duration(*autoscaler.Spec.CooldownSeconds) * time.Second
+	}
+	if eval.refused == "" && eval.desired != eval.current {
+		if status.LastScaleTime != nil && now.Sub(status.LastScaleTime.Time) < cooldown {
+			status.Message = fmt.Sprintf("cooldown until %s", status.LastScaleTime.Add(cooldown).UTC().Format(time.RFC3339))
+		} else {
+			// the copy is patched: the status computed by this reconciliation is kept in the cluster
+			scaled := cluster.DeepCopy()
+			scaled.Spec.NumberOfPrimaries = &eval.desired
+			if err = c.client.Patch(ctx, scaled, kclient.MergeFrom(cluster)); err != nil {
+				return err
+			}
+			glog.Infof("RedisClusterAutoscaler %s/%s scales RedisCluster %s from %d to %d primaries", autoscaler.Namespace, autoscaler.Name, cluster.Name, eval.current, eval.desired)
+			reason := "ScaledUp"
+			if eval.desired < eval.current {
+				reason = "ScaledDown"
+			}
+			c.recorder.Eventf(autoscaler, v1.EventTypeNormal, reason, "RedisCluster %s scaled from %d to %d primaries", cluster.Name, eval.current, eval.desired)
+			status.LastScaleTime = &now
+		}
+	}
+	if reflect.DeepEqual(previousStatus, status) {
+		return nil
+	}
+	return c.client.Status().Update(ctx, autoscaler)
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+const gib = int64(1 << 30)
+
+func newAutoscaledCluster(usedMemory []int64, maxMemory, opsPerSec int64) *rapi.RedisCluster {
+	primaries := int32(len(usedMemory))
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+		Spec:       rapi.RedisClusterSpec{NumberOfPrimaries: &primaries},
+	}
+	for i, used := range usedMemory {
+		cluster.Status.Cluster.Nodes = append(cluster.Status.Cluster.Nodes, rapi.RedisClusterNode{
+			PodName: "pod" + string(rune('a'+i)),
+			Role:    rapi.RedisClusterNodeRolePrimary,
+			Stats:   &rapi.RedisNodeStats{UsedMemory: used, MaxMemory: maxMemory, OpsPerSec: opsPerSec},
+		})
+	}
+	return cluster
+}
+
+func Test_evaluateAutoscaling(t *testing.T) {
+	opsTarget := int64(1000)
+	tests := []struct {
+		name        string
+		cluster     *rapi.RedisCluster
+		spec        rapi.RedisClusterAutoscalerSpec
+		wantDesired int32
+		wantRefused bool
+	}{
+		{
+			name:        "memory pressure",
+			cluster:     newAutoscaledCluster([]int64{gib * 9 / 10, gib * 9 / 10, gib * 9 / 10}, gib, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 3, MaxPrimaries: 10},
+			wantDesired: 4,
+		},
+		{
+			name:        "memory pressure capped by the max bound",
+			cluster:     newAutoscaledCluster([]int64{gib * 9 / 10, gib * 9 / 10, gib * 9 / 10}, gib, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 3, MaxPrimaries: 3},
+			wantDesired: 3,
+		},
+		{
+			name:        "load above the target",
+			cluster:     newAutoscaledCluster([]int64{gib / 10, gib / 10, gib / 10}, gib, 1500),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 3, MaxPrimaries: 10, TargetOpsPerSec: &opsTarget},
+			wantDesired: 5,
+		},
+		{
+			name:        "low usage scales down to the min bound",
+			cluster:     newAutoscaledCluster([]int64{gib / 10, gib / 10, gib / 10, gib / 10, gib / 10, gib / 10}, gib, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 3, MaxPrimaries: 10},
+			wantDesired: 3,
+		},
+		{
+			name:        "scale down refused when the data does not fit",
+			cluster:     newAutoscaledCluster([]int64{gib * 7 / 10, gib * 7 / 10, gib * 7 / 10, gib * 7 / 10}, gib, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 2, MaxPrimaries: 2},
+			wantDesired: 2,
+			wantRefused: true,
+		},
+		{
+			name:        "scale down refused without maxmemory",
+			cluster:     newAutoscaledCluster([]int64{gib, gib, gib, gib}, 0, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 3, MaxPrimaries: 3},
+			wantDesired: 3,
+			wantRefused: true,
+		},
+		{
+			name:        "invalid bounds",
+			cluster:     newAutoscaledCluster([]int64{gib, gib, gib}, gib, 0),
+			spec:        rapi.RedisClusterAutoscalerSpec{MinPrimaries: 5, MaxPrimaries: 4},
+			wantDesired: 3,
+			wantRefused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval := evaluateAutoscaling(tt.cluster, &tt.spec)
+			if eval.desired != tt.wantDesired {
+				t.Errorf("evaluateAutoscaling() desired = %d, want %d", eval.desired, tt.wantDesired)
+			}
+			if (eval.refused != "") != tt.wantRefused {
+				t.Errorf("evaluateAutoscaling() refused = %q, want refused %v", eval.refused, tt.wantRefused)
+			}
+		})
+	}
+}
+
+func TestController_reconcileAutoscalers(t *testing.T) {
+	cluster := newAutoscaledCluster([]int64{gib * 9 / 10, gib * 9 / 10, gib * 9 / 10}, gib, 0)
+	autoscaler := &rapi.RedisClusterAutoscaler{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "autoscaler"},
+		Spec:       rapi.RedisClusterAutoscalerSpec{ClusterName: "cluster", MinPrimaries: 3, MaxPrimaries: 10},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy(), autoscaler).Build()
+	c := &Controller{client: fakeClient, recorder: record.NewFakeRecorder(10)}
+	ctx := context.Background()
+	now := kmetav1.Now()
+
+	if err := c.reconcileAutoscalers(ctx, cluster, now); err != nil {
+		t.Fatalf("reconcileAutoscalers() error = %v", err)
+	}
+	scaled := &rapi.RedisCluster{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, scaled); err != nil {
+		t.Fatalf("unable to get the cluster: %v", err)
+	}
+	if *scaled.Spec.NumberOfPrimaries != 4 {
+		t.Errorf("numberOfPrimaries = %d, want 4", *scaled.Spec.NumberOfPrimaries)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "autoscaler"}, autoscaler); err != nil {
+		t.Fatalf("unable to get the autoscaler: %v", err)
+	}
+	if autoscaler.Status.LastScaleTime == nil || autoscaler.Status.DesiredPrimaries != 4 || *autoscaler.Status.MemoryUtilization != 89 {
+		t.Errorf("autoscaler status = %+v, want a scale to 4 primaries at 89%% memory", autoscaler.Status)
+	}
+
+	// the next scale waits for the cooldown
+	cluster = newAutoscaledCluster([]int64{gib * 9 / 10, gib * 9 / 10, gib * 9 / 10, gib * 9 / 10}, gib, 0)
+	if err := c.reconcileAutoscalers(ctx, cluster, kmetav1.NewTime(now.Add(time.Minute))); err != nil {
+		t.Fatalf("reconcileAutoscalers() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, scaled); err != nil {
+		t.Fatalf("unable to get the cluster: %v", err)
+	}
+	if *scaled.Spec.NumberOfPrimaries != 4 {
+		t.Errorf("numberOfPrimaries = %d, want 4 during the cooldown", *scaled.Spec.NumberOfPrimaries)
+	}
+}
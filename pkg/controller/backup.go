@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/robfig/cron/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/backup"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+const (
+	backupPollDelay = time.Second * 2
+	backupWaitDelay = time.Second * 10
+)
+
+// BackupController reconciles the RedisClusterBackup resources
+type BackupController struct {
+	mgr    manager.Manager
+	client kclient.Client
+
+	podControl pod.RedisClusterControlInterface
+	copier     backup.PodFileCopier
+
+	recorder record.EventRecorder
+
+	config *Config
+}
+
+// NewBackupController builds and returns a new backup controller instance
+func NewBackupController(cfg *Config, mgr manager.Manager, kubeClient kclient.Client, recorder record.EventRecorder, copier backup.PodFileCopier) *BackupController {
+	return &BackupController{
+		mgr:        mgr,
+		client:     kubeClient,
+		podControl: pod.NewRedisClusterControl(kubeClient, recorder),
+		copier:     copier,
+		recorder:   recorder,
+		config:     cfg,
+	}
+}
+
+// SetupRedisClusterBackupController registers the backup controller in the manager
+func SetupRedisClusterBackupController(mgr ctrl.Manager, backupController *BackupController) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("redisclusterbackup").
+		For(&rapi.RedisClusterBackup{}).
+		Owns(&rapi.RedisClusterBackup{}).
+		Complete(backupController)
+}
+
+// Reconcile runs a backup step by step, or creates the backups of a schedule
+func (c *BackupController) Reconcile(ctx context.Context, namespacedName ctrl.Request) (ctrl.Result, error) {
+	glog.V(2).Infof("Reconcile() backup key:%s", namespacedName)
+	result := ctrl.Result{}
+	redisClusterBackup := &rapi.RedisClusterBackup{}
+	if err := c.client.Get(ctx, namespacedName.NamespacedName, redisClusterBackup); err != nil {
+		if errors.IsNotFound(err) {
+			glog.Infof("RedisClusterBackup %s not found. Might be deleted.", namespacedName)
+			return result, nil
+		}
+		glog.Errorf("unable to get RedisClusterBackup %s: %v", namespacedName, err)
+		return result, err
+	}
+	if redisClusterBackup.DeletionTimestamp != nil {
+		return result, nil
+	}
+	redisClusterBackup = redisClusterBackup.DeepCopy()
+	if redisClusterBackup.Spec.Schedule != "" {
+		return c.reconcileSchedule(ctx, redisClusterBackup)
+	}
+
+	switch redisClusterBackup.Status.Phase {
+	case rapi.BackupPhaseCompleted, rapi.BackupPhaseFailed:
+		return result, nil
+	}
+	cluster := &rapi.RedisCluster{}
+	clusterName := types.NamespacedName{Namespace: redisClusterBackup.Namespace, Name: redisClusterBackup.Spec.ClusterName}
+	if err := c.client.Get(ctx, clusterName, cluster); err != nil {
+		if errors.IsNotFound(err) {
+			return result, c.failBackup(ctx, redisClusterBackup, fmt.Sprintf("RedisCluster %s not found", clusterName))
+		}
+		return result, err
+	}
+	if redisClusterBackup.Status.Phase == rapi.BackupPhaseRunning {
+		return c.runBackup(ctx, redisClusterBackup, cluster)
+	}
+	return c.startBackup(ctx, redisClusterBackup, cluster)
+}
+
+// startBackup selects the node backed up in each shard once the cluster is OK
+func (c *BackupController) startBackup(ctx context.Context, redisClusterBackup *rapi.RedisClusterBackup, cluster *rapi.RedisCluster) (ctrl.Result, error) {
+	if cluster.Status.Cluster.Status != rapi.ClusterStatusOK {
+		message := fmt.Sprintf("waiting for RedisCluster %s to be %s, current status: %s", cluster.Name, rapi.ClusterStatusOK, cluster.Status.Cluster.Status)
+		if redisClusterBackup.Status.Phase != rapi.BackupPhasePending || redisClusterBackup.Status.Message != message {
+			redisClusterBackup.Status.Phase = rapi.BackupPhasePending
+			redisClusterBackup.Status.Message = message
+			if err := c.updateBackupStatus(ctx, redisClusterBackup); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: backupWaitDelay}, nil
+	}
+	shards := selectBackupShards(cluster.Status.Cluster.Nodes)
+	if len(shards) == 0 {
+		return ctrl.Result{}, c.failBackup(ctx, redisClusterBackup, fmt.Sprintf("RedisCluster %s has no primary node with slots", cluster.Name))
+	}
+	now := metav1.Now()
+	redisClusterBackup.Status.Phase = rapi.BackupPhaseRunning
+	redisClusterBackup.Status.StartTime = &now
+	redisClusterBackup.Status.Message = ""
+	redisClusterBackup.Status.Shards = shards
+	if err := c.updateBackupStatus(ctx, redisClusterBackup); err != nil {
+		return ctrl.Result{}, err
+	}
+	c.recorder.Eventf(redisClusterBackup, v1.EventTypeNormal, "BackupStarted", "Backup of %d shards of RedisCluster %s started", len(shards), cluster.Name)
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// runBackup moves each shard to its next phase, and completes the backup once all the RDB files are copied
+func (c *BackupController) runBackup(ctx context.Context, redisClusterBackup *rapi.RedisClusterBackup, cluster *rapi.RedisCluster) (ctrl.Result, error) {
+	result := ctrl.Result{}
+	storage, err := backup.NewStorage(ctx, c.client, redisClusterBackup.Namespace, redisClusterBackup.Spec.Storage)
+	if err != nil {
+		return result, c.failBackup(ctx, redisClusterBackup, fmt.Sprintf("unable to initialize the backup storage: %v", err))
+	}
+	redisPods, err := c.podControl.GetRedisClusterPods(cluster)
+	if err != nil {
+		return result, err
+	}
+	redisConfig, err := getRedisClusterConfig(c.client, c.config.redis, cluster)
+	if err != nil {
+		return result, err
+	}
+	admin, err := redis.NewRedisAdmin(ctx, redisPods, redisConfig)
+	if err != nil {
+		return result, fmt.Errorf("unable to create the redis.Admin, err:%v", err)
+	}
+	defer admin.Close()
+
+	completed := 0
+	for i := range redisClusterBackup.Status.Shards {
+		shard := &redisClusterBackup.Status.Shards[i]
+		if err = c.backupShard(ctx, admin, storage, redisClusterBackup, cluster, redisPods, i); err != nil {
+			glog.Errorf("backup of shard %d of RedisCluster %s/%s failed: %v", i, cluster.Namespace, cluster.Name, err)
+			shard.Phase = rapi.BackupShardPhaseFailed
+			shard.Message = err.Error()
+		}
+		switch shard.Phase {
+		case rapi.BackupShardPhaseFailed:
+			return result, c.failBackup(ctx, redisClusterBackup, fmt.Sprintf("backup of shard %d failed: %s", i, shard.Message))
+		case rapi.BackupShardPhaseCompleted:
+			completed++
+		}
+	}
+	if completed < len(redisClusterBackup.Status.Shards) {
+		return ctrl.Result{RequeueAfter: backupPollDelay}, c.updateBackupStatus(ctx, redisClusterBackup)
+	}
+
+	manifest := &backup.Manifest{
+		Namespace:    cluster.Namespace,
+		ClusterName:  cluster.Name,
+		CreationTime: time.Now().UTC(),
+	}
+	for _, shard := range redisClusterBackup.Status.Shards {
+		manifest.Shards = append(manifest.Shards, backup.ManifestShard{
+			File:     shard.File,
+			Slots:    shard.Slots,
+			Size:     shard.Size,
+			Checksum: shard.Checksum,
+		})
+	}
+	if err = backup.WriteManifest(ctx, storage, backup.ManifestFile(redisClusterBackup.Namespace, redisClusterBackup.Name), manifest); err != nil {
+		return result, c.failBackup(ctx, redisClusterBackup, fmt.Sprintf("unable to write the backup manifest: %v", err))
+	}
+	now := metav1.Now()
+	redisClusterBackup.Status.Phase = rapi.BackupPhaseCompleted
+	redisClusterBackup.Status.CompletionTime = &now
+	if err = c.updateBackupStatus(ctx, redisClusterBackup); err != nil {
+		return result, err
+	}
+	c.recorder.Eventf(redisClusterBackup, v1.EventTypeNormal, "BackupCompleted", "Backup of RedisCluster %s completed", cluster.Name)
+	return result, nil
+}
+
+// backupShard triggers the background save of the shard node, then copies its RDB file in the storage once saved
+func (c *BackupController) backupShard(ctx context.Context, admin redis.AdminInterface, storage backup.Storage, redisClusterBackup *rapi.RedisClusterBackup, cluster *rapi.RedisCluster, pods []v1.Pod, index int) error {
+	shard := &redisClusterBackup.Status.Shards[index]
+	if shard.Phase == rapi.BackupShardPhaseCompleted || shard.Phase == rapi.BackupShardPhaseFailed {
+		return nil
+	}
+	node := getClusterNode(cluster, shard.NodeID)
+	if node == nil {
+		return fmt.Errorf("node %s is not part of the cluster anymore", shard.NodeID)
+	}
+	addr := net.JoinHostPort(node.IP, node.Port)
+
+	if shard.Phase != rapi.BackupShardPhaseSaving {
+		if node.Role == rapi.RedisClusterNodeRoleReplica {
+			info, err := admin.GetInfo(ctx, addr, "replication")
+			if err != nil {
+				return err
+			}
+			if info["master_link_status"] != "up" {
+				// the replica may be late, the primary is backed up instead
+				primary := getClusterNode(cluster, shard.PrimaryID)
+				if primary == nil {
+					return fmt.Errorf("primary node %s is not part of the cluster anymore", shard.PrimaryID)
+				}
+				glog.Warningf("replica %s is not connected to its primary, backing up primary %s instead", shard.NodeID, shard.PrimaryID)
+				shard.NodeID = primary.ID
+				shard.PodName = primary.PodName
+				addr = net.JoinHostPort(primary.IP, primary.Port)
+			}
+		}
+		// BGSAVE fails while another background save is running, the backup waits for it to complete
+		persistence, err := admin.GetInfo(ctx, addr, "persistence")
+		if err != nil {
+			return err
+		}
+		if persistence["rdb_bgsave_in_progress"] == "1" {
+			glog.V(3).Infof("background save in progress on node %s, waiting for it to complete", addr)
+			return nil
+		}
+		if err := admin.BackgroundSave(ctx, addr); err != nil {
+			return err
+		}
+		shard.Phase = rapi.BackupShardPhaseSaving
+		return nil
+	}
+
+	info, err := admin.GetInfo(ctx, addr, "persistence")
+	if err != nil {
+		return err
+	}
+	saved, err := isBackgroundSaveDone(info, redisClusterBackup.Status.StartTime.Time)
+	if err != nil || !saved {
+		return err
+	}
+	var redisPod *v1.Pod
+	for i := range pods {
+		if pods[i].Name == shard.PodName {
+			redisPod = &pods[i]
+		}
+	}
+	if redisPod == nil {
+		return fmt.Errorf("pod %s not found", shard.PodName)
+	}
+	rdbFile, err := getRDBFile(ctx, admin, addr)
+	if err != nil {
+		return err
+	}
+	file := backup.ShardFileName(redisClusterBackup.Namespace, redisClusterBackup.Name, index)
+	size, checksum, err := c.copyRDBFile(ctx, storage, redisPod, rdbFile, file)
+	if err != nil {
+		return err
+	}
+	shard.File = file
+	shard.Size = size
+	shard.Checksum = checksum
+	shard.Phase = rapi.BackupShardPhaseCompleted
+	return nil
+}
+
+// copyRDBFile streams the RDB file of the pod to the storage, and returns the size and the sha256 checksum of the file
+func (c *BackupController) copyRDBFile(ctx context.Context, storage backup.Storage, redisPod *v1.Pod, rdbFile, file string) (int64, string, error) {
+	// the size of the file selects the part size of the S3 uploads
+	fileSize, err := c.copier.FileSize(ctx, redisPod, pod.RedisNodeContainerName, rdbFile)
+	if err != nil {
+		return 0, "", err
+	}
+	hash := sha256.New()
+	pr, pw := io.Pipe()
+	copyErr := make(chan error, 1)
+	go func() {
+		err := c.copier.CopyFile(ctx, redisPod, pod.RedisNodeContainerName, rdbFile, io.MultiWriter(pw, hash))
+		// the storage reads until EOF, or gets the copy error
+		pw.CloseWithError(err)
+		copyErr <- err
+	}()
+	size, err := storage.Write(ctx, file, pr, fileSize)
+	// unblocks the copy when the storage stops reading before EOF
+	pr.CloseWithError(err)
+	if cerr := <-copyErr; err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reconcileSchedule creates a backup from the schedule on each occurrence, and deletes the finished
+// backups beyond the retention
+func (c *BackupController) reconcileSchedule(ctx context.Context, schedule *rapi.RedisClusterBackup) (ctrl.Result, error) {
+	result := ctrl.Result{}
+	cronSchedule, err := cron.ParseStandard(schedule.Spec.Schedule)
+	if err != nil {
+		return result, c.failBackup(ctx, schedule, fmt.Sprintf("invalid schedule %q: %v", schedule.Spec.Schedule, err))
+	}
+	backupList := &rapi.RedisClusterBackupList{}
+	err = c.client.List(ctx, backupList, kclient.InNamespace(schedule.Namespace), kclient.MatchingLabels{rapi.BackupScheduleLabelKey: schedule.Name})
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	lastScheduleTime := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		lastScheduleTime = schedule.Status.LastScheduleTime.Time
+	}
+	// the schedule itself has no phase, unless its cron expression is invalid
+	statusChanged := schedule.Status.Phase != ""
+	schedule.Status.Phase = ""
+	schedule.Status.Message = ""
+	next := cronSchedule.Next(lastScheduleTime)
+	if !next.After(now) {
+		// missed occurrences are not caught up, and an occurrence is skipped while a backup is running
+		if active := getActiveBackups(backupList.Items); len(active) > 0 {
+			c.recorder.Eventf(schedule, v1.EventTypeWarning, "BackupSkipped", "Backup skipped, backup %s is still running", active[0].Name)
+		} else if err = c.createScheduledBackup(ctx, schedule, now); err != nil {
+			return result, err
+		}
+		schedule.Status.LastScheduleTime = &metav1.Time{Time: now}
+		statusChanged = true
+		next = cronSchedule.Next(now)
+	}
+	if statusChanged {
+		if err = c.updateBackupStatus(ctx, schedule); err != nil {
+			return result, err
+		}
+	}
+
+	var errs []error
+	for _, b := range selectBackupsToPrune(backupList.Items, schedule.Spec.Retention) {
+		if err = c.deleteBackup(ctx, b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	result.RequeueAfter = next.Sub(now)
+	return result, utilerrors.NewAggregate(errs)
+}
+
+func (c *BackupController) createScheduledBackup(ctx context.Context, schedule *rapi.RedisClusterBackup, now time.Time) error {
+	scheduledBackup := &rapi.RedisClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", schedule.Name, now.Unix()),
+			Namespace: schedule.Namespace,
+			Labels:    map[string]string{rapi.BackupScheduleLabelKey: schedule.Name},
+		},
+		Spec: rapi.RedisClusterBackupSpec{
+			ClusterName: schedule.Spec.ClusterName,
+			Storage:     *schedule.Spec.Storage.DeepCopy(),
+		},
+	}
+	if err := controllerutil.SetControllerReference(schedule, scheduledBackup, c.mgr.GetScheme()); err != nil {
+		return err
+	}
+	if err := c.client.Create(ctx, scheduledBackup); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	c.recorder.Eventf(schedule, v1.EventTypeNormal, "BackupCreated", "Created backup %s", scheduledBackup.Name)
+	return nil
+}
+
+// deleteBackup deletes the files of the backup from the storage, then the backup itself
+func (c *BackupController) deleteBackup(ctx context.Context, redisClusterBackup rapi.RedisClusterBackup) error {
+	glog.V(2).Infof("deleting backup %s/%s", redisClusterBackup.Namespace, redisClusterBackup.Name)
+	storage, err := backup.NewStorage(ctx, c.client, redisClusterBackup.Namespace, redisClusterBackup.Spec.Storage)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, shard := range redisClusterBackup.Status.Shards {
+		if shard.File != "" {
+			errs = append(errs, storage.Delete(ctx, shard.File))
+		}
+	}
+	errs = append(errs, storage.Delete(ctx, backup.ManifestFile(redisClusterBackup.Namespace, redisClusterBackup.Name)))
+	if err = utilerrors.NewAggregate(errs); err != nil {
+		return err
+	}
+	if err = c.client.Delete(ctx, &redisClusterBackup); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *BackupController) failBackup(ctx context.Context, redisClusterBackup *rapi.RedisClusterBackup, message string) error {
+	now := metav1.Now()
+	redisClusterBackup.Status.Phase = rapi.BackupPhaseFailed
+	redisClusterBackup.Status.CompletionTime = &now
+	redisClusterBackup.Status.Message = message
+	c.recorder.Event(redisClusterBackup, v1.EventTypeWarning, "BackupFailed", message)
+	return c.updateBackupStatus(ctx, redisClusterBackup)
+}
+
+func (c *BackupController) updateBackupStatus(ctx context.Context, redisClusterBackup *rapi.RedisClusterBackup) error {
+	if err := c.client.Status().Update(ctx, redisClusterBackup); err != nil {
+		glog.Errorf("failed to update RedisClusterBackup %s/%s: %v", redisClusterBackup.Namespace, redisClusterBackup.Name, err)
+		return err
+	}
+	glog.V(6).Infof("RedisClusterBackup %s/%s updated", redisClusterBackup.Namespace, redisClusterBackup.Name)
+	return nil
+}
+
+// selectBackupShards returns one shard per primary node with slots, ordered by slot. The RDB file of a shard
+// is taken from a replica when the primary has one, to keep the fork away from the primary
+func selectBackupShards(nodes []rapi.RedisClusterNode) []rapi.BackupShardStatus {
+	var shards []rapi.BackupShardStatus
+	for _, node := range nodes {
+		if node.Role != rapi.RedisClusterNodeRolePrimary || len(node.Slots) == 0 {
+			continue
+		}
+		shard := rapi.BackupShardStatus{
+			PrimaryID: node.ID,
+			NodeID:    node.ID,
+			PodName:   node.PodName,
+			Slots:     node.Slots,
+			Phase:     rapi.BackupShardPhasePending,
+		}
+		var replicas []rapi.RedisClusterNode
+		for _, replica := range nodes {
+			if replica.Role == rapi.RedisClusterNodeRoleReplica && replica.PrimaryRef == node.ID {
+				replicas = append(replicas, replica)
+			}
+		}
+		if len(replicas) > 0 {
+			sort.Slice(replicas, func(i, j int) bool { return replicas[i].ID < replicas[j].ID })
+			shard.NodeID = replicas[0].ID
+			shard.PodName = replicas[0].PodName
+		}
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(i, j int) bool { return firstSlot(shards[i].Slots) < firstSlot(shards[j].Slots) })
+	return shards
+}
+
+func firstSlot(slots []string) redis.Slot {
+	var min redis.Slot = redis.HashMaxSlots + 1
+	for _, slotRange := range slots {
+		if slot, err := redis.DecodeSlot(strings.SplitN(slotRange, "-", 2)[0]); err == nil && slot < min {
+			min = slot
+		}
+	}
+	return min
+}
+
+// isBackgroundSaveDone returns true once the node completed a background save started after the given time
+func isBackgroundSaveDone(info map[string]string, since time.Time) (bool, error) {
+	if info["rdb_bgsave_in_progress"] == "1" || info["aof_rewrite_in_progress"] == "1" {
+		return false, nil
+	}
+	if status := info["rdb_last_bgsave_status"]; status != "ok" {
+		return false, fmt.Errorf("background save failed, rdb_last_bgsave_status: %s", status)
+	}
+	lastSave, err := strconv.ParseInt(info["rdb_last_save_time"], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse rdb_last_save_time: %v", err)
+	}
+	// a save scheduled after an AOF rewrite starts on the next server cron
+	return lastSave >= since.Unix(), nil
+}
+
+func getRDBFile(ctx context.Context, admin redis.AdminInterface, addr string) (string, error) {
+	dir, err := admin.GetNodeConfig(ctx, addr, "dir")
+	if err != nil {
+		return "", err
+	}
+	dbFilename, err := admin.GetNodeConfig(ctx, addr, "dbfilename")
+	if err != nil {
+		return "", err
+	}
+	if dir["dir"] == "" || dbFilename["dbfilename"] == "" {
+		return "", fmt.Errorf("unable to get the RDB file path of node %s", addr)
+	}
+	return path.Join(dir["dir"], dbFilename["dbfilename"]), nil
+}
+
+func getClusterNode(cluster *rapi.RedisCluster, id string) *rapi.RedisClusterNode {
+	for i, node := range cluster.Status.Cluster.Nodes {
+		if node.ID == id {
+			return &cluster.Status.Cluster.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// getActiveBackups returns the backups not completed nor failed
+func getActiveBackups(backups []rapi.RedisClusterBackup) []rapi.RedisClusterBackup {
+	var active []rapi.RedisClusterBackup
+	for _, b := range backups {
+		if b.Status.Phase != rapi.BackupPhaseCompleted && b.Status.Phase != rapi.BackupPhaseFailed {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// selectBackupsToPrune returns the finished backups beyond the retention, oldest first
+func selectBackupsToPrune(backups []rapi.RedisClusterBackup, retention *int32) []rapi.RedisClusterBackup {
+	if retention == nil {
+		return nil
+	}
+	var finished []rapi.RedisClusterBackup
+	for _, b := range backups {
+		if b.Status.Phase == rapi.BackupPhaseCompleted || b.Status.Phase == rapi.BackupPhaseFailed {
+			finished = append(finished, b)
+		}
+	}
+	if len(finished) <= int(*retention) {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreationTimestamp.Before(&finished[j].CreationTimestamp)
+	})
+	return finished[:len(finished)-int(*retention)]
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	kapiv1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/internal/testutil"
+	"github.com/IBM/operator-for-redis-cluster/pkg/backup"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+// fakeCopier writes the content in the copied file, and fails after it when err is set
+type fakeCopier struct {
+	content []byte
+	err     error
+}
+
+func (f *fakeCopier) FileSize(ctx context.Context, pod *kapiv1.Pod, container, file string) (int64, error) {
+	return int64(len(f.content)), nil
+}
+
+func (f *fakeCopier) CopyFile(ctx context.Context, pod *kapiv1.Pod, container, file string, w io.Writer) error {
+	if _, err := w.Write(f.content); err != nil {
+		return err
+	}
+	return f.err
+}
+
+func TestBackupController_copyRDBFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "redis-backup")
+	if err != nil {
+		t.Fatalf("unable to create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	storage := backup.NewLocalStorage(dir)
+	ctx := context.Background()
+	content := []byte("REDIS0009")
+	sum := sha256.Sum256(content)
+
+	c := &BackupController{copier: &fakeCopier{content: content}}
+	size, checksum, err := c.copyRDBFile(ctx, storage, &kapiv1.Pod{}, "/redis-data/dump.rdb", "ns/backup/shard-0.rdb")
+	if err != nil || size != int64(len(content)) || checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("copyRDBFile() = %d, %s, %v, want %d bytes and checksum %s", size, checksum, err, len(content), hex.EncodeToString(sum[:]))
+	}
+
+	c.copier = &fakeCopier{content: content, err: errors.New("exec failed")}
+	if _, _, err = c.copyRDBFile(ctx, storage, &kapiv1.Pod{}, "/redis-data/dump.rdb", "ns/backup/shard-1.rdb"); err == nil {
+		t.Errorf("copyRDBFile() expected the copy error")
+	}
+	if _, err = storage.Read(ctx, "ns/backup/shard-1.rdb"); !os.IsNotExist(err) {
+		t.Errorf("copyRDBFile() expected no file written when the copy fails, got %v", err)
+	}
+}
+
+func TestBackupController_backupShardWaitsForBackgroundSave(t *testing.T) {
+	primary, _ := testutil.NewRedisPrimaryNode("primary1", "zone1", "pod1", "node1", []string{"0-16383"})
+	cluster := &rapi.RedisCluster{}
+	cluster.Status.Cluster.Nodes = []rapi.RedisClusterNode{primary}
+	redisClusterBackup := &rapi.RedisClusterBackup{}
+	redisClusterBackup.Status.Shards = []rapi.BackupShardStatus{{PrimaryID: "primary1", NodeID: "primary1", PodName: "pod1", Phase: rapi.BackupShardPhasePending}}
+	addr := net.JoinHostPort(primary.IP, primary.Port)
+	fakeAdmin := admin.NewFakeAdmin()
+	fakeAdmin.GetInfoRet[addr] = map[string]string{"rdb_bgsave_in_progress": "1"}
+	ctx := context.Background()
+
+	c := &BackupController{}
+	if err := c.backupShard(ctx, fakeAdmin, nil, redisClusterBackup, cluster, nil, 0); err != nil {
+		t.Fatalf("backupShard() unexpected error: %v", err)
+	}
+	if phase := redisClusterBackup.Status.Shards[0].Phase; phase != rapi.BackupShardPhasePending {
+		t.Errorf("backupShard() phase = %s, want %s while another background save is running", phase, rapi.BackupShardPhasePending)
+	}
+
+	fakeAdmin.GetInfoRet[addr] = map[string]string{"rdb_bgsave_in_progress": "0"}
+	if err := c.backupShard(ctx, fakeAdmin, nil, redisClusterBackup, cluster, nil, 0); err != nil {
+		t.Fatalf("backupShard() unexpected error: %v", err)
+	}
+	if phase := redisClusterBackup.Status.Shards[0].Phase; phase != rapi.BackupShardPhaseSaving {
+		t.Errorf("backupShard() phase = %s, want %s", phase, rapi.BackupShardPhaseSaving)
+	}
+}
+
+func Test_selectBackupShards(t *testing.T) {
+	primary1, _ := testutil.NewRedisPrimaryNode("primary1", "zone1", "pod1", "node1", []string{"8192-16383"})
+	primary2, _ := testutil.NewRedisPrimaryNode("primary2", "zone2", "pod2", "node2", []string{"0-8191"})
+	primary3, _ := testutil.NewRedisPrimaryNode("primary3", "zone3", "pod3", "node3", []string{})
+	replica2, _ := testutil.NewRedisReplicaNode("replica2", "zone1", "primary1", "pod4", "node1")
+	replica1, _ := testutil.NewRedisReplicaNode("replica1", "zone3", "primary1", "pod5", "node3")
+	replica2.PodName = "pod4"
+	replica1.PodName = "pod5"
+	primary2.PodName = "pod2"
+
+	shards := selectBackupShards([]rapi.RedisClusterNode{primary1, replica2, primary2, primary3, replica1})
+	expected := []rapi.BackupShardStatus{
+		{PrimaryID: "primary2", NodeID: "primary2", PodName: "pod2", Slots: []string{"0-8191"}, Phase: rapi.BackupShardPhasePending},
+		{PrimaryID: "primary1", NodeID: "replica1", PodName: "pod5", Slots: []string{"8192-16383"}, Phase: rapi.BackupShardPhasePending},
+	}
+	if !reflect.DeepEqual(shards, expected) {
+		t.Errorf("selectBackupShards() = %v, want %v", shards, expected)
+	}
+}
+
+func Test_isBackgroundSaveDone(t *testing.T) {
+	since := time.Now()
+	before := strconv.FormatInt(since.Add(-time.Minute).Unix(), 10)
+	after := strconv.FormatInt(since.Add(time.Second).Unix(), 10)
+	tests := []struct {
+		name    string
+		info    map[string]string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "save in progress",
+			info: map[string]string{"rdb_bgsave_in_progress": "1", "rdb_last_bgsave_status": "ok", "rdb_last_save_time": before},
+			want: false,
+		},
+		{
+			name: "save scheduled after an AOF rewrite",
+			info: map[string]string{"rdb_bgsave_in_progress": "0", "aof_rewrite_in_progress": "1", "rdb_last_bgsave_status": "ok", "rdb_last_save_time": before},
+			want: false,
+		},
+		{
+			name: "previous save",
+			info: map[string]string{"rdb_bgsave_in_progress": "0", "rdb_last_bgsave_status": "ok", "rdb_last_save_time": before},
+			want: false,
+		},
+		{
+			name: "save done",
+			info: map[string]string{"rdb_bgsave_in_progress": "0", "rdb_last_bgsave_status": "ok", "rdb_last_save_time": after},
+			want: true,
+		},
+		{
+			name:    "save failed",
+			info:    map[string]string{"rdb_bgsave_in_progress": "0", "rdb_last_bgsave_status": "err", "rdb_last_save_time": before},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isBackgroundSaveDone(tt.info, since)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("isBackgroundSaveDone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isBackgroundSaveDone() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_selectBackupsToPrune(t *testing.T) {
+	now := time.Now()
+	newBackup := func(name string, age time.Duration, phase rapi.BackupPhase) rapi.RedisClusterBackup {
+		return rapi.RedisClusterBackup{
+			ObjectMeta: kmetav1.ObjectMeta{Name: name, CreationTimestamp: kmetav1.NewTime(now.Add(-age))},
+			Status:     rapi.RedisClusterBackupStatus{Phase: phase},
+		}
+	}
+	backups := []rapi.RedisClusterBackup{
+		newBackup("b2", 2*time.Hour, rapi.BackupPhaseFailed),
+		newBackup("b4", 0, rapi.BackupPhaseRunning),
+		newBackup("b1", 3*time.Hour, rapi.BackupPhaseCompleted),
+		newBackup("b3", time.Hour, rapi.BackupPhaseCompleted),
+	}
+	tests := []struct {
+		name      string
+		retention *int32
+		want      []string
+	}{
+		{name: "no retention", retention: nil, want: nil},
+		{name: "under retention", retention: proto.Int32(3), want: nil},
+		{name: "oldest finished backups", retention: proto.Int32(1), want: []string{"b1", "b2"}},
+		{name: "running backup is kept", retention: proto.Int32(0), want: []string{"b1", "b2", "b3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range selectBackupsToPrune(backups, tt.retention) {
+				got = append(got, b.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectBackupsToPrune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -78,6 +79,23 @@ func compareStatus(old, new *rapi.RedisClusterStatus) bool {
 		}
 	}
 
+	if compareRestoreStatus(old.Restore, new.Restore) {
+		return true
+	}
+	if compareProgressStatus(old.Progress, new.Progress) {
+		return true
+	}
+	if compareConfigStatus(old.Config, new.Config) {
+		return true
+	}
+	if compareRolloutStatus(old.Rollout, new.Rollout) {
+		return true
+	}
+	if old.ObservedGeneration != new.ObservedGeneration {
+		glog.Infof("compare status.ObservedGeneration: %d - %d", old.ObservedGeneration, new.ObservedGeneration)
+		return true
+	}
+
 	if len(old.Conditions) != len(new.Conditions) {
 		return true
 	}
@@ -127,6 +145,10 @@ func compareNodes(nodeA, nodeB *rapi.RedisClusterNode) bool {
 	if compareStringValue("Node.Role", string(nodeA.Role), string(nodeB.Role)) {
 		return true
 	}
+	if (nodeA.Stats == nil) != (nodeB.Stats == nil) || nodeA.Stats != nil && !nodeA.Stats.LastUpdateTime.Equal(&nodeB.Stats.LastUpdateTime) {
+		glog.V(4).Infof("compare Node.Stats of node %s", nodeA.ID)
+		return true
+	}
 
 	sizeSlotsA := 0
 	sizeSlotsB := 0
@@ -165,6 +187,83 @@ func compareConditions(condA, condB *rapi.RedisClusterCondition) bool {
 	return false
 }
 
+func compareRestoreStatus(old, new *rapi.RestoreStatus) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	if compareStringValue("Restore.Phase", string(old.Phase), string(new.Phase)) {
+		return true
+	}
+	if compareStringValue("Restore.Message", old.Message, new.Message) {
+		return true
+	}
+	if compareInts("Restore.Shards", old.Shards, new.Shards) {
+		return true
+	}
+	if compareInts("Restore.ShardsRestored", old.ShardsRestored, new.ShardsRestored) {
+		return true
+	}
+	if compareInts("Restore.Retries", old.Retries, new.Retries) {
+		return true
+	}
+	return old.KeysRestored != new.KeysRestored || old.ShardKeysRead != new.ShardKeysRead
+}
+
+func compareProgressStatus(old, new *rapi.ProgressStatus) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	if compareInts("Progress.SlotsPlanned", old.SlotsPlanned, new.SlotsPlanned) {
+		return true
+	}
+	if compareInts("Progress.SlotsMigrated", old.SlotsMigrated, new.SlotsMigrated) {
+		return true
+	}
+	if compareInts("Progress.SlotsFailed", old.SlotsFailed, new.SlotsFailed) {
+		return true
+	}
+	if old.KeysMoved != new.KeysMoved {
+		return true
+	}
+	return !equalTimes(old.StartTime, new.StartTime) || !equalTimes(old.EstimatedCompletionTime, new.EstimatedCompletionTime) || !equalTimes(old.CompletionTime, new.CompletionTime)
+}
+
+func compareConfigStatus(old, new *rapi.ConfigStatus) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	if old.RestartHash != new.RestartHash {
+		glog.Infof("compare status.Config.RestartHash: %s - %s", old.RestartHash, new.RestartHash)
+		return true
+	}
+	if old.BaselineRestartHash != new.BaselineRestartHash {
+		glog.Infof("compare status.Config.BaselineRestartHash: %s - %s", old.BaselineRestartHash, new.BaselineRestartHash)
+		return true
+	}
+	return !reflect.DeepEqual(old.PendingKeys, new.PendingKeys) || !reflect.DeepEqual(old.FailedKeys, new.FailedKeys) || !reflect.DeepEqual(old.DriftedNodes, new.DriftedNodes)
+}
+
+func compareRolloutStatus(old, new *rapi.RolloutStatus) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	if old.PodTemplateHash != new.PodTemplateHash || old.Phase != new.Phase || old.Message != new.Message {
+		glog.Infof("compare status.Rollout.Phase: %s - %s", old.Phase, new.Phase)
+		return true
+	}
+	if compareInts("Rollout.TotalShards", old.TotalShards, new.TotalShards) || compareInts("Rollout.UpdatedShards", old.UpdatedShards, new.UpdatedShards) || compareInts("Rollout.PromotedShards", old.PromotedShards, new.PromotedShards) {
+		return true
+	}
+	return !equalTimes(old.PauseTime, new.PauseTime) || !reflect.DeepEqual(old.HealthCounters, new.HealthCounters)
+}
+
+func equalTimes(old, new *metav1.Time) bool {
+	if old == nil || new == nil {
+		return old == new
+	}
+	return old.Equal(new)
+}
+
 func compareIntValue(name string, old, new *int32) bool {
 	if old == nil && new == nil {
 		return true
@@ -212,6 +311,11 @@ func needClusterOperation(cluster *rapi.RedisCluster) bool {
 		return true
 	}
 
+	if needRestore(cluster) {
+		glog.V(6).Info("needClusterOperation---needRestore")
+		return true
+	}
+
 	if needConditionUpdate(cluster) {
 		glog.V(6).Info("needClusterOperation---needConditionUpdate")
 		return true
@@ -238,7 +342,7 @@ func needClusterOperation(cluster *rapi.RedisCluster) bool {
 func needConditionUpdate(cluster *rapi.RedisCluster) bool {
 	for _, cond := range cluster.Status.Conditions {
 		if cond.Status == kapi.ConditionTrue {
-			if cond.Type == rapi.RedisClusterRollingUpdate || cond.Type == rapi.RedisClusterRebalancing || cond.Type == rapi.RedisClusterScaling {
+			if cond.Type == rapi.RedisClusterRollingUpdate || cond.Type == rapi.RedisClusterRebalancing || cond.Type == rapi.RedisClusterScaling || cond.Type == rapi.RedisClusterRestoring {
 				return true
 			}
 		}
@@ -247,11 +351,11 @@ func needConditionUpdate(cluster *rapi.RedisCluster) bool {
 }
 
 func needRollingUpdate(cluster *rapi.RedisCluster) bool {
-	return !comparePodsWithPodTemplate(cluster)
+	return !comparePodsWithPodTemplate(cluster) && !rolloutHeld(cluster)
 }
 
 func comparePodsWithPodTemplate(cluster *rapi.RedisCluster) bool {
-	clusterPodSpecHash, _ := podctrl.GenerateMD5Spec(&cluster.Spec.PodTemplate.Spec)
+	clusterPodSpecHash, _ := podctrl.GeneratePodHash(cluster)
 	for _, node := range cluster.Status.Cluster.Nodes {
 		if node.Pod == nil {
 			continue
@@ -401,10 +505,8 @@ func checkZoneBalance(cluster *rapi.RedisCluster) bool {
 	return ok
 }
 
-// checkServerConfig checks if the running redis server config matches
-// the server config stored in the redis cluster config map
-// Returns a map of the changed configuration, if any
-func checkServerConfig(ctx context.Context, admin redis.AdminInterface, redisClusterConfigMap *kapi.ConfigMap) (map[string]string, error) {
+// parseServerConfig returns the redis server config stored in the redis cluster config map
+func parseServerConfig(redisClusterConfigMap *kapi.ConfigMap) (map[string]string, error) {
 	var values string
 	if val, ok := redisClusterConfigMap.Data["redis.yaml"]; ok {
 		values = val
@@ -413,11 +515,19 @@ func checkServerConfig(ctx context.Context, admin redis.AdminInterface, redisClu
 	if err := yaml.Unmarshal([]byte(values), &clusterConfig); err != nil {
 		return nil, err
 	}
-	serverConfig, err := admin.GetConfig(ctx, "*")
-	if err != nil {
-		return nil, err
-	}
-	return compareConfig(serverConfig, clusterConfig), nil
+	return clusterConfig, nil
+}
+
+// checkServerConfig checks if the running redis server config of each node matches
+// the server config stored in the redis cluster config map
+// Returns the map of the changed configuration by node address, empty for the nodes that match
+func checkServerConfig(ctx context.Context, admin redis.AdminInterface, clusterConfig map[string]string) (map[string]map[string]string, error) {
+	serverConfigs, err := admin.GetClusterConfig(ctx, "*")
+	nodeChanges := make(map[string]map[string]string, len(serverConfigs))
+	for addr, serverConfig := range serverConfigs {
+		nodeChanges[addr] = compareConfig(serverConfig, clusterConfig)
+	}
+	return nodeChanges, err
 }
 
 func checkReplicasOfReplica(cluster *rapi.RedisCluster) (map[string][]*rapi.RedisClusterNode, bool) {
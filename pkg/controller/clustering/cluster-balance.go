@@ -0,0 +1,273 @@
+package clustering
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+const (
+	// memorySampleSize number of keys of a slot whose memory usage is measured
+	memorySampleSize = 5
+	// imbalanceThresholdPercent load of a primary above the average load, in percent, from which its slots are moved
+	// to the other primaries
+	imbalanceThresholdPercent = 10
+	// slotWeightsTTL duration during which the weights of the slots of a cluster are reused
+	slotWeightsTTL = 5 * time.Minute
+)
+
+// slotWeightsCache keeps the weights of the slots of each cluster, since weighting the slots sends at least one
+// command per slot to the primaries
+type slotWeightsCache struct {
+	mutex   sync.Mutex
+	entries map[string]slotWeightsEntry
+}
+
+type slotWeightsEntry struct {
+	balanceBy rapi.BalanceBy
+	weights   map[redis.Slot]int64
+	time      time.Time
+}
+
+var slotWeights = &slotWeightsCache{entries: make(map[string]slotWeightsEntry)}
+
+// get returns the weights of the slots of the cluster, they are measured again once slotWeightsTTL has elapsed.
+// The weights of a slot do not depend on the primary holding it, so they remain valid after a migration.
+func (c *slotWeightsCache) get(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, primaries redis.Nodes, balanceBy rapi.BalanceBy, now time.Time) (map[redis.Slot]int64, error) {
+	if balanceBy != rapi.BalanceByKeys && balanceBy != rapi.BalanceByMemory {
+		return nil, nil
+	}
+	key := cluster.Namespace + "/" + cluster.Name
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+	if ok && entry.balanceBy == balanceBy && now.Sub(entry.time) < slotWeightsTTL {
+		return entry.weights, nil
+	}
+	weights, err := GetSlotWeights(ctx, admin, primaries, balanceBy)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for k, e := range c.entries {
+		if now.Sub(e.time) >= slotWeightsTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = slotWeightsEntry{balanceBy: balanceBy, weights: weights, time: now}
+	return weights, nil
+}
+
+// GetSlotWeights returns the load of each slot of the primaries: its number of keys, or the memory usage of its keys
+// extrapolated from a sample. The primaries are queried in parallel. It returns nil when the primaries are balanced
+// by number of slots.
+func GetSlotWeights(ctx context.Context, admin redis.AdminInterface, primaries redis.Nodes, balanceBy rapi.BalanceBy) (map[redis.Slot]int64, error) {
+	if balanceBy != rapi.BalanceByKeys && balanceBy != rapi.BalanceByMemory {
+		return nil, nil
+	}
+	type primaryWeights struct {
+		weights map[redis.Slot]int64
+		err     error
+	}
+	resps := make(chan primaryWeights, len(primaries))
+	for _, primary := range primaries {
+		primary := primary
+		go func() {
+			weights, err := getPrimarySlotWeights(ctx, admin, primary, balanceBy)
+			resps <- primaryWeights{weights: weights, err: err}
+		}()
+	}
+	weights := make(map[redis.Slot]int64)
+	var err error
+	for range primaries {
+		r := <-resps
+		if r.err != nil {
+			err = r.err
+			continue
+		}
+		for slot, weight := range r.weights {
+			weights[slot] = weight
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+func getPrimarySlotWeights(ctx context.Context, admin redis.AdminInterface, primary *redis.Node, balanceBy rapi.BalanceBy) (map[redis.Slot]int64, error) {
+	weights := make(map[redis.Slot]int64)
+	addr := primary.IPPort()
+	for _, slot := range primary.Slots {
+		nbKeys, err := admin.CountKeysInSlot(ctx, addr, slot)
+		if err != nil {
+			return nil, err
+		}
+		if nbKeys == 0 || balanceBy == rapi.BalanceByKeys {
+			weights[slot] = nbKeys
+			continue
+		}
+		keys, err := admin.GetKeysInSlot(ctx, addr, slot, strconv.Itoa(memorySampleSize), true)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		var sampleUsage int64
+		for _, key := range keys {
+			usage, err := admin.GetMemoryUsage(ctx, addr, key)
+			if err != nil {
+				return nil, err
+			}
+			sampleUsage += usage
+		}
+		weights[slot] = sampleUsage * nbKeys / int64(len(keys))
+	}
+	return weights, nil
+}
+
+// NeedLoadRebalance returns true when the load of a primary exceeds the average load of the primaries by more than
+// imbalanceThresholdPercent, with the keys or memory balanceBy of the cluster. DispatchSlotsToNewPrimaries then moves
+// the heaviest slots of the overloaded primaries, even though the number of primaries does not change.
+func NeedLoadRebalance(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, primaries redis.Nodes) (bool, error) {
+	if cluster.Spec.Scaling == nil || len(primaries) == 0 {
+		return false, nil
+	}
+	weights, err := slotWeights.get(ctx, admin, cluster, primaries, cluster.Spec.Scaling.BalanceBy, time.Now())
+	if err != nil || weights == nil {
+		return false, err
+	}
+	weight := func(slot redis.Slot) int64 {
+		return weights[slot] + 1
+	}
+	loads := primaryLoads(primaries, weight)
+	var total int64
+	for _, load := range loads {
+		total += load
+	}
+	target := total / int64(len(primaries))
+	return imbalanced(maxLoadExcess(loads, target), target), nil
+}
+
+// primaryLoads returns the sum of the weights of the slots of each primary
+func primaryLoads(primaries redis.Nodes, weight func(redis.Slot) int64) []int64 {
+	loads := make([]int64, len(primaries))
+	for i, node := range primaries {
+		for _, slot := range node.Slots {
+			loads[i] += weight(slot)
+		}
+	}
+	return loads
+}
+
+// maxLoadExcess returns the largest load of a primary above the target load
+func maxLoadExcess(loads []int64, target int64) int64 {
+	var maxExcess int64
+	for _, load := range loads {
+		if excess := load - target; excess > maxExcess {
+			maxExcess = excess
+		}
+	}
+	return maxExcess
+}
+
+func imbalanced(maxExcess, target int64) bool {
+	if maxExcess*100 > target*imbalanceThresholdPercent {
+		glog.Infof("the load of a primary exceeds the target load %d by %d, above the %d%% threshold", target, maxExcess, imbalanceThresholdPercent)
+		return true
+	}
+	return false
+}
+
+// buildWeightedSlotsByNode assigns the slots so that each new primary holds the same load, according to the weight of
+// each slot. The slots of the removed primaries and the lost slots are dispatched, along with the heaviest slots of the
+// overloaded primaries when a primary exceeds the target load by more than imbalanceThresholdPercent. Each slot also
+// weighs one unit, so that the empty slots are spread evenly.
+func buildWeightedSlotsByNode(newPrimaryNodes, oldPrimaryNodes redis.Nodes, nbSlots int, weights map[redis.Slot]int64) map[string]redis.SlotSlice {
+	slotToAddByNode := make(map[string]redis.SlotSlice)
+	if len(newPrimaryNodes) == 0 {
+		return slotToAddByNode
+	}
+	weight := func(slot redis.Slot) int64 {
+		return weights[slot] + 1
+	}
+	var total int64
+	for slot := 0; slot < nbSlots; slot++ {
+		total += weight(redis.Slot(slot))
+	}
+	target := total / int64(len(newPrimaryNodes))
+
+	var slotsToDispatch redis.SlotSlice
+	for _, slots := range retrieveSlotToMigrateFromRemovedNodes(newPrimaryNodes, oldPrimaryNodes) {
+		slotsToDispatch = append(slotsToDispatch, slots...)
+	}
+	lostSlots := retrieveLostSlots(oldPrimaryNodes, nbSlots)
+	if len(lostSlots) != 0 {
+		glog.Errorf("several slots have been lost: %v", lostSlots)
+	}
+	slotsToDispatch = append(slotsToDispatch, lostSlots...)
+
+	loads := primaryLoads(newPrimaryNodes, weight)
+	// the slots of the overloaded primaries are only moved when the imbalance exceeds the threshold, so that the
+	// variations of the load do not migrate slots back and forth
+	origin := make(map[redis.Slot]string)
+	if maxExcess := maxLoadExcess(loads, target); imbalanced(maxExcess, target) {
+		glog.Infof("rebalancing the slots of the overloaded primaries")
+		for i, node := range newPrimaryNodes {
+			slots := append(redis.SlotSlice{}, node.Slots...)
+			sort.Slice(slots, func(a, b int) bool {
+				if weight(slots[a]) != weight(slots[b]) {
+					return weight(slots[a]) > weight(slots[b])
+				}
+				return slots[a] > slots[b]
+			})
+			// a slot is moved if it brings the primary closer to the target load
+			for _, slot := range slots {
+				if excess := loads[i] - target; excess > 0 && weight(slot) < 2*excess {
+					loads[i] -= weight(slot)
+					slotsToDispatch = append(slotsToDispatch, slot)
+					origin[slot] = node.ID
+				}
+			}
+		}
+	}
+
+	// the slots are dispatched in order to keep contiguous ranges, each primary is filled up to the target load
+	sort.Sort(slotsToDispatch)
+	for _, slot := range slotsToDispatch {
+		dest := -1
+		for i := range loads {
+			if loads[i]+weight(slot)/2 < target {
+				dest = i
+				break
+			}
+		}
+		if dest == -1 {
+			// all primaries reached the target load, the slot goes to the least loaded primary
+			dest = 0
+			for i := range loads {
+				if loads[i] < loads[dest] {
+					dest = i
+				}
+			}
+		}
+		loads[dest] += weight(slot)
+		if id := newPrimaryNodes[dest].ID; origin[slot] != id {
+			slotToAddByNode[id] = append(slotToAddByNode[id], slot)
+		}
+	}
+
+	for i, node := range newPrimaryNodes {
+		glog.Infof("node %s will have a load of %d; expected: %d, %d slots added", node.ID, loads[i], target, len(slotToAddByNode[node.ID]))
+	}
+	return slotToAddByNode
+}
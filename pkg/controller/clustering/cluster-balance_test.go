@@ -0,0 +1,179 @@
+package clustering
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func TestGetSlotWeights(t *testing.T) {
+	fakeAdmin := admin.NewFakeAdmin()
+	primary := &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Slots: redis.SlotSlice{0, 1}}
+	fakeAdmin.CountKeysInSlotRet[primary.IPPort()] = admin.CountKeysInSlotRetType{NbKeys: 10}
+	fakeAdmin.GetKeysInSlotRet[primary.IPPort()] = admin.GetKeysInSlotRetType{Keys: []string{"foo", "bar"}}
+	fakeAdmin.GetMemoryUsageRet["foo"] = 100
+	fakeAdmin.GetMemoryUsageRet["bar"] = 300
+	ctx := context.Background()
+
+	tests := []struct {
+		balanceBy rapi.BalanceBy
+		want      map[redis.Slot]int64
+	}{
+		{balanceBy: rapi.BalanceBySlots, want: nil},
+		{balanceBy: rapi.BalanceByKeys, want: map[redis.Slot]int64{0: 10, 1: 10}},
+		{balanceBy: rapi.BalanceByMemory, want: map[redis.Slot]int64{0: 2000, 1: 2000}},
+	}
+	for _, tt := range tests {
+		got, err := GetSlotWeights(ctx, fakeAdmin, redis.Nodes{primary}, tt.balanceBy)
+		if err != nil {
+			t.Fatalf("GetSlotWeights(%s) unexpected error: %v", tt.balanceBy, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetSlotWeights(%s) = %v, want %v", tt.balanceBy, got, tt.want)
+		}
+	}
+}
+
+func Test_slotWeightsCache(t *testing.T) {
+	fakeAdmin := admin.NewFakeAdmin()
+	primary := &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Slots: redis.SlotSlice{0}}
+	fakeAdmin.CountKeysInSlotRet[primary.IPPort()] = admin.CountKeysInSlotRetType{NbKeys: 10}
+	cluster := &rapi.RedisCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cluster"}}
+	cache := &slotWeightsCache{entries: make(map[string]slotWeightsEntry)}
+	ctx := context.Background()
+	now := time.Now()
+
+	get := func(balanceBy rapi.BalanceBy, now time.Time) map[redis.Slot]int64 {
+		weights, err := cache.get(ctx, fakeAdmin, cluster, redis.Nodes{primary}, balanceBy, now)
+		if err != nil {
+			t.Fatalf("slotWeightsCache.get() unexpected error: %v", err)
+		}
+		return weights
+	}
+	if weights := get(rapi.BalanceByKeys, now); weights[0] != 10 {
+		t.Errorf("slotWeightsCache.get() = %v, want 10 keys in slot 0", weights)
+	}
+	fakeAdmin.CountKeysInSlotRet[primary.IPPort()] = admin.CountKeysInSlotRetType{NbKeys: 20}
+	if weights := get(rapi.BalanceByKeys, now.Add(time.Minute)); weights[0] != 10 {
+		t.Errorf("slotWeightsCache.get() = %v, want the cached weights", weights)
+	}
+	if weights := get(rapi.BalanceByKeys, now.Add(slotWeightsTTL)); weights[0] != 20 {
+		t.Errorf("slotWeightsCache.get() = %v, want the weights measured again after the TTL", weights)
+	}
+	if weights := get(rapi.BalanceBySlots, now); weights != nil {
+		t.Errorf("slotWeightsCache.get() = %v, want nil when balanced by slots", weights)
+	}
+}
+
+func TestNeedLoadRebalance(t *testing.T) {
+	primary1 := &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Slots: redis.SlotSlice{0, 1}}
+	primary2 := &redis.Node{ID: "primary2", IP: "10.0.0.2", Port: "6379", Slots: redis.SlotSlice{2, 3}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		balanceBy rapi.BalanceBy
+		keys1     int64
+		keys2     int64
+		want      bool
+	}{
+		{name: "balanced by slots", balanceBy: rapi.BalanceBySlots, keys1: 100, keys2: 10, want: false},
+		{name: "balanced keys", balanceBy: rapi.BalanceByKeys, keys1: 100, keys2: 100, want: false},
+		{name: "imbalance below the threshold", balanceBy: rapi.BalanceByKeys, keys1: 105, keys2: 95, want: false},
+		{name: "imbalance above the threshold", balanceBy: rapi.BalanceByKeys, keys1: 100, keys2: 10, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin()
+			fakeAdmin.CountKeysInSlotRet[primary1.IPPort()] = admin.CountKeysInSlotRetType{NbKeys: tt.keys1}
+			fakeAdmin.CountKeysInSlotRet[primary2.IPPort()] = admin.CountKeysInSlotRetType{NbKeys: tt.keys2}
+			cluster := &rapi.RedisCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: tt.name},
+				Spec:       rapi.RedisClusterSpec{Scaling: &rapi.Scaling{BalanceBy: tt.balanceBy}},
+			}
+			got, err := NeedLoadRebalance(ctx, fakeAdmin, cluster, redis.Nodes{primary1, primary2})
+			if err != nil || got != tt.want {
+				t.Errorf("NeedLoadRebalance() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildWeightedSlotsByNode(t *testing.T) {
+	redis1 := &redis.Node{ID: "redis1", Slots: redis.SlotSlice{0, 1, 2, 3, 4, 5, 6, 7, 8}}
+	redis2 := &redis.Node{ID: "redis2", Slots: redis.SlotSlice{}}
+	redis3 := &redis.Node{ID: "redis3", Slots: redis.SlotSlice{}}
+
+	redis4 := &redis.Node{ID: "redis4", Slots: redis.SlotSlice{0, 1, 2, 3, 4}}
+	redis5 := &redis.Node{ID: "redis5", Slots: redis.SlotSlice{5, 6, 7, 8}}
+
+	type args struct {
+		newPrimaryNodes redis.Nodes
+		oldPrimaryNodes redis.Nodes
+		weights         map[redis.Slot]int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]redis.SlotSlice
+	}{
+		{
+			name: "empty slots",
+			args: args{
+				newPrimaryNodes: redis.Nodes{redis1, redis2, redis3},
+				oldPrimaryNodes: redis.Nodes{redis1},
+				weights:         map[redis.Slot]int64{},
+			},
+			want: map[string]redis.SlotSlice{
+				redis2.ID: {3, 4, 5},
+				redis3.ID: {6, 7, 8},
+			},
+		},
+		{
+			name: "heavy slot",
+			args: args{
+				newPrimaryNodes: redis.Nodes{redis4, redis5},
+				oldPrimaryNodes: redis.Nodes{redis4, redis5},
+				weights:         map[redis.Slot]int64{0: 99},
+			},
+			want: map[string]redis.SlotSlice{
+				redis5.ID: {1, 2, 3, 4},
+			},
+		},
+		{
+			name: "imbalance below the threshold",
+			args: args{
+				newPrimaryNodes: redis.Nodes{redis4, redis5},
+				oldPrimaryNodes: redis.Nodes{redis4, redis5},
+				weights:         map[redis.Slot]int64{0: 100, 5: 95},
+			},
+			want: map[string]redis.SlotSlice{},
+		},
+		{
+			name: "removed node",
+			args: args{
+				newPrimaryNodes: redis.Nodes{redis4},
+				oldPrimaryNodes: redis.Nodes{redis4, redis5},
+				weights:         map[redis.Slot]int64{5: 10},
+			},
+			want: map[string]redis.SlotSlice{
+				redis4.ID: {5, 6, 7, 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildWeightedSlotsByNode(tt.args.newPrimaryNodes, tt.args.oldPrimaryNodes, 9, tt.args.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildWeightedSlotsByNode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
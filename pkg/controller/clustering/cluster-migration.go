@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
 )
 
 type migrationInfo struct {
@@ -39,11 +42,29 @@ func SelectPrimaries(cluster *redis.Cluster, currentPrimaries, candidatePrimarie
 }
 
 // DispatchSlotsToNewPrimaries used to dispatch slots to the new primary nodes
-func DispatchSlotsToNewPrimaries(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, newPrimaryNodes, currentPrimaryNodes, allPrimaryNodes redis.Nodes, scaling bool) error {
+func DispatchSlotsToNewPrimaries(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, newPrimaryNodes, currentPrimaryNodes, allPrimaryNodes redis.Nodes, scaling bool) (err error) {
+	ctx, span := tracing.Start(ctx, "DispatchSlotsToNewPrimaries", attribute.Int("redis.primaries.new", len(newPrimaryNodes)), attribute.Int("redis.primaries.current", len(currentPrimaryNodes)), attribute.Bool("scaling", scaling))
+	defer func() { tracing.End(span, err) }()
+	var balanceBy rapi.BalanceBy
+	if cluster.Spec.Scaling != nil {
+		balanceBy = cluster.Spec.Scaling.BalanceBy
+	}
+	weights, err := slotWeights.get(ctx, admin, cluster, currentPrimaryNodes, balanceBy, time.Now())
+	if err != nil {
+		glog.Errorf("unable to weight the slots by %s, the primaries are balanced by number of slots: %v", balanceBy, err)
+		weights = nil
+	}
 	// calculate the migration slot information (which slots go where)
-	migrationSlotInfo, info := feedMigInfo(newPrimaryNodes, currentPrimaryNodes, allPrimaryNodes, int(admin.GetHashMaxSlot()+1))
+	migrationSlotInfo, info := feedMigInfo(newPrimaryNodes, currentPrimaryNodes, allPrimaryNodes, int(admin.GetHashMaxSlot()+1), weights)
+	info.NbSlotsToMigrate -= int32(keepPinnedSlots(migrationSlotInfo, newPrimaryNodes, rCluster.PinnedSlots))
 	rCluster.ActionsInfo = info
 	rCluster.Status = rapi.ClusterStatusRebalancing
+	progress := redis.MigrationProgressFrom(ctx)
+	for nodesInfo, slots := range migrationSlotInfo {
+		if nodesInfo.From != nil {
+			progress.AddPlannedSlots(int32(len(slots)))
+		}
+	}
 	for nodesInfo, slots := range migrationSlotInfo {
 		// there is a need for real error handling here, we must ensure we don't keep a slot in abnormal state
 		if nodesInfo.From == nil {
@@ -56,6 +77,8 @@ func DispatchSlotsToNewPrimaries(ctx context.Context, admin redis.AdminInterface
 		} else {
 			if err := admin.MigrateKeys(ctx, nodesInfo.From, nodesInfo.To, slots, &cluster.Spec, true, scaling, allPrimaryNodes); err != nil {
 				glog.Error("error during key migration: ", err)
+				// the slots that were not migrated are still owned by the source
+				slots = redis.RemoveSlots(append(redis.SlotSlice{}, slots...), nodesInfo.From.Slots)
 			}
 		}
 		// update bom
@@ -64,9 +87,15 @@ func DispatchSlotsToNewPrimaries(ctx context.Context, admin redis.AdminInterface
 	return nil
 }
 
-func feedMigInfo(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes redis.Nodes, nbSlots int) (mapOut mapSlotByMigInfo, info redis.ClusterActionsInfo) {
+// feedMigInfo computes the slots migrated between the primaries, the slots are weighted when weights is not nil
+func feedMigInfo(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes redis.Nodes, nbSlots int, weights map[redis.Slot]int64) (mapOut mapSlotByMigInfo, info redis.ClusterActionsInfo) {
 	mapOut = make(mapSlotByMigInfo)
-	mapSlotToUpdate := buildSlotsByNode(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes, nbSlots)
+	var mapSlotToUpdate map[string]redis.SlotSlice
+	if weights != nil {
+		mapSlotToUpdate = buildWeightedSlotsByNode(newPrimaryNodes, oldPrimaryNodes, nbSlots, weights)
+	} else {
+		mapSlotToUpdate = buildSlotsByNode(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes, nbSlots)
+	}
 
 	for id, slots := range mapSlotToUpdate {
 		for _, s := range slots {
@@ -109,6 +138,37 @@ func feedMigInfo(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes redis.Nodes,
 	return mapOut, info
 }
 
+// keepPinnedSlots removes from the migrations the slots pinned to their current primary, as long as this primary
+// remains a primary of the cluster. It returns the number of slots removed.
+func keepPinnedSlots(migrationSlotInfo mapSlotByMigInfo, newPrimaryNodes redis.Nodes, pinnedSlots map[redis.Slot]string) int {
+	nbPinned := 0
+	for nodesInfo, slots := range migrationSlotInfo {
+		if nodesInfo.From == nil {
+			continue
+		}
+		if _, err := newPrimaryNodes.GetNodeByID(nodesInfo.From.ID); err != nil {
+			continue
+		}
+		var kept redis.SlotSlice
+		for _, slot := range slots {
+			if pinnedSlots[slot] == nodesInfo.From.ID {
+				nbPinned++
+				continue
+			}
+			kept = append(kept, slot)
+		}
+		if len(kept) == 0 {
+			delete(migrationSlotInfo, nodesInfo)
+		} else {
+			migrationSlotInfo[nodesInfo] = kept
+		}
+	}
+	if nbPinned > 0 {
+		glog.Infof("%d slots pinned by slot migrations are kept on their primary", nbPinned)
+	}
+	return nbPinned
+}
+
 // buildSlotsByNode get all slots that have to be migrated with retrieveSlotToMigrateFrom and retrieveSlotToMigrateFromRemovedNodes
 // and assign those slots to node that need them
 func buildSlotsByNode(newPrimaryNodes, oldPrimaryNodes, allPrimaryNodes redis.Nodes, nbSlots int) map[string]redis.SlotSlice {
@@ -1,6 +1,8 @@
 package clustering
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"sort"
 	"testing"
@@ -10,6 +12,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
 )
@@ -161,6 +164,22 @@ func TestDispatchSlotToPrimary(t *testing.T) {
 	}
 }
 
+func TestDispatchSlotsToNewPrimaries_failedMigration(t *testing.T) {
+	fakeAdmin := admin.NewFakeAdmin()
+	primary1 := &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Slots: redis.BuildSlotSlice(0, fakeAdmin.GetHashMaxSlot())}
+	primary2 := &redis.Node{ID: "primary2", IP: "10.0.0.2", Port: "6379", Slots: redis.SlotSlice{}}
+	fakeAdmin.AddrError[primary1.IPPort()] = fmt.Errorf("unable to migrate the keys of slots")
+	cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{Scaling: &rapi.Scaling{BalanceBy: rapi.BalanceBySlots}}}
+
+	err := DispatchSlotsToNewPrimaries(context.Background(), fakeAdmin, cluster, &redis.Cluster{}, redis.Nodes{primary1, primary2}, redis.Nodes{primary1}, redis.Nodes{primary1, primary2}, true)
+	if err != nil {
+		t.Fatalf("DispatchSlotsToNewPrimaries() unexpected error: %v", err)
+	}
+	if len(primary2.Slots) != 0 {
+		t.Errorf("DispatchSlotsToNewPrimaries() should not assign the slots that were not migrated, got %s", primary2.Slots)
+	}
+}
+
 func Test_retrieveLostSlots(t *testing.T) {
 	redis1 := &redis.Node{ID: "redis1"}
 	redis2 := &redis.Node{ID: "redis2"}
@@ -413,9 +432,32 @@ func Test_feedMigInfo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if gotMapOut, _ := feedMigInfo(tt.args.newPrimaryNodes, tt.args.oldPrimaryNodes, tt.args.allPrimaryNodes, tt.args.nbSlots); !reflect.DeepEqual(gotMapOut, tt.wantMapOut) {
+			if gotMapOut, _ := feedMigInfo(tt.args.newPrimaryNodes, tt.args.oldPrimaryNodes, tt.args.allPrimaryNodes, tt.args.nbSlots, nil); !reflect.DeepEqual(gotMapOut, tt.wantMapOut) {
 				t.Errorf("feedMigInfo() = %v, want %v", gotMapOut, tt.wantMapOut)
 			}
 		})
 	}
 }
+
+func Test_keepPinnedSlots(t *testing.T) {
+	redis1 := &redis.Node{ID: "redis1", Slots: redis.SlotSlice{0, 1, 2, 3}}
+	redis2 := &redis.Node{ID: "redis2", Slots: redis.SlotSlice{4, 5}}
+	redis3 := &redis.Node{ID: "redis3", Slots: redis.SlotSlice{6, 7}}
+	migrationSlotInfo := mapSlotByMigInfo{
+		{From: redis1, To: redis2}: {2, 3},
+		{From: redis3, To: redis2}: {6, 7},
+	}
+	pinnedSlots := map[redis.Slot]string{2: "redis1", 3: "redis1", 6: "redis3", 4: "redis2"}
+
+	// redis3 is removed from the cluster, its pinned slots are migrated anyway
+	got := keepPinnedSlots(migrationSlotInfo, redis.Nodes{redis1, redis2}, pinnedSlots)
+	if got != 2 {
+		t.Errorf("keepPinnedSlots() = %d, want 2", got)
+	}
+	want := mapSlotByMigInfo{
+		{From: redis3, To: redis2}: {6, 7},
+	}
+	if !reflect.DeepEqual(migrationSlotInfo, want) {
+		t.Errorf("keepPinnedSlots() migrations = %v, want %v", migrationSlotInfo, want)
+	}
+}
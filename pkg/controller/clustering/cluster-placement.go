@@ -3,8 +3,10 @@ package clustering
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/utils"
 	"github.com/golang/glog"
 )
 
@@ -129,32 +131,81 @@ func ZoneToNodes(zones []string, nodes redis.Nodes) map[string]redis.Nodes {
 func selectPrimariesByZone(zones []string, primaries redis.Nodes, nbPrimary int32) (redis.Nodes, error) {
 	selection := redis.Nodes{}
 	zoneToNodes := ZoneToNodes(zones, primaries)
+	var candidateZones []string
+	for zone := range zoneToNodes {
+		candidateZones = append(candidateZones, zone)
+	}
+	sort.Strings(candidateZones)
 	for len(selection) < int(nbPrimary) {
-		for zone, nodes := range zoneToNodes {
-			if len(nodes) > 0 {
-				selection = append(selection, nodes[0])
-				zoneToNodes[zone] = nodes[1:]
+		// keep a primary of the least loaded zone, then of the least loaded host of this zone
+		bestZone := ""
+		var bestLoad []int
+		for _, zone := range candidateZones {
+			if len(zoneToNodes[zone]) == 0 {
+				continue
 			}
-			if len(selection) >= int(nbPrimary) {
-				return selection, nil
+			load := topologyLoad(zone, selection)
+			if bestLoad == nil || lessLoad(load, bestLoad) {
+				bestZone, bestLoad = zone, load
 			}
 		}
+		if bestLoad == nil {
+			return selection, fmt.Errorf("insufficient number of redis nodes for the requested number of primaries")
+		}
+		selection = append(selection, zoneToNodes[bestZone][0])
+		zoneToNodes[bestZone] = zoneToNodes[bestZone][1:]
+	}
+	return selection, nil
+}
+
+// topologyLoad returns the number of nodes sharing the first label value of the zone, then its first two label
+// values, and so on for each topology key
+func topologyLoad(zone string, nodes redis.Nodes) []int {
+	load := make([]int, utils.TopologyLabels(zone))
+	for _, node := range nodes {
+		common := utils.CommonTopologyLabels(zone, node.Zone)
+		for level := 0; level < common && level < len(load); level++ {
+			load[level]++
+		}
+	}
+	return load
+}
+
+func lessLoad(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
 	}
-	return selection, fmt.Errorf("insufficient number of redis nodes for the requested number of primaries")
+	return len(a) < len(b)
 }
 
+// ZonesBalanced returns true if adding the candidate keeps the nodes balanced at each level of the topology keys: its
+// zone has no more nodes than the other zones, and its host no more nodes than the other hosts of its zone
 func ZonesBalanced(zones []string, candidate *redis.Node, nodes redis.Nodes) bool {
 	if len(nodes) == 0 {
 		return true
 	}
 	zoneToNodes := ZoneToNodes(zones, nodes)
-	smallestZoneSize := math.MaxInt32
-	for _, zoneNodes := range zoneToNodes {
-		if len(zoneNodes) < smallestZoneSize {
-			smallestZoneSize = len(zoneNodes)
+	for level := 1; level <= utils.TopologyLabels(candidate.Zone); level++ {
+		parent := utils.TopologyPrefix(candidate.Zone, level-1)
+		prefixToSize := make(map[string]int)
+		for zone, zoneNodes := range zoneToNodes {
+			if utils.TopologyPrefix(zone, level-1) == parent {
+				prefixToSize[utils.TopologyPrefix(zone, level)] += len(zoneNodes)
+			}
+		}
+		smallestZoneSize := math.MaxInt32
+		for _, size := range prefixToSize {
+			if size < smallestZoneSize {
+				smallestZoneSize = size
+			}
+		}
+		if prefixToSize[utils.TopologyPrefix(candidate.Zone, level)] > smallestZoneSize {
+			return false
 		}
 	}
-	return len(zoneToNodes[candidate.Zone]) <= smallestZoneSize
+	return true
 }
 
 // PlaceReplicas selects replica redis nodes for each primary by spreading out the replicas across zones as much as possible.
@@ -239,28 +290,47 @@ func SameZone(zone string, nodes redis.Nodes) bool {
 	return false
 }
 
+// commonTopologyLabels returns the largest number of leading label values the zone shares with the zones of the
+// primary and of its replicas
+func commonTopologyLabels(zone string, primary *redis.Node, replicas redis.Nodes) int {
+	common := utils.CommonTopologyLabels(zone, primary.Zone)
+	for _, replica := range replicas {
+		if c := utils.CommonTopologyLabels(zone, replica.Zone); c > common {
+			common = c
+		}
+	}
+	return common
+}
+
 func selectOptimalReplicas(zones []string, primary *redis.Node, primaryToReplicas map[string]redis.Nodes, zoneToReplicas map[string]redis.Nodes, replicationFactor int32) bool {
 	zoneIndex := GetZoneIndex(zones, primary.Zone, primaryToReplicas[primary.ID])
 	nodeAdded := false
-	i := 0
-	for i < len(zones) && len(primaryToReplicas[primary.ID]) < int(replicationFactor) {
-		zone := zones[zoneIndex]
-		zoneReplicas := zoneToReplicas[zone]
-		zoneIndex = (zoneIndex + 1) % len(zones)
-		if len(zoneReplicas) > 0 {
-			// if RF < # of zones, we can achieve optimal placement
-			if int(replicationFactor) < len(zones) {
-				// skip this zone if it is the same as the primary zone or if this primary already has replicas in this zone
-				if primary.Zone == zone || SameZone(zone, primaryToReplicas[primary.ID]) {
-					continue
-				}
+	for len(primaryToReplicas[primary.ID]) < int(replicationFactor) {
+		// select the zone that shares the fewest leading label values with the primary and its replicas: another
+		// zone before another host of the same zone
+		bestZone := ""
+		bestCommon := -1
+		for i := 0; i < len(zones); i++ {
+			zone := zones[(zoneIndex+i)%len(zones)]
+			if len(zoneToReplicas[zone]) == 0 {
+				continue
 			}
-			nodeAdded = true
-			glog.V(4).Infof("adding replica %s to primary %s", zoneReplicas[0].ID, primary.ID)
-			primaryToReplicas[primary.ID] = append(primaryToReplicas[primary.ID], zoneReplicas[0])
-			zoneToReplicas[zone] = zoneReplicas[1:]
+			if common := commonTopologyLabels(zone, primary, primaryToReplicas[primary.ID]); bestCommon == -1 || common < bestCommon {
+				bestZone, bestCommon = zone, common
+			}
+		}
+		if bestCommon == -1 {
+			break
+		}
+		// if RF < # of zones, we can achieve optimal placement: skip the zones of the primary and of its replicas
+		if int(replicationFactor) < len(zones) && bestCommon == utils.TopologyLabels(bestZone) {
+			break
 		}
-		i++
+		zoneReplicas := zoneToReplicas[bestZone]
+		nodeAdded = true
+		glog.V(4).Infof("adding replica %s to primary %s", zoneReplicas[0].ID, primary.ID)
+		primaryToReplicas[primary.ID] = append(primaryToReplicas[primary.ID], zoneReplicas[0])
+		zoneToReplicas[bestZone] = zoneReplicas[1:]
 	}
 	return nodeAdded
 }
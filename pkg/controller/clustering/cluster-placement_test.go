@@ -144,3 +144,69 @@ func TestPlacePrimaries(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaceReplicasWithTopologyKeys(t *testing.T) {
+	// zone1 has two hosts and zone2 one: the hosts are interleaved as zone1/node1, zone2/node3, zone1/node2, so the
+	// next host after the primary is the other host of its zone
+	_, primary := testutil.NewRedisPrimaryNode("primary", "zone1/node2", "pod2", "node2", []string{"0"})
+	_, sameZone := testutil.NewRedisReplicaNode("replica1", "zone1/node1", "", "pod1", "node1")
+	_, otherZone := testutil.NewRedisReplicaNode("replica2", "zone2/node3", "", "pod3", "node3")
+
+	var kubeNodes []v1.Node
+	for name, zone := range map[string]string{"node1": "zone1", "node2": "zone1", "node3": "zone2"} {
+		kubeNodes = append(kubeNodes, v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone, v1.LabelHostname: name}}})
+	}
+	cluster := &redis.Cluster{
+		Name:         "cluster",
+		Nodes:        map[string]*redis.Node{primary.ID: &primary, sameZone.ID: &sameZone, otherZone.ID: &otherZone},
+		KubeNodes:    kubeNodes,
+		TopologyKeys: []string{v1.LabelTopologyZone, v1.LabelHostname},
+	}
+	primaryToReplicas := map[string]redis.Nodes{primary.ID: {}}
+	if err := PlaceReplicas(cluster, primaryToReplicas, redis.Nodes{&sameZone, &otherZone}, redis.Nodes{}, 1); err != nil {
+		t.Fatalf("PlaceReplicas() error = %v", err)
+	}
+	if replicas := primaryToReplicas[primary.ID]; len(replicas) != 1 || replicas[0].ID != otherZone.ID {
+		t.Errorf("PlaceReplicas() replicas = %v, want the replica of the other zone %s", replicas, otherZone.ID)
+	}
+}
+
+func TestZonesBalancedWithTopologyKeys(t *testing.T) {
+	zones := []string{"zone1/node1", "zone2/node3", "zone1/node2"}
+	_, primary1 := testutil.NewRedisPrimaryNode("primary1", "zone1/node1", "pod1", "node1", []string{"0"})
+	_, primary2 := testutil.NewRedisPrimaryNode("primary2", "zone2/node3", "pod3", "node3", []string{"1"})
+	_, sameZone := testutil.NewRedisPrimaryNode("primary3", "zone1/node2", "pod2", "node2", []string{"2"})
+	_, otherZone := testutil.NewRedisPrimaryNode("primary4", "zone2/node3", "pod4", "node3", []string{"3"})
+	tests := []struct {
+		name      string
+		candidate *redis.Node
+		nodes     redis.Nodes
+		want      bool
+	}{
+		{
+			name:      "empty host of a loaded zone",
+			candidate: &sameZone,
+			nodes:     redis.Nodes{&primary1},
+			want:      false,
+		},
+		{
+			name:      "host of an empty zone",
+			candidate: &otherZone,
+			nodes:     redis.Nodes{&primary1},
+			want:      true,
+		},
+		{
+			name:      "empty host of a balanced zone",
+			candidate: &sameZone,
+			nodes:     redis.Nodes{&primary1, &primary2},
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ZonesBalanced(zones, tt.candidate, tt.nodes); got != tt.want {
+				t.Errorf("ZonesBalanced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -76,6 +76,40 @@ func setRollingUpdateCondition(clusterStatus *rapi.RedisClusterStatus, status bo
 	return setCondition(clusterStatus, rapi.RedisClusterRollingUpdate, statusCondition, metav1.Now(), "rolling update in progress", "rolling update in progress")
 }
 
+func setRestoringCondition(clusterStatus *rapi.RedisClusterStatus, status bool) bool {
+	statusCondition := apiv1.ConditionFalse
+	if status {
+		statusCondition = apiv1.ConditionTrue
+	}
+	return setCondition(clusterStatus, rapi.RedisClusterRestoring, statusCondition, metav1.Now(), "restoring backup", "restoring backup")
+}
+
+func setPausedCondition(clusterStatus *rapi.RedisClusterStatus, status bool) bool {
+	statusCondition := apiv1.ConditionFalse
+	if status {
+		statusCondition = apiv1.ConditionTrue
+	}
+	return setCondition(clusterStatus, rapi.RedisClusterPaused, statusCondition, metav1.Now(), "reconciliation paused", "reconciliation paused")
+}
+
+func setDeletionBlockedCondition(clusterStatus *rapi.RedisClusterStatus, status bool) bool {
+	statusCondition := apiv1.ConditionFalse
+	if status {
+		statusCondition = apiv1.ConditionTrue
+	}
+	return setCondition(clusterStatus, rapi.RedisClusterDeletionBlocked, statusCondition, metav1.Now(), "deletion blocked by deletionProtection", "deletion blocked by deletionProtection")
+}
+
+// hasCondition returns true if the condition of this type is true
+func hasCondition(clusterStatus *rapi.RedisClusterStatus, conditionType rapi.RedisClusterConditionType) bool {
+	for _, c := range clusterStatus.Conditions {
+		if c.Type == conditionType {
+			return c.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func setClusterStatusCondition(clusterStatus *rapi.RedisClusterStatus, status bool) bool {
 	statusCondition := apiv1.ConditionFalse
 	if status {
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -15,10 +18,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1beta1"
 	policyv1 "k8s.io/api/policy/v1beta1"
@@ -26,9 +33,13 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/config"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/clustering"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/metrics"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller/sanitycheck"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
 )
 
 const (
@@ -47,6 +58,8 @@ type Controller struct {
 	recorder record.EventRecorder
 
 	config *Config
+
+	restores restoreCursors
 }
 
 // NewController builds and return new controller instance
@@ -72,44 +85,61 @@ func SetupRedisClusterController(mgr ctrl.Manager, redisClusterController *Contr
 		Owns(&v1.Service{}).
 		Owns(&v1.ConfigMap{}).
 		Owns(&policy.PodDisruptionBudget{}).
+		Watches(&source.Kind{Type: &rapi.RedisSlotMigration{}}, handler.EnqueueRequestsFromMapFunc(slotMigrationClusterRequest)).
+		Watches(&source.Kind{Type: &rapi.RedisClusterAutoscaler{}}, handler.EnqueueRequestsFromMapFunc(autoscalerClusterRequest)).
 		//WithEventFilter(predicate.NewRedisClusterPredicate()). //uncomment to see kubernetes events in the logs, e.g. ConfigMap updates
 		Complete(redisClusterController)
 }
 
-func (c *Controller) Reconcile(ctx context.Context, namespacedName ctrl.Request) (ctrl.Result, error) {
-	var err error
+func (c *Controller) Reconcile(ctx context.Context, namespacedName ctrl.Request) (result ctrl.Result, err error) {
 	glog.V(2).Infof("Reconcile() key:%s", namespacedName)
+	ctx, span := tracing.Start(ctx, "Controller.Reconcile", attribute.String("rediscluster.namespace", namespacedName.Namespace), attribute.String("rediscluster.name", namespacedName.Name))
+	defer func() { tracing.End(span, err) }()
 	startTime := metav1.Now()
+	deleted := false
 	defer func() {
 		reconcileTime := time.Since(startTime.Time)
+		if deleted {
+			metrics.DeleteCluster(namespacedName.Namespace, namespacedName.Name)
+		} else {
+			metrics.ObserveReconcile(namespacedName.Namespace, namespacedName.Name, reconcileTime)
+		}
 		glog.V(2).Infof("finished reconciling RedisCluster %q (%v)", namespacedName, reconcileTime)
 	}()
-	result := ctrl.Result{}
 	sharedRedisCluster := &rapi.RedisCluster{}
 	err = c.client.Get(ctx, namespacedName.NamespacedName, sharedRedisCluster)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			glog.Infof("RedisCluster %s not found. Might be deleted.", namespacedName)
+			deleted = true
 			return result, nil
 		}
 		glog.Errorf("unable to get RedisCluster %s: %v", namespacedName, err)
 		return result, err
 	}
 
-	if !rapi.IsRedisClusterDefaulted(sharedRedisCluster) {
-		defaultedRedisCluster := rapi.DefaultRedisCluster(sharedRedisCluster)
-		if result.Requeue = c.updateRedisClusterSpec(defaultedRedisCluster); result.Requeue {
-			return result, nil
-		}
-		glog.V(6).Infof("RedisCluster %s correctly defaulted", namespacedName)
-	}
+	redisCluster := sharedRedisCluster.DeepCopy()
+	// the spec is defaulted by the mutating webhook, the defaults also apply to clusters created without it
+	redisCluster.Default()
 
-	if sharedRedisCluster.DeletionTimestamp != nil {
+	if redisCluster.DeletionTimestamp != nil {
+		if !redisCluster.Spec.DeletionProtection {
+			return c.teardown(ctx, redisCluster)
+		}
+		// deleted without the validating webhook, the cluster keeps running until deletionProtection is cleared
+		if c.setDeletionBlocked(redisCluster) {
+			if result.Requeue = c.updateRedisClusterStatus(ctx, redisCluster); result.Requeue {
+				return result, nil
+			}
+		}
+	} else if !controllerutil.ContainsFinalizer(redisCluster, rapi.TeardownFinalizer) {
+		if err = c.addTeardownFinalizer(ctx, sharedRedisCluster.DeepCopy()); err != nil {
+			return result, err
+		}
+		// the patch triggers a new reconciliation with the up to date resource version
 		return result, nil
 	}
 
-	redisCluster := sharedRedisCluster.DeepCopy()
-
 	// init status.StartTime
 	if redisCluster.Status.StartTime == nil {
 		redisCluster.Status.StartTime = &startTime
@@ -138,6 +168,56 @@ func (c *Controller) reconcileConfigMap(ctx context.Context, cluster *rapi.Redis
 	return cm, nil
 }
 
+// reconcileServerConfig applies the config changes that redis accepts at runtime on the nodes that differ from the
+// config map, the changes requiring a restart are reported as pending until the rolling update of the pods. The nodes
+// that differ while the other nodes match the config map are reported as drifted.
+func (c *Controller) reconcileServerConfig(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, clusterConfig map[string]string) {
+	nodeChanges, err := checkServerConfig(ctx, admin, clusterConfig)
+	if err != nil {
+		glog.Warningf("unable to get server config: %v", err)
+	}
+	if len(nodeChanges) == 0 {
+		return
+	}
+	restartChanges := make(map[string]string)
+	dynamicFields := make(map[string]bool)
+	failedFields := make(map[string]bool)
+	var updatedAddrs []string
+	for addr, changes := range nodeChanges {
+		dynamicChanges, nodeRestartChanges := splitConfigChanges(changes)
+		for field, val := range nodeRestartChanges {
+			restartChanges[field] = val
+		}
+		if len(dynamicChanges) == 0 {
+			continue
+		}
+		updatedAddrs = append(updatedAddrs, addr)
+		for field := range dynamicChanges {
+			dynamicFields[field] = true
+		}
+		for _, field := range updateConfig(ctx, admin, addr, dynamicChanges) {
+			failedFields[field] = true
+		}
+	}
+
+	var driftedNodes []string
+	if len(updatedAddrs) > 0 && len(updatedAddrs) < len(nodeChanges) {
+		driftedNodes = podNamesByAddr(cluster, updatedAddrs)
+		c.recorder.Eventf(cluster, v1.EventTypeWarning, "ConfigDrift", "Server configuration of nodes %s drifted from the config map", strings.Join(driftedNodes, ", "))
+	} else if len(failedFields) < len(dynamicFields) {
+		c.recorder.Event(cluster, v1.EventTypeNormal, "ConfigUpdate", "Server configuration updated")
+	}
+	failedKeys := make([]string, 0, len(failedFields))
+	for field := range failedFields {
+		failedKeys = append(failedKeys, field)
+	}
+	sort.Strings(failedKeys)
+	if len(failedKeys) > 0 {
+		c.recorder.Eventf(cluster, v1.EventTypeWarning, "ConfigUpdateFailed", "Unable to update the server configuration: %s", strings.Join(failedKeys, ", "))
+	}
+	setConfigKeys(cluster, restartChanges, failedKeys, driftedNodes)
+}
+
 func (c *Controller) getRedisCluster(ctx context.Context, namespace, name string) (*rapi.RedisCluster, error) {
 	newCluster := &rapi.RedisCluster{}
 	namespacedName := types.NamespacedName{
@@ -152,7 +232,7 @@ func (c *Controller) getRedisCluster(ctx context.Context, namespace, name string
 }
 
 func (c *Controller) getRedisClusterService(cluster *rapi.RedisCluster) (*v1.Service, error) {
-	serviceName := getServiceName(cluster)
+	serviceName := cluster.GetServiceName()
 	labels, err := pod.GetLabelsSet(cluster)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get cluster label, err: %v ", err)
@@ -202,16 +282,100 @@ func (c *Controller) getRedisClusterConfigMap(redisCluster *rapi.RedisCluster) (
 	return configMap, c.client.Get(context.Background(), namespacedName, configMap)
 }
 
-func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCluster) (ctrl.Result, error) {
+// getRedisClusterConfig returns a copy of the redis configuration of the operator with the credentials and the TLS
+// configuration of the cluster
+func getRedisClusterConfig(kubeClient kclient.Client, cfg config.Redis, redisCluster *rapi.RedisCluster) (*config.Redis, error) {
+	var err error
+	if cfg.TLSConfig, err = getRedisClusterTLSConfig(kubeClient, redisCluster); err != nil {
+		return nil, err
+	}
+	if redisCluster.Spec.Auth == nil || redisCluster.Spec.Auth.SecretName == "" {
+		return &cfg, nil
+	}
+	secret := &v1.Secret{}
+	namespacedName := types.NamespacedName{
+		Name:      redisCluster.Spec.Auth.SecretName,
+		Namespace: redisCluster.Namespace,
+	}
+	if err = kubeClient.Get(context.Background(), namespacedName, secret); err != nil {
+		return nil, err
+	}
+	password, ok := secret.Data[rapi.AuthPasswordKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, rapi.AuthPasswordKey)
+	}
+	cfg.Password = string(password)
+	cfg.Username = string(secret.Data[rapi.AuthUsernameKey])
+	return &cfg, nil
+}
+
+// updateClaimsNodeID stores the redis node ID in the persistent volume claim of each node,
+// so that the node is not forgotten while its claim waits for a new pod
+func (c *Controller) updateClaimsNodeID(redisCluster *rapi.RedisCluster) error {
+	claims, err := c.podControl.GetRedisClusterClaims(redisCluster)
+	if err != nil || len(claims) == 0 {
+		return err
+	}
+	nodeIDs := map[string]string{}
+	for _, node := range redisCluster.Status.Cluster.Nodes {
+		if node.Pod != nil && node.ID != "" {
+			if claimName := pod.GetClaimName(node.Pod); claimName != "" {
+				nodeIDs[claimName] = node.ID
+			}
+		}
+	}
+	var errs []error
+	for i := range claims {
+		claim := &claims[i]
+		id, ok := nodeIDs[claim.Name]
+		if !ok || claim.Annotations[rapi.NodeIDAnnotationKey] == id {
+			continue
+		}
+		if claim.Annotations == nil {
+			claim.Annotations = map[string]string{}
+		}
+		claim.Annotations[rapi.NodeIDAnnotationKey] = id
+		if err = c.client.Update(context.Background(), claim); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func getRedisClusterTLSConfig(kubeClient kclient.Client, redisCluster *rapi.RedisCluster) (*tls.Config, error) {
+	if redisCluster.Spec.TLS == nil || redisCluster.Spec.TLS.SecretName == "" {
+		return nil, nil
+	}
+	secret := &v1.Secret{}
+	namespacedName := types.NamespacedName{
+		Name:      redisCluster.Spec.TLS.SecretName,
+		Namespace: redisCluster.Namespace,
+	}
+	// the secret is read on each reconcile, so that a certificate rotation is picked up by the next connections
+	if err := kubeClient.Get(context.Background(), namespacedName, secret); err != nil {
+		return nil, err
+	}
+	return redis.NewTLSConfig(secret.Data[rapi.TLSCertKey], secret.Data[rapi.TLSPrivateKeyKey], secret.Data[rapi.TLSCAKey])
+}
+
+func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCluster) (result ctrl.Result, err error) {
 	glog.V(6).Info("syncCluster START")
 	defer glog.V(6).Info("syncCluster STOP")
-	result := ctrl.Result{}
+	ctx, span := tracing.Start(ctx, "Controller.syncCluster")
+	defer func() { tracing.End(span, err) }()
 
 	redisClusterConfigMap, err := c.reconcileConfigMap(ctx, redisCluster)
 	if err != nil {
 		glog.Errorf("RedisCluster-Operator.Reconcile unable to update config map associated with RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
 		return result, err
 	}
+	clusterConfig, err := parseServerConfig(redisClusterConfigMap)
+	if err != nil {
+		glog.Warningf("unable to parse the server config of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+	} else {
+		// the pods are created and rolled with the hash of the settings applied at startup
+		setRestartHash(redisCluster, clusterConfig)
+	}
 
 	redisClusterService, err := c.getRedisClusterService(redisCluster)
 	if err != nil {
@@ -237,6 +401,14 @@ func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCl
 		}
 	}
 
+	if !redisCluster.Spec.Paused {
+		// patched before the pods are listed: the pod selector contains the labels of the PodTemplate
+		if err = c.reconcilePodMetadata(ctx, redisCluster); err != nil {
+			glog.Errorf("RedisCluster-Operator.Reconcile unable to patch the pod metadata of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+			return result, err
+		}
+	}
+
 	redisPods, err := c.podControl.GetRedisClusterPods(redisCluster)
 	if err != nil {
 		glog.Errorf("RedisCluster-Operator.Reconcile unable to retrieve pods associated with RedisCluster: %s/%s", redisCluster.Namespace, redisCluster.Name)
@@ -245,14 +417,23 @@ func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCl
 
 	pods, lostPods := filterLostNodes(redisPods)
 	if len(lostPods) != 0 {
-		for _, p := range lostPods {
-			err = c.podControl.DeletePodNow(redisCluster, p.Name)
-			glog.Errorf("Lost node associated with pod %s: %v", p.Name, err)
+		// the lost pods of a paused cluster are left for the manual repairs
+		if !redisCluster.Spec.Paused {
+			for _, p := range lostPods {
+				err = c.podControl.DeletePodNow(redisCluster, p.Name)
+				glog.Errorf("Lost node associated with pod %s: %v", p.Name, err)
+			}
 		}
 		redisPods = pods
 	}
 
-	admin, err := redis.NewRedisAdmin(ctx, redisPods, &c.config.redis)
+	redisConfig, err := getRedisClusterConfig(c.client, c.config.redis, redisCluster)
+	if err != nil {
+		glog.Errorf("RedisCluster-Operator.Reconcile unable to retrieve auth or tls secret associated with RedisCluster: %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+		return result, err
+	}
+
+	admin, err := redis.NewRedisAdmin(ctx, redisPods, redisConfig)
 	if err != nil {
 		return result, fmt.Errorf("unable to create the redis.Admin, err:%v", err)
 	}
@@ -273,39 +454,61 @@ func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCl
 	}
 
 	// build the cluster status from the RedisCluster nodes,
-	clusterState, err := c.buildClusterState(redisCluster, clusterInfos, redisPods, kubeNodes)
+	clusterState, err := c.buildClusterState(ctx, admin, redisCluster, clusterInfos, redisPods, kubeNodes)
 	if err != nil {
 		return result, fmt.Errorf("unable to build RedisCluster status, err: %v", err)
 	}
 	redisCluster.Status.Cluster = *clusterState
+	if err = c.updateClaimsNodeID(redisCluster); err != nil {
+		glog.Warningf("unable to update the node ID of the persistent volume claims: %v", err)
+	}
 	allPodsReady := true
 	if clusterState.NumberOfPods-clusterState.NumberOfRedisNodesRunning != 0 {
 		glog.V(3).Infof("not all redis nodes are running, numberOfPods: %d, numberOfRedisNodesRunning: %d", clusterState.NumberOfPods, clusterState.NumberOfRedisNodesRunning)
 		allPodsReady = false
 	}
 
+	if c.isPaused(redisCluster) {
+		glog.V(3).Infof("RedisCluster %s/%s is paused, only its status is updated", redisCluster.Namespace, redisCluster.Name)
+		result.Requeue = c.updateClusterStatus(ctx, redisCluster)
+		return result, nil
+	}
+
+	// the failovers do not wait for all the pods to be ready: a forced failover is the way to replace an unreachable primary
+	failedOver, err := c.reconcileFailovers(ctx, admin, redisCluster, clusterInfos, kubeNodes)
+	if err != nil {
+		glog.Errorf("unable to reconcile the failovers of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+		return result, err
+	}
+	if failedOver {
+		c.updateClusterStatus(ctx, redisCluster)
+		result.Requeue = true
+		return result, nil
+	}
+
 	// check if the operator needs to execute some operation on the redis cluster
-	needSanitize, err := c.checkSanity(ctx, redisCluster, admin, clusterInfos)
+	needSanitize, err := c.checkSanity(ctx, redisCluster, admin, clusterInfos, redisConfig)
 	if err != nil {
 		glog.Errorf("checkSanity error occurred in dry run mode: %v", err)
 		return result, err
 	}
 
 	if allPodsReady {
-		configChanges, err := checkServerConfig(ctx, admin, redisClusterConfigMap)
-		if err != nil {
-			glog.Warningf("unable to get server config: %v", err)
-		}
-		if len(configChanges) > 0 {
-			updateConfig(ctx, admin, configChanges)
-			c.recorder.Event(redisCluster, v1.EventTypeNormal, "ConfigUpdate", "Server configuration updated")
+		if clusterConfig != nil {
+			c.reconcileServerConfig(ctx, admin, redisCluster, clusterConfig)
 		}
 		if !checkZoneBalance(redisCluster) {
 			glog.Warningf("Node zones are not balanced. Trigger a rolling update to force reschedule redis pods.")
 			c.recorder.Event(redisCluster, v1.EventTypeWarning, "UnbalancedZones", "Zones are unbalanced")
 		}
-		if needClusterOperation(redisCluster) || needSanitize {
-			result, err = c.clusterAction(ctx, admin, redisCluster, clusterInfos)
+		if err = c.reconcileRollout(ctx, admin, redisCluster, clusterInfos, metav1.Now()); err != nil {
+			glog.Errorf("unable to reconcile the rolling update of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+			return result, err
+		}
+		if needClusterOperation(redisCluster) || needSanitize || c.checkLoadBalance(ctx, redisCluster, admin, clusterInfos) {
+			actionCtx, stopProgress := c.trackProgress(ctx, redisCluster)
+			result, err = c.clusterAction(actionCtx, admin, redisCluster, clusterInfos, redisConfig)
+			stopProgress()
 			if err != nil {
 				return result, err
 			}
@@ -314,32 +517,29 @@ func (c *Controller) syncCluster(ctx context.Context, redisCluster *rapi.RedisCl
 			}
 			return result, nil
 		}
+		migrating, err := c.reconcileSlotMigrations(ctx, admin, redisCluster, clusterInfos)
+		if err != nil {
+			glog.Errorf("unable to reconcile the slot migrations of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+			return result, err
+		}
+		result.Requeue = migrating
+		if !result.Requeue {
+			completeOperation(redisCluster, metav1.Now())
+			if err = c.reconcileAutoscalers(ctx, redisCluster, metav1.Now()); err != nil {
+				glog.Errorf("unable to reconcile the autoscalers of RedisCluster %s/%s: %v", redisCluster.Namespace, redisCluster.Name, err)
+			}
+		}
 	}
 
 	setClusterStatusCondition(&redisCluster.Status, true)
-	result.Requeue = c.updateClusterStatus(ctx, redisCluster)
-	return result, nil
-}
-
-func (c *Controller) updateRedisClusterSpec(desiredCluster *rapi.RedisCluster) bool {
-	ctx := context.Background()
-	actualCluster, err := c.getRedisCluster(ctx, desiredCluster.Namespace, desiredCluster.Name)
-	if err != nil {
-		glog.Errorf("failed to get RedisCluster %s/%s: %v", desiredCluster.Namespace, desiredCluster.Name, err)
-		return false
+	if c.updateClusterStatus(ctx, redisCluster) {
+		result.Requeue = true
 	}
-	actualCluster.Spec = desiredCluster.Spec
-	if err = c.client.Update(ctx, actualCluster); err != nil {
-		if errors.IsConflict(err) {
-			glog.V(6).Infof("conflict occurred when updating RedisCluster %s/%s", desiredCluster.Namespace, desiredCluster.Name)
-			return true
-		}
-		glog.Errorf("failed to update RedisCluster %s/%s: %v", desiredCluster.Name, desiredCluster.Namespace, err)
-		return true
+	if !result.Requeue {
+		// refresh the statistics of the redis nodes
+		result.RequeueAfter = nodeStatsRefreshInterval
 	}
-
-	glog.V(6).Infof("RedisCluster %s/%s updated", desiredCluster.Namespace, desiredCluster.Name)
-	return false
+	return result, nil
 }
 
 func (c *Controller) updateRedisClusterStatus(ctx context.Context, redisCluster *rapi.RedisCluster) bool {
@@ -357,6 +557,7 @@ func (c *Controller) updateRedisClusterStatus(ctx context.Context, redisCluster
 }
 
 func (c *Controller) updateClusterStatus(ctx context.Context, desiredCluster *rapi.RedisCluster) bool {
+	metrics.SetClusterStatus(desiredCluster)
 	actualCluster, err := c.getRedisCluster(ctx, desiredCluster.Namespace, desiredCluster.Name)
 	if err != nil {
 		glog.Errorf("failed to get RedisCluster %s/%s: %v", desiredCluster.Namespace, desiredCluster.Name, err)
@@ -371,8 +572,9 @@ func (c *Controller) updateClusterStatus(ctx context.Context, desiredCluster *ra
 	return false
 }
 
-func (c *Controller) buildClusterState(cluster *rapi.RedisCluster, clusterInfos *redis.ClusterInfos, pods []v1.Pod, kubeNodes []v1.Node) (*rapi.RedisClusterState, error) {
-	clusterState := getRedisClusterState(clusterInfos, pods, kubeNodes)
+func (c *Controller) buildClusterState(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, clusterInfos *redis.ClusterInfos, pods []v1.Pod, kubeNodes []v1.Node) (*rapi.RedisClusterState, error) {
+	clusterState := getRedisClusterState(clusterInfos, pods, kubeNodes, utils.GetTopologyKeys(cluster))
+	setNodeStats(ctx, admin, clusterState.Nodes, cluster.Status.Cluster.Nodes, metav1.Now())
 	podLabels, err := pod.GetLabelsSet(cluster)
 	if err != nil {
 		glog.Errorf("unable to get label set: %v", err)
@@ -386,8 +588,19 @@ func (c *Controller) buildClusterState(cluster *rapi.RedisCluster, clusterInfos
 	return clusterState, nil
 }
 
-func (c *Controller) checkSanity(ctx context.Context, cluster *rapi.RedisCluster, admin redis.AdminInterface, infos *redis.ClusterInfos) (bool, error) {
-	return sanitycheck.RunSanityChecks(ctx, admin, &c.config.redis, c.podControl, cluster, infos, true)
+func (c *Controller) checkSanity(ctx context.Context, cluster *rapi.RedisCluster, admin redis.AdminInterface, infos *redis.ClusterInfos, redisConfig *config.Redis) (bool, error) {
+	return sanitycheck.RunSanityChecks(ctx, admin, redisConfig, c.podControl, cluster, infos, true)
+}
+
+// checkLoadBalance returns true when the slots must be moved from an overloaded primary while the number of primaries
+// does not change, see clustering.NeedLoadRebalance
+func (c *Controller) checkLoadBalance(ctx context.Context, cluster *rapi.RedisCluster, admin redis.AdminInterface, infos *redis.ClusterInfos) bool {
+	needRebalance, err := clustering.NeedLoadRebalance(ctx, admin, cluster, infos.GetNodes().FilterByFunc(redis.IsPrimaryWithSlot))
+	if err != nil {
+		glog.Warningf("unable to check the load of the primaries of RedisCluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+		return false
+	}
+	return needRebalance
 }
 
 func getReplicationFactors(numberOfReplicasPerPrimary map[string]int) (int, int) {
@@ -407,7 +620,7 @@ func getReplicationFactors(numberOfReplicasPerPrimary map[string]int) (int, int)
 	return minReplicationFactor, maxReplicationFactor
 }
 
-func getRedisClusterState(clusterInfos *redis.ClusterInfos, pods []v1.Pod, kubeNodes []v1.Node) *rapi.RedisClusterState {
+func getRedisClusterState(clusterInfos *redis.ClusterInfos, pods []v1.Pod, kubeNodes []v1.Node, topologyKeys []string) *rapi.RedisClusterState {
 	clusterState := &rapi.RedisClusterState{}
 	clusterState.NumberOfPodsReady = 0
 	clusterState.NumberOfRedisNodesRunning = 0
@@ -437,7 +650,7 @@ func getRedisClusterState(clusterInfos *redis.ClusterInfos, pods []v1.Pod, kubeN
 		newNode := rapi.RedisClusterNode{
 			PodName: p.Name,
 			IP:      p.Status.PodIP,
-			Zone:    utils.GetZone(p.Spec.NodeName, kubeNodes),
+			Zone:    utils.GetZone(p.Spec.NodeName, kubeNodes, topologyKeys),
 			Pod:     &pods[i],
 			Slots:   []string{},
 		}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/metrics"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/utils"
+)
+
+const (
+	// failoverTimeout maximum time to wait for the replica to be reported as a primary
+	failoverTimeout = 30 * time.Second
+	// failoverPollInterval time between two checks of the roles in the cluster
+	failoverPollInterval = time.Second
+	// failoverOffsetTolerance maximum replication offset lag, in bytes, of a replica in the zone of the primary
+	// to be preferred to a replica of another zone
+	failoverOffsetTolerance = 1024
+)
+
+// reconcileFailovers executes the failovers requested with the failover annotation on the pods of the cluster,
+// the annotation is removed once the outcome is recorded as an event. It returns true if a failover was executed.
+func (c *Controller) reconcileFailovers(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, kubeNodes []v1.Node) (bool, error) {
+	pods, err := c.podControl.GetRedisClusterPods(cluster)
+	if err != nil {
+		return false, err
+	}
+	failedOver := false
+	for i := range pods {
+		pod := &pods[i]
+		mode, ok := pod.Annotations[rapi.FailoverAnnotationKey]
+		if !ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err = c.failover(ctx, admin, cluster, infos, pod, utils.GetZone(pod.Spec.NodeName, kubeNodes, utils.GetTopologyKeys(cluster)), mode); err != nil {
+			glog.Errorf("failover of pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+			c.recorder.Eventf(cluster, v1.EventTypeWarning, "FailoverFailed", "Failover of pod %s failed: %v", pod.Name, err)
+		} else {
+			failedOver = true
+		}
+		patch := kclient.MergeFrom(pod.DeepCopy())
+		delete(pod.Annotations, rapi.FailoverAnnotationKey)
+		if err = c.client.Patch(ctx, pod, patch); err != nil {
+			return failedOver, err
+		}
+		if failedOver {
+			// the roles changed, the next failover is chosen from up-to-date cluster infos
+			break
+		}
+	}
+	return failedOver, nil
+}
+
+// failover hands the slots of the primary running in the pod over to its best replica, and waits for the replica
+// to be reported as a primary
+func (c *Controller) failover(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, pod *v1.Pod, primaryZone, mode string) error {
+	var option string
+	failoverMode := strings.ToLower(mode)
+	switch failoverMode {
+	case "", rapi.FailoverModeDefault:
+		failoverMode = rapi.FailoverModeDefault
+	case rapi.FailoverModeForce:
+		option = "FORCE"
+	case rapi.FailoverModeTakeover:
+		option = "TAKEOVER"
+	default:
+		return fmt.Errorf("unsupported failover mode %q, supported modes: %s, %s, %s", mode, rapi.FailoverModeDefault, rapi.FailoverModeForce, rapi.FailoverModeTakeover)
+	}
+	podName := pod.Name
+	zones := make(map[string]string)
+	for _, node := range cluster.Status.Cluster.Nodes {
+		zones[node.ID] = node.Zone
+	}
+	nodes := clusterNodes(infos)
+	podNodes, err := nodes.GetNodesByFunc(func(node *redis.Node) bool {
+		return pod.Status.PodIP != "" && node.IP == pod.Status.PodIP
+	})
+	if err != nil {
+		return fmt.Errorf("no redis node found for the pod")
+	}
+	primary := podNodes[0]
+	if !redis.IsPrimaryWithSlot(primary) {
+		return fmt.Errorf("redis node %s is not a primary with slots", primary.ID)
+	}
+	replica, err := selectFailoverReplica(ctx, admin, nodes, primary, primaryZone, zones)
+	if err != nil {
+		return err
+	}
+
+	glog.Infof("failover of primary %s of pod %s to replica %s, mode %q", primary.ID, podName, replica.ID, mode)
+	if err = admin.Failover(ctx, replica.IPPort(), option); err != nil {
+		return err
+	}
+	metrics.RecordFailover(cluster, failoverMode)
+	if err = waitFailover(ctx, admin, replica.ID); err != nil {
+		return err
+	}
+	c.recorder.Eventf(cluster, v1.EventTypeNormal, "FailoverCompleted", "Replica %s took over primary %s of pod %s", replica.ID, primary.ID, podName)
+	return nil
+}
+
+// clusterNodes returns the nodes of the cluster infos, completed with the view of the other nodes: the pod of an
+// unreachable primary is not ready, and only its replicas still know it
+func clusterNodes(infos *redis.ClusterInfos) redis.Nodes {
+	nodes := infos.GetNodes()
+	for _, nodeInfos := range infos.Infos {
+		for _, friend := range nodeInfos.Friends {
+			if _, err := nodes.GetNodeByID(friend.ID); err != nil {
+				nodes = append(nodes, friend)
+			}
+		}
+	}
+	return nodes
+}
+
+// selectFailoverReplica returns the healthy replica of the primary with the highest replication offset,
+// a replica in the zone of the primary lagging by at most failoverOffsetTolerance bytes is preferred to keep
+// the primaries spread across the zones
+func selectFailoverReplica(ctx context.Context, admin redis.AdminInterface, nodes redis.Nodes, primary *redis.Node, primaryZone string, zones map[string]string) (*redis.Node, error) {
+	var best, bestInZone *redis.Node
+	var bestOffset, bestInZoneOffset int64
+	for _, node := range nodes {
+		if node.PrimaryReferent != primary.ID || !redis.IsReplica(node) || node.HasStatus(redis.NodeStatusFail) || node.HasStatus(redis.NodeStatusPFail) {
+			continue
+		}
+		replication, err := admin.GetInfo(ctx, node.IPPort(), "replication")
+		if err != nil {
+			glog.Warningf("unable to get the replication offset of replica %s: %v", node.ID, err)
+			continue
+		}
+		offset, _ := strconv.ParseInt(replication["slave_repl_offset"], 10, 64)
+		if best == nil || offset > bestOffset {
+			best, bestOffset = node, offset
+		}
+		if zones[node.ID] == primaryZone && (bestInZone == nil || offset > bestInZoneOffset) {
+			bestInZone, bestInZoneOffset = node, offset
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("primary %s has no healthy replica", primary.ID)
+	}
+	if bestInZone != nil && bestOffset-bestInZoneOffset <= failoverOffsetTolerance {
+		return bestInZone, nil
+	}
+	return best, nil
+}
+
+// waitFailover waits until the cluster reports the replica as a primary with slots
+func waitFailover(ctx context.Context, admin redis.AdminInterface, replicaID string) error {
+	deadline := time.Now().Add(failoverTimeout)
+	for {
+		infos, err := admin.GetClusterInfos(ctx)
+		if infos != nil {
+			if node, err := infos.GetNodes().GetNodeByID(replicaID); err == nil && redis.IsPrimaryWithSlot(node) {
+				return nil
+			}
+		} else if err != nil {
+			glog.Warningf("unable to get cluster infos: %v", err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("replica %s not reported as a primary after %s", replicaID, failoverTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(failoverPollInterval):
+		}
+	}
+}
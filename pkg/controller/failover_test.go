@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func Test_selectFailoverReplica(t *testing.T) {
+	primary := &redis.Node{ID: "primary", Role: "master", Slots: redis.SlotSlice{0}}
+	replica1 := &redis.Node{ID: "replica1", IP: "10.0.0.1", Port: "6379", Role: "slave", PrimaryReferent: "primary"}
+	replica2 := &redis.Node{ID: "replica2", IP: "10.0.0.2", Port: "6379", Role: "slave", PrimaryReferent: "primary"}
+	replica3 := &redis.Node{ID: "replica3", IP: "10.0.0.3", Port: "6379", Role: "slave", PrimaryReferent: "primary", FailStatus: []string{redis.NodeStatusFail}}
+	nodes := redis.Nodes{primary, replica1, replica2, replica3}
+	zones := map[string]string{"primary": "zone1", "replica1": "zone2", "replica2": "zone1", "replica3": "zone1"}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		offsets map[string]string
+		want    string
+	}{
+		{name: "highest offset", offsets: map[string]string{"10.0.0.1:6379": "2000", "10.0.0.2:6379": "100", "10.0.0.3:6379": "300"}, want: "replica1"},
+		{name: "same zone on equal offsets", offsets: map[string]string{"10.0.0.1:6379": "100", "10.0.0.2:6379": "100"}, want: "replica2"},
+		{name: "same zone within the tolerance", offsets: map[string]string{"10.0.0.1:6379": "1100", "10.0.0.2:6379": "100"}, want: "replica2"},
+		{name: "other zone beyond the tolerance", offsets: map[string]string{"10.0.0.1:6379": "1200", "10.0.0.2:6379": "100"}, want: "replica1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin()
+			for addr, offset := range tt.offsets {
+				fakeAdmin.GetInfoRet[addr] = map[string]string{"slave_repl_offset": offset}
+			}
+			got, err := selectFailoverReplica(ctx, fakeAdmin, nodes, primary, "zone1", zones)
+			if err != nil || got.ID != tt.want {
+				t.Errorf("selectFailoverReplica() = %v, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+	if _, err := selectFailoverReplica(ctx, admin.NewFakeAdmin(), redis.Nodes{primary, replica3}, primary, "zone1", zones); err == nil {
+		t.Errorf("selectFailoverReplica() should fail without a healthy replica")
+	}
+}
+
+func TestController_reconcileFailovers(t *testing.T) {
+	primaryNode := &redis.Node{ID: "primary", IP: "10.0.0.1", Port: "6379", Role: "master", Slots: redis.SlotSlice{0}}
+	replicaNode := &redis.Node{ID: "replica", IP: "10.0.0.2", Port: "6379", Role: "slave", PrimaryReferent: "primary"}
+	tests := []struct {
+		name        string
+		statusNodes []rapi.RedisClusterNode
+		infos       *redis.ClusterInfos
+	}{
+		{
+			name: "ready primary",
+			statusNodes: []rapi.RedisClusterNode{
+				{ID: "primary", PodName: "pod1", Zone: "zone1"},
+				{ID: "replica", PodName: "pod2", Zone: "zone2"},
+			},
+			infos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+				"primary": {Node: primaryNode},
+				"replica": {Node: replicaNode},
+			}},
+		},
+		{
+			// the pod of an unreachable primary is not ready: it is missing from the status and the cluster infos,
+			// only its replica still knows it
+			name: "primary pod not ready",
+			statusNodes: []rapi.RedisClusterNode{
+				{ID: "replica", PodName: "pod2", Zone: "zone2"},
+			},
+			infos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+				"replica": {Node: replicaNode, Friends: redis.Nodes{primaryNode}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{
+				ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+				Status:     rapi.RedisClusterStatus{Cluster: rapi.RedisClusterState{Nodes: tt.statusNodes}},
+			}
+			labels := map[string]string{rapi.ClusterNameLabelKey: "cluster"}
+			pod1 := &kapiv1.Pod{
+				ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "pod1", Labels: labels, Annotations: map[string]string{rapi.FailoverAnnotationKey: rapi.FailoverModeForce}},
+				Status:     kapiv1.PodStatus{PodIP: "10.0.0.1"},
+			}
+			pod2 := &kapiv1.Pod{ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "pod2", Labels: labels}, Status: kapiv1.PodStatus{PodIP: "10.0.0.2"}}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod1, pod2).Build()
+			recorder := record.NewFakeRecorder(10)
+			c := &Controller{client: fakeClient, recorder: recorder, podControl: pod.NewRedisClusterControl(fakeClient, recorder)}
+
+			fakeAdmin := admin.NewFakeAdmin()
+			fakeAdmin.GetClusterInfosRet = admin.ClusterInfosRetType{ClusterInfos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+				"primary": {Node: &redis.Node{ID: "primary", IP: "10.0.0.1", Port: "6379", Role: "slave", PrimaryReferent: "replica"}},
+				"replica": {Node: &redis.Node{ID: "replica", IP: "10.0.0.2", Port: "6379", Role: "master", Slots: redis.SlotSlice{0}}},
+			}}}
+			ctx := context.Background()
+
+			failedOver, err := c.reconcileFailovers(ctx, fakeAdmin, cluster, tt.infos, nil)
+			if err != nil || !failedOver {
+				t.Fatalf("reconcileFailovers() = %v, %v, want a failover", failedOver, err)
+			}
+			if option, ok := fakeAdmin.Failovers["10.0.0.2:6379"]; !ok || option != "FORCE" {
+				t.Errorf("reconcileFailovers() should execute CLUSTER FAILOVER FORCE on the replica, got %v", fakeAdmin.Failovers)
+			}
+			if err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pod1"}, pod1); err != nil {
+				t.Fatalf("unable to get the pod: %v", err)
+			}
+			if _, ok := pod1.Annotations[rapi.FailoverAnnotationKey]; ok {
+				t.Errorf("reconcileFailovers() should remove the failover annotation")
+			}
+			if event := <-recorder.Events; event != "Normal FailoverCompleted Replica replica took over primary primary of pod pod1" {
+				t.Errorf("reconcileFailovers() unexpected event: %s", event)
+			}
+		})
+	}
+}
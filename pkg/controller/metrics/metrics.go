@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+const (
+	metricsNamespace = "redis_operator"
+
+	namespaceLabel = "namespace"
+	clusterLabel   = "cluster"
+)
+
+// failoverModes values of the mode label of the failovers
+var failoverModes = []string{rapi.FailoverModeDefault, rapi.FailoverModeForce, rapi.FailoverModeTakeover}
+
+// sanityCheckActionNames values of the action label of the sanity check actions
+var sanityCheckActionNames = []string{"FixFailedNodes", "FixUntrustedNodes", "FixTerminatingPods", "FixClusterSplit"}
+
+// clusterStatuses values of the cluster status state set
+var clusterStatuses = []rapi.ClusterStatus{
+	rapi.ClusterStatusOK,
+	rapi.ClusterStatusKO,
+	rapi.ClusterStatusScaling,
+	rapi.ClusterStatusRebalancing,
+	rapi.ClusterStatusRollingUpdate,
+	rapi.ClusterStatusRestoring,
+}
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations of a RedisCluster",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	}, []string{namespaceLabel, clusterLabel})
+
+	slotMigrationsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "slot_migrations_started_total",
+		Help:      "Number of slot migrations started",
+	}, []string{namespaceLabel, clusterLabel})
+
+	slotMigrationsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "slot_migrations_completed_total",
+		Help:      "Number of slot migrations completed",
+	}, []string{namespaceLabel, clusterLabel})
+
+	slotMigrationsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "slot_migrations_failed_total",
+		Help:      "Number of slot migrations failed",
+	}, []string{namespaceLabel, clusterLabel})
+
+	keysMigrated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "keys_migrated_total",
+		Help:      "Number of keys migrated between redis nodes",
+	}, []string{namespaceLabel, clusterLabel})
+
+	migrationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "migration_duration_seconds",
+		Help:      "Duration of the slot migrations of a cluster operation",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{namespaceLabel, clusterLabel})
+
+	failoversTriggered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "failovers_total",
+		Help:      "Number of failovers triggered by the operator",
+	}, []string{namespaceLabel, clusterLabel, "mode"})
+
+	sanityCheckActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sanity_check_actions_total",
+		Help:      "Number of actions executed by the sanity checks",
+	}, []string{namespaceLabel, clusterLabel, "action"})
+
+	podsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pods_created_total",
+		Help:      "Number of redis pods created",
+	}, []string{namespaceLabel, clusterLabel})
+
+	podsDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pods_deleted_total",
+		Help:      "Number of redis pods deleted",
+	}, []string{namespaceLabel, clusterLabel})
+
+	clusterStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "cluster_status",
+		Help:      "Status of a RedisCluster, 1 for the current status and 0 for the others",
+	}, []string{namespaceLabel, clusterLabel, "status"})
+
+	clusterCollectors = []*prometheus.MetricVec{
+		reconcileDuration.MetricVec,
+		slotMigrationsStarted.MetricVec,
+		slotMigrationsCompleted.MetricVec,
+		slotMigrationsFailed.MetricVec,
+		keysMigrated.MetricVec,
+		migrationDuration.MetricVec,
+		podsCreated.MetricVec,
+		podsDeleted.MetricVec,
+	}
+)
+
+func init() {
+	// the collectors are served on the metrics endpoint of the manager
+	crmetrics.Registry.MustRegister(
+		reconcileDuration,
+		slotMigrationsStarted,
+		slotMigrationsCompleted,
+		slotMigrationsFailed,
+		keysMigrated,
+		migrationDuration,
+		failoversTriggered,
+		sanityCheckActions,
+		podsCreated,
+		podsDeleted,
+		clusterStatus,
+	)
+}
+
+func clusterLabels(cluster *rapi.RedisCluster) prometheus.Labels {
+	return prometheus.Labels{namespaceLabel: cluster.Namespace, clusterLabel: cluster.Name}
+}
+
+// ObserveReconcile records the duration of a reconciliation of the cluster
+func ObserveReconcile(namespace, name string, duration time.Duration) {
+	reconcileDuration.WithLabelValues(namespace, name).Observe(duration.Seconds())
+}
+
+// RecordSlotMigrations records the slot migrations of a cluster operation: the slots planned are the migrations
+// started, and the duration is the time elapsed since the first slot was planned
+func RecordSlotMigrations(cluster *rapi.RedisCluster, started, completed, failed int32, keys int64, duration time.Duration) {
+	if started == 0 && completed == 0 && failed == 0 {
+		return
+	}
+	labels := clusterLabels(cluster)
+	slotMigrationsStarted.With(labels).Add(float64(started))
+	slotMigrationsCompleted.With(labels).Add(float64(completed))
+	slotMigrationsFailed.With(labels).Add(float64(failed))
+	keysMigrated.With(labels).Add(float64(keys))
+	if duration > 0 {
+		migrationDuration.With(labels).Observe(duration.Seconds())
+	}
+}
+
+// RecordFailover records a failover triggered on a replica of the cluster, the mode is one of the failover modes
+func RecordFailover(cluster *rapi.RedisCluster, mode string) {
+	failoversTriggered.WithLabelValues(cluster.Namespace, cluster.Name, mode).Inc()
+}
+
+// RecordSanityCheckAction records an action executed by a sanity check, the action is the name of the check
+func RecordSanityCheckAction(cluster *rapi.RedisCluster, action string) {
+	sanityCheckActions.WithLabelValues(cluster.Namespace, cluster.Name, action).Inc()
+}
+
+// RecordPodCreated records the creation of a redis pod of the cluster
+func RecordPodCreated(cluster *rapi.RedisCluster) {
+	podsCreated.With(clusterLabels(cluster)).Inc()
+}
+
+// RecordPodDeleted records the deletion of a redis pod of the cluster
+func RecordPodDeleted(cluster *rapi.RedisCluster) {
+	podsDeleted.With(clusterLabels(cluster)).Inc()
+}
+
+// SetClusterStatus sets the status of the cluster in the state set, an empty status is not reported
+func SetClusterStatus(cluster *rapi.RedisCluster) {
+	current := cluster.Status.Cluster.Status
+	if current == "" {
+		return
+	}
+	for _, status := range clusterStatuses {
+		value := 0.0
+		if status == current {
+			value = 1
+		}
+		clusterStatus.WithLabelValues(cluster.Namespace, cluster.Name, string(status)).Set(value)
+	}
+}
+
+// DeleteCluster removes the series of a deleted cluster
+func DeleteCluster(namespace, name string) {
+	for _, vec := range clusterCollectors {
+		vec.DeleteLabelValues(namespace, name)
+	}
+	for _, mode := range failoverModes {
+		failoversTriggered.DeleteLabelValues(namespace, name, mode)
+	}
+	for _, action := range sanityCheckActionNames {
+		sanityCheckActions.DeleteLabelValues(namespace, name, action)
+	}
+	for _, status := range clusterStatuses {
+		clusterStatus.DeleteLabelValues(namespace, name, string(status))
+	}
+}
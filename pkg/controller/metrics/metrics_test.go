@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+func newCluster(name string, status rapi.ClusterStatus) *rapi.RedisCluster {
+	return &rapi.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     rapi.RedisClusterStatus{Cluster: rapi.RedisClusterState{Status: status}},
+	}
+}
+
+func TestSetClusterStatus(t *testing.T) {
+	cluster := newCluster("status", rapi.ClusterStatusOK)
+	SetClusterStatus(cluster)
+	cluster.Status.Cluster.Status = rapi.ClusterStatusScaling
+	SetClusterStatus(cluster)
+	for _, status := range clusterStatuses {
+		want := 0.0
+		if status == rapi.ClusterStatusScaling {
+			want = 1
+		}
+		if got := testutil.ToFloat64(clusterStatus.WithLabelValues("default", "status", string(status))); got != want {
+			t.Errorf("cluster_status{status=%q} = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestRecordSlotMigrations(t *testing.T) {
+	cluster := newCluster("migrations", rapi.ClusterStatusRebalancing)
+	RecordSlotMigrations(cluster, 0, 0, 0, 0, 0)
+	if got := testutil.CollectAndCount(slotMigrationsStarted); got != 0 {
+		t.Fatalf("slot_migrations_started_total series = %d, want 0", got)
+	}
+	RecordSlotMigrations(cluster, 10, 8, 2, 42, time.Minute)
+	RecordSlotMigrations(cluster, 5, 5, 0, 8, time.Minute)
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "started", got: testutil.ToFloat64(slotMigrationsStarted.WithLabelValues("default", "migrations")), want: 15},
+		{name: "completed", got: testutil.ToFloat64(slotMigrationsCompleted.WithLabelValues("default", "migrations")), want: 13},
+		{name: "failed", got: testutil.ToFloat64(slotMigrationsFailed.WithLabelValues("default", "migrations")), want: 2},
+		{name: "keys", got: testutil.ToFloat64(keysMigrated.WithLabelValues("default", "migrations")), want: 50},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDeleteCluster(t *testing.T) {
+	cluster := newCluster("deleted", rapi.ClusterStatusOK)
+	ObserveReconcile(cluster.Namespace, cluster.Name, time.Second)
+	RecordFailover(cluster, rapi.FailoverModeForce)
+	RecordSanityCheckAction(cluster, "FixFailedNodes")
+	RecordPodCreated(cluster)
+	RecordPodDeleted(cluster)
+	SetClusterStatus(cluster)
+
+	DeleteCluster(cluster.Namespace, cluster.Name)
+	for _, vec := range clusterCollectors {
+		if vec.DeleteLabelValues(cluster.Namespace, cluster.Name) {
+			t.Errorf("series of the deleted cluster left in a collector")
+		}
+	}
+	if failoversTriggered.DeleteLabelValues(cluster.Namespace, cluster.Name, rapi.FailoverModeForce) {
+		t.Errorf("failovers_total series of the deleted cluster left")
+	}
+	if sanityCheckActions.DeleteLabelValues(cluster.Namespace, cluster.Name, "FixFailedNodes") {
+		t.Errorf("sanity_check_actions_total series of the deleted cluster left")
+	}
+	if clusterStatus.DeleteLabelValues(cluster.Namespace, cluster.Name, string(rapi.ClusterStatusOK)) {
+		t.Errorf("cluster_status series of the deleted cluster left")
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+// nodeStatsRefreshInterval minimum time between two collections of the statistics of the redis nodes,
+// it limits the number of updates of the RedisCluster status
+const nodeStatsRefreshInterval = 30 * time.Second
+
+// setNodeStats sets the statistics of the redis nodes of the cluster state. The statistics of the previous status are
+// kept while they are up to date, otherwise they are collected again for all the nodes.
+func setNodeStats(ctx context.Context, admin redis.AdminInterface, nodes, previousNodes []rapi.RedisClusterNode, now metav1.Time) {
+	previousStats := make(map[string]*rapi.RedisNodeStats)
+	for _, node := range previousNodes {
+		if node.Stats != nil {
+			previousStats[node.ID] = node.Stats
+		}
+	}
+	refresh := false
+	for _, node := range nodes {
+		if node.ID == "" {
+			continue
+		}
+		stats, ok := previousStats[node.ID]
+		if !ok || now.Sub(stats.LastUpdateTime.Time) >= nodeStatsRefreshInterval {
+			refresh = true
+			break
+		}
+	}
+	if !refresh {
+		for i := range nodes {
+			nodes[i].Stats = previousStats[nodes[i].ID]
+		}
+		return
+	}
+
+	var addrs []string
+	for _, node := range nodes {
+		if node.ID != "" {
+			addrs = append(addrs, node.IP+":"+node.Port)
+		}
+	}
+	// the default INFO sections include the memory, replication, keyspace, stats and clients sections
+	infos, err := admin.GetInfos(ctx, addrs, "default")
+	if err != nil {
+		glog.Warningf("unable to get the statistics of some redis nodes: %v", err)
+	}
+	offsets := make(map[string]int64)
+	for i := range nodes {
+		node := &nodes[i]
+		if node.ID == "" {
+			continue
+		}
+		info, ok := infos[node.IP+":"+node.Port]
+		if !ok {
+			node.Stats = previousStats[node.ID]
+			continue
+		}
+		node.Stats = decodeNodeStats(info, now)
+		offsets[node.ID] = node.Stats.ReplicationOffset
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Stats == nil || node.Role != rapi.RedisClusterNodeRoleReplica {
+			continue
+		}
+		if primaryOffset, ok := offsets[node.PrimaryRef]; ok && primaryOffset > node.Stats.ReplicationOffset {
+			node.Stats.ReplicationLag = primaryOffset - node.Stats.ReplicationOffset
+		}
+	}
+}
+
+// decodeNodeStats returns the statistics of a redis node from the fields of the INFO command
+func decodeNodeStats(info map[string]string, now metav1.Time) *rapi.RedisNodeStats {
+	stats := &rapi.RedisNodeStats{
+		UsedMemory:        parseInfoInt(info["used_memory"]),
+		MaxMemory:         parseInfoInt(info["maxmemory"]),
+		ReplicationOffset: parseInfoInt(info["master_repl_offset"]),
+		ConnectedClients:  parseInfoInt(info["connected_clients"]),
+		OpsPerSec:         parseInfoInt(info["instantaneous_ops_per_sec"]),
+		LastUpdateTime:    now,
+	}
+	if offset, ok := info["slave_repl_offset"]; ok {
+		stats.ReplicationOffset = parseInfoInt(offset)
+	}
+	// the keyspace section contains one line per database, for instance db0:keys=10,expires=0,avg_ttl=0
+	for field, value := range info {
+		if !strings.HasPrefix(field, "db") {
+			continue
+		}
+		for _, kv := range strings.Split(value, ",") {
+			if strings.HasPrefix(kv, "keys=") {
+				stats.Keys += parseInfoInt(strings.TrimPrefix(kv, "keys="))
+			}
+		}
+	}
+	return stats
+}
+
+func parseInfoInt(value string) int64 {
+	i, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return i
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func Test_decodeNodeStats(t *testing.T) {
+	now := kmetav1.Now()
+	info := map[string]string{
+		"used_memory":               "2048",
+		"maxmemory":                 "4096",
+		"connected_clients":         "12",
+		"instantaneous_ops_per_sec": "150",
+		"master_repl_offset":        "1000",
+		"slave_repl_offset":         "900",
+		"db0":                       "keys=10,expires=2,avg_ttl=0",
+		"db1":                       "keys=5,expires=0,avg_ttl=0",
+	}
+	want := &rapi.RedisNodeStats{UsedMemory: 2048, MaxMemory: 4096, Keys: 15, ReplicationOffset: 900, ConnectedClients: 12, OpsPerSec: 150, LastUpdateTime: now}
+	if got := decodeNodeStats(info, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeNodeStats() = %v, want %v", got, want)
+	}
+}
+
+func Test_setNodeStats(t *testing.T) {
+	fakeAdmin := admin.NewFakeAdmin()
+	fakeAdmin.GetInfoRet["10.0.0.1:6379"] = map[string]string{"master_repl_offset": "1000", "db0": "keys=10,expires=0,avg_ttl=0"}
+	fakeAdmin.GetInfoRet["10.0.0.2:6379"] = map[string]string{"master_repl_offset": "990", "slave_repl_offset": "990"}
+	now := kmetav1.Now()
+	ctx := context.Background()
+	newNodes := func() []rapi.RedisClusterNode {
+		return []rapi.RedisClusterNode{
+			{ID: "primary", Role: rapi.RedisClusterNodeRolePrimary, IP: "10.0.0.1", Port: "6379"},
+			{ID: "replica", Role: rapi.RedisClusterNodeRoleReplica, IP: "10.0.0.2", Port: "6379", PrimaryRef: "primary"},
+		}
+	}
+
+	nodes := newNodes()
+	setNodeStats(ctx, fakeAdmin, nodes, nil, now)
+	if nodes[0].Stats == nil || nodes[0].Stats.Keys != 10 || nodes[1].Stats == nil || nodes[1].Stats.ReplicationLag != 10 {
+		t.Fatalf("setNodeStats() should collect the statistics, got %v, %v", nodes[0].Stats, nodes[1].Stats)
+	}
+
+	// up to date statistics are kept
+	fakeAdmin.GetInfoRet["10.0.0.1:6379"] = map[string]string{"db0": "keys=20,expires=0,avg_ttl=0"}
+	previous := nodes
+	nodes = newNodes()
+	setNodeStats(ctx, fakeAdmin, nodes, previous, kmetav1.NewTime(now.Add(time.Second)))
+	if nodes[0].Stats != previous[0].Stats {
+		t.Errorf("setNodeStats() should keep up to date statistics, got %v", nodes[0].Stats)
+	}
+
+	nodes = newNodes()
+	setNodeStats(ctx, fakeAdmin, nodes, previous, kmetav1.NewTime(now.Add(nodeStatsRefreshInterval)))
+	if nodes[0].Stats.Keys != 20 {
+		t.Errorf("setNodeStats() should refresh outdated statistics, got %v", nodes[0].Stats)
+	}
+
+	// the statistics of an unreachable node are kept
+	fakeAdmin.AddrError["10.0.0.2:6379"] = errors.New("unreachable")
+	refreshed := nodes
+	nodes = newNodes()
+	setNodeStats(ctx, fakeAdmin, nodes, refreshed, kmetav1.NewTime(now.Add(2*nodeStatsRefreshInterval)))
+	if nodes[0].Stats.LastUpdateTime == refreshed[0].Stats.LastUpdateTime || nodes[1].Stats != refreshed[1].Stats {
+		t.Errorf("setNodeStats() should keep the statistics of an unreachable node, got %v, %v", nodes[0].Stats, nodes[1].Stats)
+	}
+}
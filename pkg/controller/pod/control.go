@@ -16,9 +16,25 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/metrics"
 	"github.com/golang/glog"
 )
 
+const (
+	// RedisUsernameEnv environment variable holding the redis username in the redis-node container
+	RedisUsernameEnv = "REDIS_USERNAME"
+	// RedisPasswordEnv environment variable holding the redis password in the redis-node container
+	RedisPasswordEnv = "REDIS_PASSWORD"
+	// RedisTLSDirEnv environment variable holding the tls certificates folder in the redis-node container
+	RedisTLSDirEnv = "REDIS_TLS_DIR"
+	// TLSVolumeName name of the volume holding the tls certificates
+	TLSVolumeName = "redis-tls"
+	// TLSMountPath mount path of the tls certificates in the redis-node container
+	TLSMountPath = "/redis-tls"
+	// RedisNodeContainerName name of the container running the redis-server process
+	RedisNodeContainerName = rapi.RedisNodeContainerName
+)
+
 // RedisClusterControlInterface interface for the RedisClusterPodControl
 type RedisClusterControlInterface interface {
 	// GetRedisClusterPods return list of Pod attached to a RedisCluster
@@ -27,10 +43,14 @@ type RedisClusterControlInterface interface {
 	CreatePod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error)
 	// CreatePodOnNode used to create a Pod on the given node
 	CreatePodOnNode(redisCluster *rapi.RedisCluster, nodeName string) (*kapiv1.Pod, error)
-	// DeletePod used to delete a pod from its name
+	// GetRedisClusterClaims return list of PersistentVolumeClaim attached to a RedisCluster
+	GetRedisClusterClaims(redisCluster *rapi.RedisCluster) ([]kapiv1.PersistentVolumeClaim, error)
+	// DeletePod used to delete a pod from its name, and its persistent volume claim
 	DeletePod(redisCluster *rapi.RedisCluster, podName string) error
 	// DeletePodNow used to delete now (force) a pod from its name
 	DeletePodNow(redisCluster *rapi.RedisCluster, podName string) error
+	// DeletePodKeepClaim used to delete a pod from its name in its grace period, without its persistent volume claim
+	DeletePodKeepClaim(redisCluster *rapi.RedisCluster, podName string) error
 }
 
 var _ RedisClusterControlInterface = &RedisClusterControl{}
@@ -69,10 +89,14 @@ func (p *RedisClusterControl) CreatePod(redisCluster *rapi.RedisCluster) (*kapiv
 	if err != nil {
 		return pod, err
 	}
+	if err = p.bindClaim(redisCluster, pod, true); err != nil {
+		return nil, err
+	}
 	glog.V(6).Infof("CreatePod: %s/%s", redisCluster.Namespace, pod.Name)
 	if err = p.KubeClient.Create(context.Background(), pod); err != nil {
 		return nil, err
 	}
+	metrics.RecordPodCreated(redisCluster)
 	return pod, nil
 }
 
@@ -83,26 +107,51 @@ func (p *RedisClusterControl) CreatePodOnNode(redisCluster *rapi.RedisCluster, n
 		return pod, err
 	}
 	pod.Spec.NodeName = nodeName
+	// a claim left by a previous pod may not be attachable to the given node
+	if err = p.bindClaim(redisCluster, pod, false); err != nil {
+		return nil, err
+	}
 	glog.V(6).Infof("CreatePodOnNode: %s/%s", redisCluster.Namespace, pod.Name)
 	if err = p.KubeClient.Create(context.Background(), pod); err != nil {
 		return nil, err
 	}
+	metrics.RecordPodCreated(redisCluster)
 	return pod, nil
 }
 
 // DeletePod used to delete a pod
+// the redis node is removed from the cluster, so its persistent volume claim is deleted as well
 func (p *RedisClusterControl) DeletePod(redisCluster *rapi.RedisCluster, podName string) error {
 	glog.V(6).Infof("DeletePod: %s/%s", redisCluster.Namespace, podName)
-	return p.deletePodGracePeriod(redisCluster, podName, nil)
+	if !IsStorageEnabled(redisCluster) {
+		return p.deletePodGracePeriod(redisCluster, podName, nil)
+	}
+	pod, err := p.getPod(redisCluster, podName)
+	if err != nil {
+		return err
+	}
+	if err = p.deletePodGracePeriod(redisCluster, podName, nil); err != nil {
+		return err
+	}
+	return p.deleteClaim(redisCluster, pod)
 }
 
 // DeletePodNow used to for delete a pod now
+// the persistent volume claim is kept, so that the next pod restarts the redis node with its data
 func (p *RedisClusterControl) DeletePodNow(redisCluster *rapi.RedisCluster, podName string) error {
 	glog.V(6).Infof("DeletePod: %s/%s", redisCluster.Namespace, podName)
 	now := int64(0)
 	return p.deletePodGracePeriod(redisCluster, podName, &now)
 }
 
+// DeletePodKeepClaim used to delete a pod in its grace period
+// the persistent volume claim is kept, and remains bound to the pod until the kubelet stops its containers and
+// releases its volumes, so that the next pod does not write in the data folder at the same time
+func (p *RedisClusterControl) DeletePodKeepClaim(redisCluster *rapi.RedisCluster, podName string) error {
+	glog.V(6).Infof("DeletePodKeepClaim: %s/%s", redisCluster.Namespace, podName)
+	return p.deletePodGracePeriod(redisCluster, podName, nil)
+}
+
 // deletePodGracePeriod used to delete a pod in a given grace period
 func (p *RedisClusterControl) deletePodGracePeriod(redisCluster *rapi.RedisCluster, podName string, period *int64) error {
 	pod := &kapiv1.Pod{
@@ -116,7 +165,11 @@ func (p *RedisClusterControl) deletePodGracePeriod(redisCluster *rapi.RedisClust
 	if period != nil {
 		deleteOptions = append(deleteOptions, client.GracePeriodSeconds(*period))
 	}
-	return p.KubeClient.Delete(context.Background(), pod, deleteOptions...)
+	if err := p.KubeClient.Delete(context.Background(), pod, deleteOptions...); err != nil {
+		return err
+	}
+	metrics.RecordPodDeleted(redisCluster)
+	return nil
 }
 
 func initPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
@@ -146,17 +199,91 @@ func initPod(redisCluster *rapi.RedisCluster) (*kapiv1.Pod, error) {
 	if redisCluster.Spec.PodTemplate == nil {
 		return nil, fmt.Errorf("rediscluster[%s/%s] PodTemplate missing", redisCluster.Namespace, redisCluster.Name)
 	}
+	for k, v := range redisCluster.Spec.PodTemplate.Annotations {
+		pod.Annotations[k] = v
+	}
 	pod.Spec = *redisCluster.Spec.PodTemplate.Spec.DeepCopy()
+	// credentials and certificates are not part of the PodTemplate
+	setAuthEnv(redisCluster, &pod.Spec)
+	setTLSVolume(redisCluster, &pod.Spec)
 
-	// Generate a MD5 representing the PodSpec send
-	hash, err := GenerateMD5Spec(&pod.Spec)
+	// Generate a MD5 representing the PodSpec send, and the redis settings applied at startup
+	hash, err := GeneratePodHash(redisCluster)
 	if err != nil {
 		return nil, err
 	}
 	pod.Annotations[rapi.PodSpecMD5LabelKey] = hash
+	// the labels and annotations of the PodTemplate are hashed apart: they are patched on the running pods
+	metadataHash, err := GenerateMD5Metadata(&redisCluster.Spec.PodTemplate.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	pod.Annotations[rapi.PodMetadataMD5AnnotationKey] = metadataHash
 	return pod, nil
 }
 
+// setAuthEnv exposes the auth secret to the redis-node container through environment variables
+func setAuthEnv(redisCluster *rapi.RedisCluster, spec *kapiv1.PodSpec) {
+	if redisCluster.Spec.Auth == nil || redisCluster.Spec.Auth.SecretName == "" {
+		return
+	}
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != RedisNodeContainerName {
+			continue
+		}
+		spec.Containers[i].Env = append(spec.Containers[i].Env,
+			kapiv1.EnvVar{
+				Name:      RedisUsernameEnv,
+				ValueFrom: secretKeyRef(redisCluster.Spec.Auth.SecretName, rapi.AuthUsernameKey, true),
+			},
+			kapiv1.EnvVar{
+				Name:      RedisPasswordEnv,
+				ValueFrom: secretKeyRef(redisCluster.Spec.Auth.SecretName, rapi.AuthPasswordKey, false),
+			},
+		)
+	}
+}
+
+// setTLSVolume mounts the tls secret in the redis-node container
+// the secret is not mounted with a subPath, so that the kubelet propagates a certificate rotation
+func setTLSVolume(redisCluster *rapi.RedisCluster, spec *kapiv1.PodSpec) {
+	if redisCluster.Spec.TLS == nil || redisCluster.Spec.TLS.SecretName == "" {
+		return
+	}
+	spec.Volumes = append(spec.Volumes, kapiv1.Volume{
+		Name: TLSVolumeName,
+		VolumeSource: kapiv1.VolumeSource{
+			Secret: &kapiv1.SecretVolumeSource{
+				SecretName: redisCluster.Spec.TLS.SecretName,
+			},
+		},
+	})
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != RedisNodeContainerName {
+			continue
+		}
+		spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, kapiv1.VolumeMount{
+			Name:      TLSVolumeName,
+			MountPath: TLSMountPath,
+			ReadOnly:  true,
+		})
+		spec.Containers[i].Env = append(spec.Containers[i].Env, kapiv1.EnvVar{
+			Name:  RedisTLSDirEnv,
+			Value: TLSMountPath,
+		})
+	}
+}
+
+func secretKeyRef(secretName, key string, optional bool) *kapiv1.EnvVarSource {
+	return &kapiv1.EnvVarSource{
+		SecretKeyRef: &kapiv1.SecretKeySelector{
+			LocalObjectReference: kapiv1.LocalObjectReference{Name: secretName},
+			Key:                  key,
+			Optional:             boolPtr(optional),
+		},
+	}
+}
+
 // GenerateMD5Spec used to generate the PodSpec MD5 hash
 func GenerateMD5Spec(spec *kapiv1.PodSpec) (string, error) {
 	b, err := json.Marshal(spec)
@@ -170,6 +297,36 @@ func GenerateMD5Spec(spec *kapiv1.PodSpec) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// GenerateMD5Metadata used to generate the MD5 hash of the labels and annotations of the PodTemplate
+func GenerateMD5Metadata(meta *metav1.ObjectMeta) (string, error) {
+	b, err := json.Marshal(struct {
+		Labels      map[string]string `json:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}{meta.Labels, meta.Annotations})
+	if err != nil {
+		return "", err
+	}
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GeneratePodHash used to generate the hash of the pods of a RedisCluster: the MD5 hash of the PodSpec with the
+// credentials and certificates secrets, combined with the hash of the redis settings that require a restart once
+// they differ from the baseline recorded in the status
+func GeneratePodHash(redisCluster *rapi.RedisCluster) (string, error) {
+	spec := redisCluster.Spec.PodTemplate.Spec.DeepCopy()
+	// the PodSpec is unchanged without auth nor TLS, so the hash of the pods of those clusters is kept
+	setAuthEnv(redisCluster, spec)
+	setTLSVolume(redisCluster, spec)
+	hash, err := GenerateMD5Spec(spec)
+	config := redisCluster.Status.Config
+	if err != nil || config == nil || config.RestartHash == config.BaselineRestartHash {
+		return hash, err
+	}
+	sum := md5.Sum([]byte(hash + redisCluster.Status.Config.RestartHash))
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // BuildOwnerReference used to build the OwnerReference from a RedisCluster
 func BuildOwnerReference(cluster *rapi.RedisCluster) metav1.OwnerReference {
 	controllerRef := metav1.OwnerReference{
@@ -13,6 +13,30 @@ import (
 
 func Test_initPod(t *testing.T) {
 	emptyPodSpecMD5, _ := GenerateMD5Spec(&kapiv1.PodSpec{})
+	redisNodeSpec := kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node"}}}
+	authSpec := kapiv1.PodSpec{Containers: []kapiv1.Container{{
+		Name: "redis-node",
+		Env: []kapiv1.EnvVar{
+			{Name: RedisUsernameEnv, ValueFrom: secretKeyRef("redis-auth", rapi.AuthUsernameKey, true)},
+			{Name: RedisPasswordEnv, ValueFrom: secretKeyRef("redis-auth", rapi.AuthPasswordKey, false)},
+		},
+	}}}
+	authSpecMD5, _ := GenerateMD5Spec(&authSpec)
+	tlsSpec := kapiv1.PodSpec{
+		Volumes: []kapiv1.Volume{{
+			Name:         TLSVolumeName,
+			VolumeSource: kapiv1.VolumeSource{Secret: &kapiv1.SecretVolumeSource{SecretName: "redis-tls"}},
+		}},
+		Containers: []kapiv1.Container{{
+			Name:         "redis-node",
+			VolumeMounts: []kapiv1.VolumeMount{{Name: TLSVolumeName, MountPath: TLSMountPath, ReadOnly: true}},
+			Env:          []kapiv1.EnvVar{{Name: RedisTLSDirEnv, Value: TLSMountPath}},
+		}},
+	}
+	tlsSpecMD5, _ := GenerateMD5Spec(&tlsSpec)
+	emptyMetadataMD5, _ := GenerateMD5Metadata(&metav1.ObjectMeta{})
+	templateMetadata := metav1.ObjectMeta{Labels: map[string]string{"team": "cache"}, Annotations: map[string]string{"prometheus.io/scrape": "true"}}
+	templateMetadataMD5, _ := GenerateMD5Metadata(&templateMetadata)
 
 	type args struct {
 		redisCluster *rapi.RedisCluster
@@ -55,8 +79,103 @@ func Test_initPod(t *testing.T) {
 						Controller: boolPtr(true),
 					}},
 					Labels:      map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
-					Annotations: map[string]string{rapi.PodSpecMD5LabelKey: string(emptyPodSpecMD5)},
+					Annotations: map[string]string{rapi.PodSpecMD5LabelKey: string(emptyPodSpecMD5), rapi.PodMetadataMD5AnnotationKey: emptyMetadataMD5},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "template metadata",
+			args: args{
+				redisCluster: &rapi.RedisCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testcluster",
+						Namespace: "foo",
+					},
+					Spec: rapi.RedisClusterSpec{
+						PodTemplate: &kapiv1.PodTemplateSpec{ObjectMeta: templateMetadata},
+					},
+				},
+			},
+			want: &kapiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "rediscluster-testcluster-",
+					Namespace:    "foo",
+					OwnerReferences: []metav1.OwnerReference{{
+						Name:       "testcluster",
+						APIVersion: rapi.GroupVersion.String(),
+						Kind:       rapi.ResourceKind,
+						Controller: boolPtr(true),
+					}},
+					Labels: map[string]string{rapi.ClusterNameLabelKey: "testcluster", "team": "cache"},
+					Annotations: map[string]string{
+						rapi.PodSpecMD5LabelKey:          string(emptyPodSpecMD5),
+						rapi.PodMetadataMD5AnnotationKey: templateMetadataMD5,
+						"prometheus.io/scrape":           "true",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "auth spec",
+			args: args{
+				redisCluster: &rapi.RedisCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testcluster",
+						Namespace: "foo",
+					},
+					Spec: rapi.RedisClusterSpec{
+						PodTemplate: &kapiv1.PodTemplateSpec{Spec: redisNodeSpec},
+						Auth:        &rapi.RedisAuth{SecretName: "redis-auth"},
+					},
+				},
+			},
+			want: &kapiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "rediscluster-testcluster-",
+					Namespace:    "foo",
+					OwnerReferences: []metav1.OwnerReference{{
+						Name:       "testcluster",
+						APIVersion: rapi.GroupVersion.String(),
+						Kind:       rapi.ResourceKind,
+						Controller: boolPtr(true),
+					}},
+					Labels:      map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
+					Annotations: map[string]string{rapi.PodSpecMD5LabelKey: string(authSpecMD5), rapi.PodMetadataMD5AnnotationKey: emptyMetadataMD5},
 				},
+				Spec: authSpec,
+			},
+			wantErr: false,
+		},
+		{
+			name: "tls spec",
+			args: args{
+				redisCluster: &rapi.RedisCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testcluster",
+						Namespace: "foo",
+					},
+					Spec: rapi.RedisClusterSpec{
+						PodTemplate: &kapiv1.PodTemplateSpec{Spec: redisNodeSpec},
+						TLS:         &rapi.RedisTLS{SecretName: "redis-tls"},
+					},
+				},
+			},
+			want: &kapiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "rediscluster-testcluster-",
+					Namespace:    "foo",
+					OwnerReferences: []metav1.OwnerReference{{
+						Name:       "testcluster",
+						APIVersion: rapi.GroupVersion.String(),
+						Kind:       rapi.ResourceKind,
+						Controller: boolPtr(true),
+					}},
+					Labels:      map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
+					Annotations: map[string]string{rapi.PodSpecMD5LabelKey: string(tlsSpecMD5), rapi.PodMetadataMD5AnnotationKey: emptyMetadataMD5},
+				},
+				Spec: tlsSpec,
 			},
 			wantErr: false,
 		},
@@ -76,3 +195,47 @@ func Test_initPod(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratePodHash(t *testing.T) {
+	redisNodeSpec := kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node"}}}
+	cluster := &rapi.RedisCluster{
+		Spec: rapi.RedisClusterSpec{
+			PodTemplate: &kapiv1.PodTemplateSpec{Spec: redisNodeSpec},
+		},
+	}
+	specMD5, _ := GenerateMD5Spec(&redisNodeSpec)
+	if hash, _ := GeneratePodHash(cluster); hash != specMD5 {
+		t.Errorf("GeneratePodHash() = %s, want the PodSpec hash %s without restart config", hash, specMD5)
+	}
+
+	cluster.Status.Config = &rapi.ConfigStatus{RestartHash: "0cc175b9c0f1b6a831c399e269772661", BaselineRestartHash: "0cc175b9c0f1b6a831c399e269772661"}
+	if hash, _ := GeneratePodHash(cluster); hash != specMD5 {
+		t.Errorf("GeneratePodHash() = %s, want the PodSpec hash %s with the baseline restart config", hash, specMD5)
+	}
+	cluster.Status.Config.BaselineRestartHash = ""
+	withConfig, _ := GeneratePodHash(cluster)
+	if withConfig == specMD5 {
+		t.Errorf("GeneratePodHash() = %s, want a hash different from the PodSpec hash with restart config", withConfig)
+	}
+	cluster.Status.Config.RestartHash = "92eb5ffee6ae2fec3ad71c777531578f"
+	withNewConfig, _ := GeneratePodHash(cluster)
+	if withNewConfig == withConfig {
+		t.Errorf("GeneratePodHash() = %s, want a new hash when the restart config changes", withNewConfig)
+	}
+
+	cluster.Spec.Auth = &rapi.RedisAuth{SecretName: "redis-auth"}
+	withAuth, _ := GeneratePodHash(cluster)
+	if withAuth == withNewConfig {
+		t.Errorf("GeneratePodHash() = %s, want a new hash when auth is enabled", withAuth)
+	}
+	cluster.Spec.Auth.SecretName = "other-auth"
+	withOtherAuth, _ := GeneratePodHash(cluster)
+	if withOtherAuth == withAuth {
+		t.Errorf("GeneratePodHash() = %s, want a new hash when the auth secret changes", withOtherAuth)
+	}
+
+	cluster.Spec.TLS = &rapi.RedisTLS{SecretName: "redis-tls"}
+	if hash, _ := GeneratePodHash(cluster); hash == withOtherAuth {
+		t.Errorf("GeneratePodHash() = %s, want a new hash when TLS is enabled", hash)
+	}
+}
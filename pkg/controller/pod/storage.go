@@ -0,0 +1,245 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	kapiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+const (
+	// DataVolumeName name of the volume holding the redis data folder
+	DataVolumeName = "data"
+	// DataMountPath mount path of the redis data folder in the redis-node container
+	DataMountPath = "/redis-data"
+	// RedisPersistentDataEnv environment variable set in the redis-node container when the data folder is persistent
+	RedisPersistentDataEnv = "REDIS_PERSISTENT_DATA"
+	// claimReservationTimeout duration of the reservation of a claim for a pod: the pods listed from the cache may not
+	// contain a pod created in the last moments yet
+	claimReservationTimeout = time.Minute
+)
+
+// IsStorageEnabled returns true if the redis nodes keep their data in persistent volume claims
+func IsStorageEnabled(redisCluster *rapi.RedisCluster) bool {
+	return redisCluster.Spec.Storage != nil
+}
+
+// GetRedisClusterClaims return list of PersistentVolumeClaim attached to a RedisCluster
+func (p *RedisClusterControl) GetRedisClusterClaims(redisCluster *rapi.RedisCluster) ([]kapiv1.PersistentVolumeClaim, error) {
+	if !IsStorageEnabled(redisCluster) {
+		return nil, nil
+	}
+	selector, err := CreateRedisClusterLabelSelector(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	claimList := &kapiv1.PersistentVolumeClaimList{}
+	if err = p.KubeClient.List(context.Background(), claimList, client.InNamespace(redisCluster.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return claimList.Items, nil
+}
+
+// bindClaim binds the pod to a persistent volume claim: a claim left by a previous pod is reused,
+// so that the redis node restarts with its data and node ID. Otherwise a new claim is created.
+func (p *RedisClusterControl) bindClaim(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod, reuse bool) error {
+	if !IsStorageEnabled(redisCluster) {
+		return nil
+	}
+	var claimName string
+	if reuse {
+		var err error
+		if claimName, err = p.reserveClaim(redisCluster, pod); err != nil {
+			return err
+		}
+	}
+	if claimName == "" {
+		claim, err := initClaim(redisCluster)
+		if err != nil {
+			return err
+		}
+		if err = p.KubeClient.Create(context.Background(), claim); err != nil {
+			return err
+		}
+		claimName = claim.Name
+	} else {
+		glog.V(2).Infof("reusing persistent volume claim %s/%s", redisCluster.Namespace, claimName)
+	}
+	setDataVolume(&pod.Spec, claimName)
+	return nil
+}
+
+// reserveClaim reserves a claim left by a previous pod for the pod, and returns its name, empty if there is none.
+// The name of the pod is generated here to be stored in the claim.
+func (p *RedisClusterControl) reserveClaim(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod) (string, error) {
+	claims, err := p.GetRedisClusterClaims(redisCluster)
+	if err != nil {
+		return "", err
+	}
+	pods, err := p.GetRedisClusterPods(redisCluster)
+	if err != nil {
+		return "", err
+	}
+	if pod.Name == "" {
+		pod.Name = pod.GenerateName + utilrand.String(5)
+	}
+	now := time.Now()
+	for _, claim := range selectUnboundClaims(claims, pods, now) {
+		if claim.Annotations == nil {
+			claim.Annotations = make(map[string]string)
+		}
+		claim.Annotations[rapi.ClaimPodAnnotationKey] = pod.Name
+		claim.Annotations[rapi.ClaimReservedAtAnnotationKey] = now.UTC().Format(time.RFC3339)
+		// the update is rejected if the claim changed since it was listed, e.g. when it was reserved for another pod
+		err = p.KubeClient.Update(context.Background(), claim)
+		if err == nil {
+			return claim.Name, nil
+		}
+		if !apierrors.IsConflict(err) {
+			return "", err
+		}
+		glog.V(2).Infof("persistent volume claim %s/%s changed since it was listed, not reused for pod %s", redisCluster.Namespace, claim.Name, pod.Name)
+	}
+	return "", nil
+}
+
+// deleteClaim deletes the persistent volume claim used by the pod, if any
+func (p *RedisClusterControl) deleteClaim(redisCluster *rapi.RedisCluster, pod *kapiv1.Pod) error {
+	claimName := GetClaimName(pod)
+	if claimName == "" {
+		return nil
+	}
+	glog.V(6).Infof("DeleteClaim: %s/%s", redisCluster.Namespace, claimName)
+	claim := &kapiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claimName,
+			Namespace: redisCluster.Namespace,
+		},
+	}
+	if err := p.KubeClient.Delete(context.Background(), claim); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (p *RedisClusterControl) getPod(redisCluster *rapi.RedisCluster, podName string) (*kapiv1.Pod, error) {
+	pod := &kapiv1.Pod{}
+	namespacedName := types.NamespacedName{
+		Name:      podName,
+		Namespace: redisCluster.Namespace,
+	}
+	return pod, p.KubeClient.Get(context.Background(), namespacedName, pod)
+}
+
+// GetClaimName returns the name of the persistent volume claim holding the pod data folder
+func GetClaimName(pod *kapiv1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == DataVolumeName && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
+
+// selectUnboundClaims returns the claims not used by any pod nor reserved for a pod being created, sorted by name
+func selectUnboundClaims(claims []kapiv1.PersistentVolumeClaim, pods []kapiv1.Pod, now time.Time) []*kapiv1.PersistentVolumeClaim {
+	bound := map[string]bool{}
+	for i := range pods {
+		if name := GetClaimName(&pods[i]); name != "" {
+			bound[name] = true
+		}
+	}
+	var unbound []*kapiv1.PersistentVolumeClaim
+	for i := range claims {
+		claim := &claims[i]
+		if claim.DeletionTimestamp == nil && !bound[claim.Name] && !isClaimReserved(claim, now) {
+			unbound = append(unbound, claim)
+		}
+	}
+	sort.Slice(unbound, func(i, j int) bool { return unbound[i].Name < unbound[j].Name })
+	return unbound
+}
+
+// isClaimReserved returns true if the claim was reserved for a pod less than claimReservationTimeout ago
+func isClaimReserved(claim *kapiv1.PersistentVolumeClaim, now time.Time) bool {
+	if _, ok := claim.Annotations[rapi.ClaimPodAnnotationKey]; !ok {
+		return false
+	}
+	reservedAt, err := time.Parse(time.RFC3339, claim.Annotations[rapi.ClaimReservedAtAnnotationKey])
+	return err == nil && now.Sub(reservedAt) < claimReservationTimeout
+}
+
+func initClaim(redisCluster *rapi.RedisCluster) (*kapiv1.PersistentVolumeClaim, error) {
+	desiredLabels, err := GetLabelsSet(redisCluster)
+	if err != nil {
+		return nil, err
+	}
+	template := redisCluster.Spec.Storage.VolumeClaimTemplate.DeepCopy()
+	for key, value := range template.Labels {
+		desiredLabels[key] = value
+	}
+	claim := &kapiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       redisCluster.Namespace,
+			Labels:          desiredLabels,
+			Annotations:     template.Annotations,
+			GenerateName:    fmt.Sprintf("rediscluster-%s-", redisCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{BuildOwnerReference(redisCluster)},
+		},
+		Spec: template.Spec,
+	}
+	return claim, nil
+}
+
+// setDataVolume replaces the data volume of the pod with the persistent volume claim
+func setDataVolume(spec *kapiv1.PodSpec, claimName string) {
+	volume := kapiv1.Volume{
+		Name: DataVolumeName,
+		VolumeSource: kapiv1.VolumeSource{
+			PersistentVolumeClaim: &kapiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		},
+	}
+	found := false
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == DataVolumeName {
+			spec.Volumes[i] = volume
+			found = true
+		}
+	}
+	if !found {
+		spec.Volumes = append(spec.Volumes, volume)
+	}
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != RedisNodeContainerName {
+			continue
+		}
+		mounted := false
+		for _, mount := range spec.Containers[i].VolumeMounts {
+			if mount.Name == DataVolumeName {
+				mounted = true
+			}
+		}
+		if !mounted {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, kapiv1.VolumeMount{
+				Name:      DataVolumeName,
+				MountPath: DataMountPath,
+			})
+		}
+		spec.Containers[i].Env = append(spec.Containers[i].Env, kapiv1.EnvVar{
+			Name:  RedisPersistentDataEnv,
+			Value: "true",
+		})
+	}
+}
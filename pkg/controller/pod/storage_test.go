@@ -0,0 +1,233 @@
+package pod
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+func newClaim(name string, deleted bool) kapiv1.PersistentVolumeClaim {
+	claim := kapiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "foo",
+			Labels:    map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
+		},
+	}
+	if deleted {
+		now := metav1.Now()
+		claim.DeletionTimestamp = &now
+	}
+	return claim
+}
+
+func newPodWithClaim(name, claimName string) kapiv1.Pod {
+	pod := kapiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "foo",
+			Labels:    map[string]string{rapi.ClusterNameLabelKey: "testcluster"},
+		},
+	}
+	setDataVolume(&pod.Spec, claimName)
+	return pod
+}
+
+func Test_selectUnboundClaims(t *testing.T) {
+	now := time.Now()
+	reservedClaim := func(name string, reservedAt time.Time) kapiv1.PersistentVolumeClaim {
+		claim := newClaim(name, false)
+		claim.Annotations = map[string]string{
+			rapi.ClaimPodAnnotationKey:        "pod2",
+			rapi.ClaimReservedAtAnnotationKey: reservedAt.UTC().Format(time.RFC3339),
+		}
+		return claim
+	}
+	tests := []struct {
+		name   string
+		claims []kapiv1.PersistentVolumeClaim
+		pods   []kapiv1.Pod
+		want   []string
+	}{
+		{
+			name: "no claim",
+			want: nil,
+		},
+		{
+			name:   "all claims bound",
+			claims: []kapiv1.PersistentVolumeClaim{newClaim("claim1", false)},
+			pods:   []kapiv1.Pod{newPodWithClaim("pod1", "claim1")},
+			want:   nil,
+		},
+		{
+			name:   "deleted claim is not reused",
+			claims: []kapiv1.PersistentVolumeClaim{newClaim("claim1", true)},
+			want:   nil,
+		},
+		{
+			name:   "unbound claims sorted by name",
+			claims: []kapiv1.PersistentVolumeClaim{newClaim("claim3", false), newClaim("claim2", false), newClaim("claim1", false)},
+			pods:   []kapiv1.Pod{newPodWithClaim("pod1", "claim1")},
+			want:   []string{"claim2", "claim3"},
+		},
+		{
+			name:   "claim reserved for a pod not listed yet",
+			claims: []kapiv1.PersistentVolumeClaim{reservedClaim("claim1", now.Add(-time.Second)), newClaim("claim2", false)},
+			want:   []string{"claim2"},
+		},
+		{
+			name:   "expired reservation",
+			claims: []kapiv1.PersistentVolumeClaim{reservedClaim("claim1", now.Add(-2*claimReservationTimeout))},
+			want:   []string{"claim1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, claim := range selectUnboundClaims(tt.claims, tt.pods, now) {
+				got = append(got, claim.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectUnboundClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setDataVolume(t *testing.T) {
+	spec := kapiv1.PodSpec{
+		Volumes: []kapiv1.Volume{{Name: DataVolumeName, VolumeSource: kapiv1.VolumeSource{EmptyDir: &kapiv1.EmptyDirVolumeSource{}}}},
+		Containers: []kapiv1.Container{{
+			Name:         "redis-node",
+			VolumeMounts: []kapiv1.VolumeMount{{Name: DataVolumeName, MountPath: DataMountPath}},
+		}},
+	}
+	setDataVolume(&spec, "claim1")
+	if len(spec.Volumes) != 1 || spec.Volumes[0].PersistentVolumeClaim == nil || spec.Volumes[0].PersistentVolumeClaim.ClaimName != "claim1" {
+		t.Errorf("setDataVolume() expected the data volume to be replaced by the claim, got %v", spec.Volumes)
+	}
+	if len(spec.Containers[0].VolumeMounts) != 1 {
+		t.Errorf("setDataVolume() expected the existing data mount to be kept, got %v", spec.Containers[0].VolumeMounts)
+	}
+	if len(spec.Containers[0].Env) != 1 || spec.Containers[0].Env[0].Name != RedisPersistentDataEnv {
+		t.Errorf("setDataVolume() expected the %s env var, got %v", RedisPersistentDataEnv, spec.Containers[0].Env)
+	}
+}
+
+func TestRedisClusterControl_CreatePodWithStorage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rapi.AddToScheme(scheme))
+
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcluster", Namespace: "foo"},
+		Spec: rapi.RedisClusterSpec{
+			PodTemplate: &kapiv1.PodTemplateSpec{Spec: kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node"}}}},
+			Storage:     &rapi.RedisStorage{},
+		},
+	}
+	unbound := newClaim("claim2", false)
+	bound := newClaim("claim1", false)
+	pod1 := newPodWithClaim("pod1", "claim1")
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&bound, &unbound, &pod1).Build()
+	control := NewRedisClusterControl(kubeClient, nil)
+
+	pod, err := control.CreatePod(cluster)
+	if err != nil {
+		t.Fatalf("CreatePod() unexpected error: %v", err)
+	}
+	if got := GetClaimName(pod); got != "claim2" {
+		t.Errorf("CreatePod() expected the unbound claim to be reused, got %q", got)
+	}
+
+	pod, err = control.CreatePod(cluster)
+	if err != nil {
+		t.Fatalf("CreatePod() unexpected error: %v", err)
+	}
+	claimName := GetClaimName(pod)
+	if claimName == "" || claimName == "claim1" || claimName == "claim2" {
+		t.Errorf("CreatePod() expected a new claim, got %q", claimName)
+	}
+
+	if err = control.DeletePod(cluster, "pod1"); err != nil {
+		t.Fatalf("DeletePod() unexpected error: %v", err)
+	}
+	claims, _ := control.GetRedisClusterClaims(cluster)
+	for _, claim := range claims {
+		if claim.Name == "claim1" {
+			t.Errorf("DeletePod() expected the claim of the pod to be deleted")
+		}
+	}
+}
+
+// staleListClient lists the pods and claims from a cache that is not updated
+type staleListClient struct {
+	client.Client
+	claims *kapiv1.PersistentVolumeClaimList
+}
+
+func (c *staleListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	switch l := list.(type) {
+	case *kapiv1.PodList:
+		l.Items = nil
+	case *kapiv1.PersistentVolumeClaimList:
+		c.claims.DeepCopyInto(l)
+	default:
+		return c.Client.List(ctx, list, opts...)
+	}
+	return nil
+}
+
+func TestRedisClusterControl_CreatePodsReserveDistinctClaims(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rapi.AddToScheme(scheme))
+
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcluster", Namespace: "foo"},
+		Spec: rapi.RedisClusterSpec{
+			PodTemplate: &kapiv1.PodTemplateSpec{Spec: kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis-node"}}}},
+			Storage:     &rapi.RedisStorage{},
+		},
+	}
+	claim1 := newClaim("claim1", false)
+	claim2 := newClaim("claim2", false)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&claim1, &claim2).Build()
+	// the cache does not contain the pods and the reservations of the pass yet
+	staleClaims := &kapiv1.PersistentVolumeClaimList{}
+	if err := kubeClient.List(context.Background(), staleClaims); err != nil {
+		t.Fatalf("unable to list the claims: %v", err)
+	}
+	control := NewRedisClusterControl(&staleListClient{Client: kubeClient, claims: staleClaims}, nil)
+
+	// the pods are created in one pass, as the controller does when the cluster needs more pods
+	claimPods := map[string]string{}
+	for i := 0; i < 2; i++ {
+		pod, err := control.CreatePod(cluster)
+		if err != nil {
+			t.Fatalf("CreatePod() unexpected error: %v", err)
+		}
+		claimPods[GetClaimName(pod)] = pod.Name
+	}
+	for _, name := range []string{"claim1", "claim2"} {
+		claim := &kapiv1.PersistentVolumeClaim{}
+		if err := kubeClient.Get(context.Background(), types.NamespacedName{Namespace: "foo", Name: name}, claim); err != nil {
+			t.Fatalf("unable to get the claim: %v", err)
+		}
+		if podName, ok := claimPods[name]; !ok || claim.Annotations[rapi.ClaimPodAnnotationKey] != podName {
+			t.Errorf("claim %s reserved for pod %q, want a distinct pod for each claim, pods by claim: %v", name, claim.Annotations[rapi.ClaimPodAnnotationKey], claimPods)
+		}
+	}
+}
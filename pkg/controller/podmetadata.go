@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
+)
+
+// reconcilePodMetadata patches the labels and annotations of the PodTemplate on the pods created with previous ones:
+// a metadata change does not replace the pods, only a PodSpec change does. The pods are selected with the cluster name
+// label, since the selector of the redis pods contains the labels of the PodTemplate. The labels and annotations removed
+// from the PodTemplate are left on the running pods.
+func (c *Controller) reconcilePodMetadata(ctx context.Context, cluster *rapi.RedisCluster) (err error) {
+	ctx, span := tracing.Start(ctx, "Controller.reconcilePodMetadata")
+	defer func() { tracing.End(span, err) }()
+	if cluster.Spec.PodTemplate == nil {
+		return nil
+	}
+	hash, err := podctrl.GenerateMD5Metadata(&cluster.Spec.PodTemplate.ObjectMeta)
+	if err != nil {
+		return err
+	}
+	labels, err := podctrl.GetLabelsSet(cluster)
+	if err != nil {
+		return err
+	}
+	pods := &v1.PodList{}
+	if err = c.client.List(ctx, pods, kclient.InNamespace(cluster.Namespace), kclient.MatchingLabels{rapi.ClusterNameLabelKey: cluster.Name}); err != nil {
+		return err
+	}
+	var patched []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !metav1.IsControlledBy(pod, cluster) || pod.Annotations[rapi.PodMetadataMD5AnnotationKey] == hash {
+			continue
+		}
+		patch := kclient.MergeFrom(pod.DeepCopy())
+		if pod.Labels == nil {
+			pod.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			pod.Labels[k] = v
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		for k, v := range cluster.Spec.PodTemplate.Annotations {
+			pod.Annotations[k] = v
+		}
+		pod.Annotations[rapi.PodMetadataMD5AnnotationKey] = hash
+		if err = c.client.Patch(ctx, pod, patch); err != nil {
+			return err
+		}
+		glog.V(3).Infof("labels and annotations of the pod template of RedisCluster %s/%s patched on pod %s", cluster.Namespace, cluster.Name, pod.Name)
+		patched = append(patched, pod.Name)
+	}
+	if len(patched) > 0 {
+		c.recorder.Eventf(cluster, v1.EventTypeNormal, "PodMetadataUpdated", "Labels and annotations of the pod template patched on %d pods without replacing them", len(patched))
+	}
+	return nil
+}
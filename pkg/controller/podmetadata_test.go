@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+)
+
+func TestController_reconcilePodMetadata(t *testing.T) {
+	ctx := context.Background()
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster", UID: "cluster-uid"},
+		Spec: rapi.RedisClusterSpec{
+			PodTemplate: &kapiv1.PodTemplateSpec{
+				ObjectMeta: kmetav1.ObjectMeta{Labels: map[string]string{"team": "cache"}, Annotations: map[string]string{"prometheus.io/scrape": "true"}},
+			},
+		},
+	}
+	hash, err := podctrl.GenerateMD5Metadata(&cluster.Spec.PodTemplate.ObjectMeta)
+	if err != nil {
+		t.Fatalf("unable to generate the metadata hash: %v", err)
+	}
+	newPod := func(name, metadataHash string, owned bool) *kapiv1.Pod {
+		pod := &kapiv1.Pod{ObjectMeta: kmetav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        name,
+			Labels:      map[string]string{rapi.ClusterNameLabelKey: "cluster"},
+			Annotations: map[string]string{rapi.PodSpecMD5LabelKey: "spec", rapi.PodMetadataMD5AnnotationKey: metadataHash},
+		}}
+		if owned {
+			pod.OwnerReferences = []kmetav1.OwnerReference{podctrl.BuildOwnerReference(cluster)}
+		}
+		return pod
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newPod("previous", "previous", true),
+		newPod("current", hash, true),
+		newPod("foreign", "previous", false),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{client: fakeClient, recorder: recorder}
+
+	if err = c.reconcilePodMetadata(ctx, cluster); err != nil {
+		t.Fatalf("reconcilePodMetadata() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		wantTeam string
+		wantHash string
+	}{
+		{name: "previous", wantTeam: "cache", wantHash: hash},
+		{name: "current", wantHash: hash},
+		{name: "foreign", wantHash: "previous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &kapiv1.Pod{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: tt.name}, pod); err != nil {
+				t.Fatalf("unable to get the pod: %v", err)
+			}
+			if pod.Labels["team"] != tt.wantTeam || pod.Annotations[rapi.PodMetadataMD5AnnotationKey] != tt.wantHash {
+				t.Errorf("pod %s metadata = %v %v, want team label %q and metadata hash %s", tt.name, pod.Labels, pod.Annotations, tt.wantTeam, tt.wantHash)
+			}
+			if pod.Annotations[rapi.PodSpecMD5LabelKey] != "spec" {
+				t.Errorf("pod %s spec hash = %s, should not change", tt.name, pod.Annotations[rapi.PodSpecMD5LabelKey])
+			}
+		})
+	}
+	if event := <-recorder.Events; event != "Normal PodMetadataUpdated Labels and annotations of the pod template patched on 1 pods without replacing them" {
+		t.Errorf("reconcilePodMetadata() unexpected event: %s", event)
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/metrics"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+// progressUpdateInterval time between two updates of status.progress while slots are migrated
+const progressUpdateInterval = 10 * time.Second
+
+// trackProgress returns a context in which the slot migrations of the cluster operation are counted. The counters are
+// periodically written to status.progress until the returned function is called, which sets the final progress in the
+// status of the cluster.
+func (c *Controller) trackProgress(ctx context.Context, cluster *rapi.RedisCluster) (context.Context, func()) {
+	progress := &redis.MigrationProgress{}
+	base := cluster.Status.Progress.DeepCopy()
+	namespace, name := cluster.Namespace, cluster.Name
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressUpdateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if status := mergeProgress(base, progress.Snapshot(), time.Now()); status != base {
+					c.updateProgress(ctx, namespace, name, status)
+				}
+			}
+		}
+	}()
+	return redis.WithMigrationProgress(ctx, progress), func() {
+		close(done)
+		wg.Wait()
+		snapshot, now := progress.Snapshot(), time.Now()
+		cluster.Status.Progress = mergeProgress(base, snapshot, now)
+		recordMigrationMetrics(cluster, snapshot, now)
+	}
+}
+
+// recordMigrationMetrics exports the counters of the slot migrations of an operation
+func recordMigrationMetrics(cluster *rapi.RedisCluster, snapshot redis.MigrationProgressSnapshot, now time.Time) {
+	var duration time.Duration
+	if !snapshot.StartTime.IsZero() {
+		duration = now.Sub(snapshot.StartTime)
+	}
+	metrics.RecordSlotMigrations(cluster, snapshot.SlotsPlanned, snapshot.SlotsMigrated, snapshot.SlotsFailed, snapshot.KeysMoved, duration)
+}
+
+func (c *Controller) updateProgress(ctx context.Context, namespace, name string, progress *rapi.ProgressStatus) {
+	cluster, err := c.getRedisCluster(ctx, namespace, name)
+	if err != nil {
+		glog.Warningf("unable to get RedisCluster %s/%s to update its progress: %v", namespace, name, err)
+		return
+	}
+	cluster.Status.Progress = progress
+	if err = c.client.Status().Update(ctx, cluster); err != nil {
+		glog.Warningf("unable to update the progress of RedisCluster %s/%s: %v", namespace, name, err)
+	}
+}
+
+// mergeProgress adds the counters of the migrations to the progress of the current cluster operation, a completed
+// progress is replaced. The progress is returned unchanged when no slot migration occurred.
+func mergeProgress(progress *rapi.ProgressStatus, snapshot redis.MigrationProgressSnapshot, now time.Time) *rapi.ProgressStatus {
+	if snapshot.SlotsPlanned == 0 && snapshot.SlotsMigrated == 0 && snapshot.SlotsFailed == 0 {
+		return progress
+	}
+	var merged *rapi.ProgressStatus
+	if progress == nil || progress.CompletionTime != nil {
+		startTime := snapshot.StartTime
+		if startTime.IsZero() {
+			startTime = now
+		}
+		merged = &rapi.ProgressStatus{StartTime: &metav1.Time{Time: startTime}}
+	} else {
+		merged = progress.DeepCopy()
+	}
+	merged.SlotsPlanned += snapshot.SlotsPlanned
+	merged.SlotsMigrated += snapshot.SlotsMigrated
+	merged.SlotsFailed += snapshot.SlotsFailed
+	merged.KeysMoved += snapshot.KeysMoved
+
+	merged.EstimatedCompletionTime = nil
+	processed := merged.SlotsMigrated + merged.SlotsFailed
+	if processed > 0 && processed < merged.SlotsPlanned && merged.StartTime != nil {
+		elapsed := now.Sub(merged.StartTime.Time)
+		remaining := time.Duration(float64(elapsed) * float64(merged.SlotsPlanned-processed) / float64(processed))
+		merged.EstimatedCompletionTime = &metav1.Time{Time: now.Add(remaining)}
+	}
+	return merged
+}
+
+// completeOperation records that the spec is fully applied: the progress of the operation is completed, and the
+// generation of the spec is observed
+func completeOperation(cluster *rapi.RedisCluster, now metav1.Time) {
+	if progress := cluster.Status.Progress; progress != nil && progress.CompletionTime == nil {
+		progress.CompletionTime = &now
+		progress.EstimatedCompletionTime = nil
+	}
+	cluster.Status.ObservedGeneration = cluster.Generation
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+func Test_mergeProgress(t *testing.T) {
+	start := time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Minute)
+
+	if got := mergeProgress(nil, redis.MigrationProgressSnapshot{}, now); got != nil {
+		t.Errorf("mergeProgress() = %v, want nil without slot migration", got)
+	}
+
+	got := mergeProgress(nil, redis.MigrationProgressSnapshot{StartTime: start, SlotsPlanned: 100, SlotsMigrated: 20, SlotsFailed: 5, KeysMoved: 1000}, now)
+	if got.SlotsPlanned != 100 || got.SlotsMigrated != 20 || got.SlotsFailed != 5 || got.KeysMoved != 1000 || !got.StartTime.Time.Equal(start) {
+		t.Fatalf("mergeProgress() unexpected progress: %v", got)
+	}
+	// 25 slots processed in 10 minutes, 75 slots left
+	if want := now.Add(30 * time.Minute); got.EstimatedCompletionTime == nil || !got.EstimatedCompletionTime.Time.Equal(want) {
+		t.Errorf("mergeProgress() estimated completion = %v, want %v", got.EstimatedCompletionTime, want)
+	}
+
+	// the next reconciliation of the same operation adds its migrations
+	got = mergeProgress(got, redis.MigrationProgressSnapshot{StartTime: now, SlotsMigrated: 75}, now)
+	if got.SlotsMigrated != 95 || !got.StartTime.Time.Equal(start) || got.EstimatedCompletionTime != nil {
+		t.Errorf("mergeProgress() unexpected progress: %v", got)
+	}
+
+	// a completed operation is replaced by the next one
+	completed := kmetav1.NewTime(now)
+	got.CompletionTime = &completed
+	got = mergeProgress(got, redis.MigrationProgressSnapshot{StartTime: now, SlotsPlanned: 10}, now)
+	if got.SlotsPlanned != 10 || got.SlotsMigrated != 0 || got.CompletionTime != nil || !got.StartTime.Time.Equal(now) {
+		t.Errorf("mergeProgress() should start a new progress, got %v", got)
+	}
+}
+
+func Test_completeOperation(t *testing.T) {
+	estimated := kmetav1.Now()
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Generation: 4},
+		Status:     rapi.RedisClusterStatus{Progress: &rapi.ProgressStatus{SlotsPlanned: 10, SlotsMigrated: 10, EstimatedCompletionTime: &estimated}},
+	}
+	now := kmetav1.Now()
+	completeOperation(cluster, now)
+	if cluster.Status.ObservedGeneration != 4 {
+		t.Errorf("completeOperation() observedGeneration = %d, want 4", cluster.Status.ObservedGeneration)
+	}
+	if progress := cluster.Status.Progress; progress.CompletionTime == nil || !progress.CompletionTime.Equal(&now) || progress.EstimatedCompletionTime != nil {
+		t.Errorf("completeOperation() unexpected progress: %v", progress)
+	}
+}
@@ -0,0 +1,368 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/backup"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/clustering"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+const (
+	// restoreBatchesPerReconcile number of key batches loaded per reconcile: a RDB file is loaded across reconciles,
+	// so that the status reports the progress and a failure only replays the keys of the last reconcile
+	restoreBatchesPerReconcile = 10
+	// maxRestoreRetries number of consecutive failed attempts after which the restore fails
+	maxRestoreRetries = 10
+)
+
+// restoreCursor reads the RDB file of a shard across reconciles
+type restoreCursor struct {
+	file     string
+	body     io.ReadCloser
+	reader   *backup.RDBReader
+	hash     hash.Hash
+	keysRead int64
+}
+
+// restoreCursors contains the RDB files being restored, by RedisCluster UID
+type restoreCursors struct {
+	sync.Mutex
+	cursors map[types.UID]*restoreCursor
+}
+
+// get returns the cursor of the cluster if it reads the file and has read keysRead keys, nil otherwise
+func (r *restoreCursors) get(uid types.UID, file string, keysRead int64) *restoreCursor {
+	r.Lock()
+	defer r.Unlock()
+	if cursor, ok := r.cursors[uid]; ok && cursor.file == file && cursor.keysRead == keysRead {
+		return cursor
+	}
+	return nil
+}
+
+// set replaces the cursor of the cluster
+func (r *restoreCursors) set(uid types.UID, cursor *restoreCursor) {
+	r.Lock()
+	defer r.Unlock()
+	if previous, ok := r.cursors[uid]; ok {
+		previous.body.Close()
+	}
+	if r.cursors == nil {
+		r.cursors = make(map[types.UID]*restoreCursor)
+	}
+	r.cursors[uid] = cursor
+}
+
+// close closes the cursor of the cluster, if any
+func (r *restoreCursors) close(uid types.UID) {
+	r.Lock()
+	defer r.Unlock()
+	if cursor, ok := r.cursors[uid]; ok {
+		cursor.body.Close()
+		delete(r.cursors, uid)
+	}
+}
+
+// needRestore returns true while the backup set in RestoreFrom is not restored
+func needRestore(cluster *rapi.RedisCluster) bool {
+	if cluster.Spec.RestoreFrom == nil {
+		return false
+	}
+	return cluster.Status.Restore == nil || cluster.Status.Restore.Phase == rapi.RestorePhaseRunning
+}
+
+func (c *Controller) needsRestore(cluster *rapi.RedisCluster) bool {
+	needsRestore := needRestore(cluster)
+	if setRestoringCondition(&cluster.Status, needsRestore) {
+		cluster.Status.Cluster.Status = rapi.ClusterStatusRestoring
+	}
+	return needsRestore
+}
+
+// restoreCluster loads the backup set in RestoreFrom in a new cluster, before its replicas are attached:
+// - the slots are dispatched to the primaries, with the slot ranges of the backup when the number of shards matches
+// - the RDB files are loaded one by one, in batches across reconciles, each key is restored on the primary owning its slot
+//
+// Keys are restored one by one even when each primary gets the slot ranges of one shard: redis-server only loads an
+// RDB file when it starts, so loading it directly would require writing the file in the pod and restarting the node
+// while keeping its cluster state. RESTORE only needs the connection to the primaries, resumes after a failure from
+// the keys already read, and also applies when the shards and the primaries do not match.
+func (c *Controller) restoreCluster(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, rCluster *redis.Cluster, nodes redis.Nodes) (ctrl.Result, error) {
+	currentPrimaries, candidatePrimaries, allPrimaries := getPrimaries(nodes)
+	status := cluster.Status.Restore
+	if status == nil {
+		// the slots of a new cluster are all assigned to its first node, and it has no replicas yet
+		if len(currentPrimaries) > 1 || len(nodes.FilterByFunc(redis.IsReplica)) > 0 || hasCondition(&cluster.Status, rapi.RedisClusterOK) {
+			cluster.Status.Restore = &rapi.RestoreStatus{}
+			return c.restoreFailed(cluster, "restoreFrom only applies when the cluster is created")
+		}
+		now := metav1.Now()
+		cluster.Status.Restore = &rapi.RestoreStatus{Phase: rapi.RestorePhaseRunning, StartTime: &now}
+		c.recorder.Event(cluster, v1.EventTypeNormal, "RestoreStarted", "Restoring the cluster from a backup")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	storage, folder, err := c.getRestoreSource(ctx, cluster)
+	if err != nil {
+		return c.restoreError(cluster, err)
+	}
+	manifest, err := backup.ReadManifest(ctx, storage, path.Join(folder, backup.ManifestFileName))
+	if err != nil {
+		return c.restoreError(cluster, err)
+	}
+	status.Shards = int32(len(manifest.Shards))
+
+	nbPrimaries := *cluster.Spec.NumberOfPrimaries
+	if len(currentPrimaries) != int(nbPrimaries) {
+		newPrimaries, err := clustering.SelectPrimaries(rCluster, currentPrimaries, candidatePrimaries, nbPrimaries)
+		if err != nil {
+			return c.restoreError(cluster, fmt.Errorf("unable to select primaries: %v", err))
+		}
+		if len(newPrimaries) == len(manifest.Shards) {
+			err = dispatchBackupSlots(ctx, admin, cluster, manifest, currentPrimaries, newPrimaries, allPrimaries)
+		} else {
+			glog.Infof("backup has %d shards and the cluster %d primaries, dispatching the slots evenly", len(manifest.Shards), len(newPrimaries))
+			err = clustering.DispatchSlotsToNewPrimaries(ctx, admin, cluster, rCluster, newPrimaries, currentPrimaries, allPrimaries, true)
+		}
+		if err != nil {
+			return c.restoreError(cluster, fmt.Errorf("unable to dispatch slots: %v", err))
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if int(status.ShardsRestored) < len(manifest.Shards) {
+		shard := manifest.Shards[status.ShardsRestored]
+		file := path.Join(folder, path.Base(shard.File))
+		cursor := c.restores.get(cluster.UID, file, status.ShardKeysRead)
+		if cursor == nil {
+			if cursor, err = openRestoreCursor(storage, file, status.ShardKeysRead); err != nil {
+				return c.restoreError(cluster, fmt.Errorf("unable to read %s: %v", shard.File, err))
+			}
+			c.restores.set(cluster.UID, cursor)
+		}
+		batchSize := int(*cluster.Spec.Scaling.KeyBatchSize)
+		nbKeys, done, err := restoreKeys(ctx, admin, cursor, getSlotOwners(currentPrimaries), batchSize, int64(restoreBatchesPerReconcile*batchSize))
+		if err != nil {
+			// the keys read since the last reconcile are restored again by a new cursor
+			c.restores.close(cluster.UID)
+			return c.restoreError(cluster, fmt.Errorf("unable to restore %s: %v", shard.File, err))
+		}
+		status.KeysRestored += nbKeys
+		status.ShardKeysRead = cursor.keysRead
+		status.Retries = 0
+		status.Message = ""
+		if !done {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		c.restores.close(cluster.UID)
+		if sum := hex.EncodeToString(cursor.hash.Sum(nil)); shard.Checksum != "" && sum != shard.Checksum {
+			return c.restoreFailed(cluster, fmt.Sprintf("checksum mismatch of %s, expected %s got %s", shard.File, shard.Checksum, sum))
+		}
+		glog.Infof("restored the keys of %s in RedisCluster %s/%s", shard.File, cluster.Namespace, cluster.Name)
+		status.ShardsRestored++
+		status.ShardKeysRead = 0
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	now := metav1.Now()
+	status.Phase = rapi.RestorePhaseCompleted
+	status.CompletionTime = &now
+	status.Message = ""
+	c.recorder.Event(cluster, v1.EventTypeNormal, "RestoreCompleted", fmt.Sprintf("Restored %d keys from %d shards", status.KeysRestored, status.ShardsRestored))
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// restoreError records the error in the restore status, the restore is retried after a delay until it fails
+// maxRestoreRetries times in a row
+func (c *Controller) restoreError(cluster *rapi.RedisCluster, err error) (ctrl.Result, error) {
+	glog.Errorf("unable to restore RedisCluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+	status := cluster.Status.Restore
+	status.Retries++
+	if status.Retries >= maxRestoreRetries {
+		return c.restoreFailed(cluster, fmt.Sprintf("%v, giving up after %d attempts", err, status.Retries))
+	}
+	status.Message = err.Error()
+	c.recorder.Event(cluster, v1.EventTypeWarning, "RestoreError", err.Error())
+	return ctrl.Result{RequeueAfter: requeueDelay}, nil
+}
+
+// restoreFailed sets the terminal Failed phase: the restore is not retried, and the cluster operations resume
+func (c *Controller) restoreFailed(cluster *rapi.RedisCluster, message string) (ctrl.Result, error) {
+	c.restores.close(cluster.UID)
+	now := metav1.Now()
+	status := cluster.Status.Restore
+	status.Phase = rapi.RestorePhaseFailed
+	status.CompletionTime = &now
+	status.Message = message
+	c.recorder.Event(cluster, v1.EventTypeWarning, "RestoreFailed", message)
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// getRestoreSource returns the storage and the folder of the backup set in RestoreFrom
+func (c *Controller) getRestoreSource(ctx context.Context, cluster *rapi.RedisCluster) (backup.Storage, string, error) {
+	restore := cluster.Spec.RestoreFrom
+	if restore.BackupName == "" {
+		if restore.Storage == nil || restore.Path == "" {
+			return nil, "", errors.New("restoreFrom requires either a backup name, or a storage and a path")
+		}
+		storage, err := backup.NewStorage(ctx, c.client, cluster.Namespace, *restore.Storage)
+		return storage, restore.Path, err
+	}
+	redisClusterBackup := &rapi.RedisClusterBackup{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: restore.BackupName}, redisClusterBackup); err != nil {
+		return nil, "", err
+	}
+	if redisClusterBackup.Status.Phase != rapi.BackupPhaseCompleted {
+		return nil, "", fmt.Errorf("backup %s is not completed", restore.BackupName)
+	}
+	storage, err := backup.NewStorage(ctx, c.client, cluster.Namespace, redisClusterBackup.Spec.Storage)
+	return storage, path.Join(redisClusterBackup.Namespace, redisClusterBackup.Name), err
+}
+
+// dispatchBackupSlots assigns the slot ranges of each shard of the backup to a new primary, so that the keys
+// of a RDB file are loaded in a single primary
+func dispatchBackupSlots(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, manifest *backup.Manifest, currentPrimaries, newPrimaries, allPrimaries redis.Nodes) error {
+	owners := make(map[redis.Slot]*redis.Node)
+	for _, primary := range currentPrimaries {
+		for _, slot := range primary.Slots {
+			owners[slot] = primary
+		}
+	}
+	var errs []error
+	for i, primary := range newPrimaries {
+		slots, err := decodeSlotRanges(manifest.Shards[i].Slots)
+		if err != nil {
+			return err
+		}
+		var newSlots redis.SlotSlice
+		migratedSlots := make(map[*redis.Node]redis.SlotSlice)
+		for _, slot := range slots {
+			owner, ok := owners[slot]
+			if !ok {
+				newSlots = append(newSlots, slot)
+			} else if owner.ID != primary.ID {
+				migratedSlots[owner] = append(migratedSlots[owner], slot)
+			}
+		}
+		for owner, slots := range migratedSlots {
+			redis.MigrationProgressFrom(ctx).AddPlannedSlots(int32(len(slots)))
+			if err = admin.MigrateKeys(ctx, owner, primary, slots, &cluster.Spec, true, true, allPrimaries); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err = admin.AddSlots(ctx, primary.IPPort(), newSlots); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// openRestoreCursor opens the RDB file of the backup, and reads the keysRead first keys already restored so that
+// the checksum covers the whole file. The file stays open across reconciles, it is not bound to the reconcile context.
+func openRestoreCursor(storage backup.Storage, file string, keysRead int64) (*restoreCursor, error) {
+	body, err := storage.Read(context.Background(), file)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &restoreCursor{file: file, body: body, hash: sha256.New()}
+	if cursor.reader, err = backup.NewRDBReader(io.TeeReader(body, cursor.hash)); err != nil {
+		body.Close()
+		return nil, err
+	}
+	for cursor.keysRead < keysRead {
+		if _, err = cursor.reader.Next(); err != nil {
+			body.Close()
+			return nil, err
+		}
+		cursor.keysRead++
+	}
+	return cursor, nil
+}
+
+// restoreKeys reads up to maxKeys keys of the RDB file, and returns the number of restored keys and true once the
+// whole file is read. Keys are restored in batches on the primary owning their slot, the keys already expired are skipped.
+func restoreKeys(ctx context.Context, admin redis.AdminInterface, cursor *restoreCursor, slotOwners map[redis.Slot]string, batchSize int, maxKeys int64) (int64, bool, error) {
+	var nbKeys, read int64
+	done := false
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	batches := make(map[string][]redis.DumpEntry)
+	for read < maxKeys {
+		entry, err := cursor.reader.Next()
+		if err == io.EOF {
+			done = true
+			break
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		read++
+		if entry.ExpireAt > 0 && entry.ExpireAt <= now {
+			continue
+		}
+		slot := redis.KeySlot(entry.Key)
+		addr, ok := slotOwners[slot]
+		if !ok {
+			return 0, false, fmt.Errorf("slot %d is not assigned to a primary", slot)
+		}
+		batches[addr] = append(batches[addr], *entry)
+		if len(batches[addr]) >= batchSize {
+			if err = admin.RestoreKeys(ctx, addr, batches[addr]); err != nil {
+				return 0, false, err
+			}
+			nbKeys += int64(len(batches[addr]))
+			batches[addr] = batches[addr][:0]
+		}
+	}
+	for addr, entries := range batches {
+		if len(entries) == 0 {
+			continue
+		}
+		if err := admin.RestoreKeys(ctx, addr, entries); err != nil {
+			return 0, false, err
+		}
+		nbKeys += int64(len(entries))
+	}
+	cursor.keysRead += read
+	return nbKeys, done, nil
+}
+
+// getSlotOwners returns the address of the primary owning each slot
+func getSlotOwners(primaries redis.Nodes) map[redis.Slot]string {
+	owners := make(map[redis.Slot]string)
+	for _, primary := range primaries {
+		for _, slot := range primary.Slots {
+			owners[slot] = primary.IPPort()
+		}
+	}
+	return owners
+}
+
+func decodeSlotRanges(slotRanges []string) (redis.SlotSlice, error) {
+	var slots redis.SlotSlice
+	for _, slotRange := range slotRanges {
+		decoded, _, _, err := redis.DecodeSlotRange(slotRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot range %q: %v", slotRange, err)
+		}
+		slots = append(slots, decoded...)
+	}
+	return slots, nil
+}
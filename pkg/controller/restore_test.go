@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/backup"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func Test_restoreKeys(t *testing.T) {
+	rdb := &bytes.Buffer{}
+	rdb.WriteString("REDIS0009")
+	for _, key := range []string{"foo", "bar", "expired"} {
+		if key == "expired" {
+			rdb.Write([]byte{252, 0xe8, 0x03, 0, 0, 0, 0, 0, 0})
+		}
+		rdb.Write([]byte{0, byte(len(key))})
+		rdb.WriteString(key)
+		rdb.Write([]byte{0xc0, 1})
+	}
+	rdb.Write([]byte{255, 0, 0, 0, 0, 0, 0, 0, 0})
+	sum := sha256.Sum256(rdb.Bytes())
+	checksum := hex.EncodeToString(sum[:])
+
+	dir, err := os.MkdirTemp("", "redis-restore")
+	if err != nil {
+		t.Fatalf("unable to create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	storage := backup.NewLocalStorage(dir)
+	ctx := context.Background()
+	if _, err = storage.Write(ctx, "ns/backup/shard-0.rdb", bytes.NewReader(rdb.Bytes()), int64(rdb.Len())); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	primaries := redis.Nodes{
+		{ID: "primary1", IP: "10.0.0.1", Port: "6379", Slots: redis.BuildSlotSlice(0, 8191)},
+		{ID: "primary2", IP: "10.0.0.2", Port: "6379", Slots: redis.BuildSlotSlice(8192, 16383)},
+	}
+	fakeAdmin := admin.NewFakeAdmin()
+	cursor, err := openRestoreCursor(storage, "ns/backup/shard-0.rdb", 0)
+	if err != nil {
+		t.Fatalf("openRestoreCursor() unexpected error: %v", err)
+	}
+	// the first batch is loaded by a reconcile, the next ones by a new cursor, as after a restart of the operator
+	nbKeys, done, err := restoreKeys(ctx, fakeAdmin, cursor, getSlotOwners(primaries), 1, 1)
+	cursor.body.Close()
+	if err != nil || done || nbKeys != 1 || cursor.keysRead != 1 {
+		t.Fatalf("restoreKeys() = %d, %v, %v, want 1 key restored out of 3", nbKeys, done, err)
+	}
+	if cursor, err = openRestoreCursor(storage, "ns/backup/shard-0.rdb", cursor.keysRead); err != nil {
+		t.Fatalf("openRestoreCursor() unexpected error: %v", err)
+	}
+	defer cursor.body.Close()
+	nbKeys, done, err = restoreKeys(ctx, fakeAdmin, cursor, getSlotOwners(primaries), 1, 10)
+	if err != nil || !done || nbKeys != 1 {
+		t.Errorf("restoreKeys() = %d, %v, %v, want the last key restored and the expired key skipped", nbKeys, done, err)
+	}
+	if sum := hex.EncodeToString(cursor.hash.Sum(nil)); sum != checksum {
+		t.Errorf("restoreKeys() file checksum = %s, want %s", sum, checksum)
+	}
+	// foo is in slot 12182 and bar in slot 5061
+	for addr, key := range map[string]string{"10.0.0.1:6379": "bar", "10.0.0.2:6379": "foo"} {
+		entries := fakeAdmin.RestoredKeys[addr]
+		if len(entries) != 1 || entries[0].Key != key {
+			t.Errorf("restoreKeys() restored %v on %s, want %s", entries, addr, key)
+		}
+	}
+
+	cursor, err = openRestoreCursor(storage, "ns/backup/shard-0.rdb", 0)
+	if err != nil {
+		t.Fatalf("openRestoreCursor() unexpected error: %v", err)
+	}
+	defer cursor.body.Close()
+	if _, _, err = restoreKeys(ctx, fakeAdmin, cursor, getSlotOwners(primaries[:1]), 10, 10); err == nil {
+		t.Errorf("restoreKeys() should return an error when a slot is not assigned")
+	}
+}
+
+func TestController_restoreError(t *testing.T) {
+	cluster := &rapi.RedisCluster{Status: rapi.RedisClusterStatus{Restore: &rapi.RestoreStatus{Phase: rapi.RestorePhaseRunning}}}
+	c := &Controller{recorder: record.NewFakeRecorder(maxRestoreRetries + 1)}
+	for i := 1; i < maxRestoreRetries; i++ {
+		if result, _ := c.restoreError(cluster, errors.New("storage unavailable")); result.RequeueAfter == 0 || cluster.Status.Restore.Phase != rapi.RestorePhaseRunning {
+			t.Fatalf("restoreError() attempt %d = %v, phase %s, want a delayed retry", i, result, cluster.Status.Restore.Phase)
+		}
+	}
+	c.restoreError(cluster, errors.New("storage unavailable"))
+	if cluster.Status.Restore.Phase != rapi.RestorePhaseFailed || needRestore(cluster) {
+		t.Errorf("restoreError() phase = %s after %d attempts, want %s", cluster.Status.Restore.Phase, maxRestoreRetries, rapi.RestorePhaseFailed)
+	}
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
+	v1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
+	"github.com/IBM/operator-for-redis-cluster/pkg/utils"
+)
+
+// maxFailoverReplicationLag maximum number of bytes a new replica may be behind its primary before it replaces an old
+// replica or takes over the slots of the primary, CLUSTER FAILOVER waits for the replica to catch up the remaining bytes
+const maxFailoverReplicationLag = 1024 * 1024
+
+// failoverShard is a primary with slots and its replicas, split by pod template
+type failoverShard struct {
+	primary     *redis.Node
+	updated     bool
+	oldReplicas redis.Nodes
+	newReplicas redis.Nodes
+}
+
+// classifyFailoverShards groups the nodes by shard. It also returns the nodes running the pod template that are not
+// attached to a shard yet, and the nodes running a previous pod template that are not part of a shard.
+func classifyFailoverShards(nodes redis.Nodes, hash string) ([]*failoverShard, redis.Nodes, redis.Nodes) {
+	updated := func(n *redis.Node) bool { return n.Pod != nil && comparePodSpecMD5Hash(hash, n.Pod) }
+	shardByPrimary := make(map[string]*failoverShard)
+	var shards []*failoverShard
+	for _, node := range nodes {
+		if redis.IsPrimaryWithSlot(node) {
+			shard := &failoverShard{primary: node, updated: updated(node)}
+			shardByPrimary[node.ID] = shard
+			shards = append(shards, shard)
+		}
+	}
+	var unattached, stale redis.Nodes
+	for _, node := range nodes {
+		if node.Pod == nil || redis.IsPrimaryWithSlot(node) {
+			continue
+		}
+		shard, ok := shardByPrimary[node.PrimaryReferent]
+		switch {
+		case redis.IsReplica(node) && ok && updated(node):
+			shard.newReplicas = append(shard.newReplicas, node)
+		case redis.IsReplica(node) && ok:
+			shard.oldReplicas = append(shard.oldReplicas, node)
+		case updated(node):
+			unattached = append(unattached, node)
+		default:
+			stale = append(stale, node)
+		}
+	}
+	return shards, unattached, stale
+}
+
+// selectFailoverShards returns up to maxShards shards to update: the shards whose primary was already replaced are
+// completed first, then the shards with new replicas, so that the shards are updated a few at a time
+func selectFailoverShards(shards []*failoverShard, maxShards int32) []*failoverShard {
+	priority := func(shard *failoverShard) int {
+		switch {
+		case shard.updated:
+			return 0
+		case len(shard.newReplicas) > 0:
+			return 1
+		}
+		return 2
+	}
+	var candidates []*failoverShard
+	for _, shard := range shards {
+		if !shard.updated || len(shard.oldReplicas) > 0 {
+			candidates = append(candidates, shard)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if pi, pj := priority(candidates[i]), priority(candidates[j]); pi != pj {
+			return pi < pj
+		}
+		return candidates[i].primary.ID < candidates[j].primary.ID
+	})
+	if int32(len(candidates)) > maxShards {
+		candidates = candidates[:maxShards]
+	}
+	return candidates
+}
+
+// replicasInSync returns an error when a replica is not fully synchronized with its primary
+func replicasInSync(ctx context.Context, admin redis.AdminInterface, primary *redis.Node, replicas redis.Nodes) error {
+	if len(replicas) == 0 {
+		return nil
+	}
+	info, err := admin.GetInfo(ctx, primary.IPPort(), "replication")
+	if err != nil {
+		return fmt.Errorf("unable to get the replication info of primary %s: %v", primary.ID, err)
+	}
+	primaryOffset, _ := strconv.ParseInt(info["master_repl_offset"], 10, 64)
+	for _, replica := range replicas {
+		info, err = admin.GetInfo(ctx, replica.IPPort(), "replication")
+		if err != nil {
+			return fmt.Errorf("unable to get the replication info of replica %s: %v", replica.ID, err)
+		}
+		if info["master_link_status"] != "up" || info["master_sync_in_progress"] == "1" {
+			return fmt.Errorf("replica %s is synchronizing with primary %s", replica.ID, primary.ID)
+		}
+		offset, _ := strconv.ParseInt(info["slave_repl_offset"], 10, 64)
+		if lag := primaryOffset - offset; lag > maxFailoverReplicationLag {
+			return fmt.Errorf("replica %s is %d bytes behind primary %s", replica.ID, lag, primary.ID)
+		}
+	}
+	return nil
+}
+
+// manageFailoverRollingUpdate replaces the redis nodes without moving slots: the replicas of a shard are replaced
+// first, then a new replica takes over the slots of the primary with a failover, and the previous primary is retired
+// as a replica. Each call executes one step on each shard updated at the same time.
+func (c *Controller) manageFailoverRollingUpdate(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, nodes redis.Nodes) (result ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "Controller.manageFailoverRollingUpdate", attribute.Int("redis.nodes", len(nodes)))
+	defer func() { tracing.End(span, err) }()
+	hash, err := podctrl.GeneratePodHash(cluster)
+	if err != nil {
+		return result, err
+	}
+	result.RequeueAfter = requeueDelay
+	shards, unattached, stale := classifyFailoverShards(nodes, hash)
+	for _, node := range stale {
+		glog.Infof("failover rolling update: removing node %s of a previous pod template without slots", node.ID)
+		if err = c.detachForgetDeleteNode(ctx, admin, cluster, node); err != nil {
+			return result, err
+		}
+	}
+	selected := selectFailoverShards(shards, utils.GetMaxUnavailableShards(cluster))
+	if len(selected) == 0 {
+		return result, nil
+	}
+
+	if len(unattached) > 0 {
+		for i, node := range unattached {
+			primary := selected[i%len(selected)].primary
+			glog.Infof("failover rolling update: attaching node %s to primary %s", node.ID, primary.ID)
+			if err = admin.AttachReplicaToPrimary(ctx, node, primary); err != nil {
+				return result, err
+			}
+		}
+		return result, nil
+	}
+	nbRequiredPods := *cluster.Spec.NumberOfPrimaries * (1 + *cluster.Spec.ReplicationFactor)
+	surge := nbRequiredPods + utils.GetMaxSurge(cluster) - cluster.Status.Cluster.NumberOfPods
+	for _, shard := range selected {
+		if err = c.updateFailoverShard(ctx, admin, cluster, shard, &surge); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// updateFailoverShard executes the next step of the update of the shard. A pod is created while the surge allows it,
+// otherwise an old replica is retired first to make room for the new pod.
+func (c *Controller) updateFailoverShard(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, shard *failoverShard, surge *int32) error {
+	if err := replicasInSync(ctx, admin, shard.primary, shard.newReplicas); err != nil {
+		glog.V(3).Infof("failover rolling update: waiting for the replicas: %v", err)
+		return nil
+	}
+	nbReplicas := int32(len(shard.oldReplicas) + len(shard.newReplicas))
+	needPod := len(shard.oldReplicas) > 0 && nbReplicas <= *cluster.Spec.ReplicationFactor || len(shard.oldReplicas) == 0 && len(shard.newReplicas) == 0
+	switch {
+	case len(shard.oldReplicas) > 0 && (!needPod || *surge <= 0):
+		replica := shard.oldReplicas[0]
+		glog.Infof("failover rolling update: retiring replica %s of primary %s", replica.ID, shard.primary.ID)
+		return c.detachForgetDeleteNode(ctx, admin, cluster, replica)
+	case needPod:
+		// a shard without replicas needs a pod above the pods of the cluster to fail over, even without surge
+		if *surge < 0 || *surge == 0 && cluster.Status.Cluster.NumberOfPods > *cluster.Spec.NumberOfPrimaries*(1+*cluster.Spec.ReplicationFactor) {
+			return nil
+		}
+		*surge--
+		_, err := c.createPod(ctx, cluster)
+		return err
+	}
+	zones := make(map[string]string)
+	for _, node := range shard.newReplicas {
+		zones[node.ID] = node.Zone
+	}
+	replica, err := selectFailoverReplica(ctx, admin, shard.newReplicas, shard.primary, shard.primary.Zone, zones)
+	if err != nil {
+		return err
+	}
+	glog.Infof("failover rolling update: replica %s takes over primary %s", replica.ID, shard.primary.ID)
+	if err = admin.Failover(ctx, replica.IPPort(), ""); err != nil {
+		return err
+	}
+	if err = waitFailover(ctx, admin, replica.ID); err != nil {
+		return err
+	}
+	c.recorder.Eventf(cluster, v1.EventTypeNormal, "RollingUpdateFailover", "Replica %s took over primary %s of pod %s", replica.ID, shard.primary.ID, shard.primary.Pod.Name)
+	return nil
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func newFailoverNode(id, ip, role, primaryID, podHash string, slots ...redis.Slot) *redis.Node {
+	return &redis.Node{
+		ID:              id,
+		IP:              ip,
+		Port:            "6379",
+		Role:            role,
+		PrimaryReferent: primaryID,
+		Slots:           slots,
+		Pod:             &kapiv1.Pod{ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "pod-" + id, Annotations: map[string]string{rapi.PodSpecMD5LabelKey: podHash}}},
+	}
+}
+
+func Test_selectFailoverShards(t *testing.T) {
+	tests := []struct {
+		name           string
+		nodes          redis.Nodes
+		maxShards      int32
+		wantPrimaries  []string
+		wantUnattached int
+		wantStale      int
+	}{
+		{
+			name: "fresh shards",
+			nodes: redis.Nodes{
+				newFailoverNode("b", "10.0.0.2", "master", "", "old", 1),
+				newFailoverNode("a", "10.0.0.1", "master", "", "old", 0),
+				newFailoverNode("a1", "10.0.0.3", "slave", "a", "old"),
+			},
+			maxShards:     1,
+			wantPrimaries: []string{"a"},
+		},
+		{
+			name: "parallel shards",
+			nodes: redis.Nodes{
+				newFailoverNode("c", "10.0.0.3", "master", "", "old", 2),
+				newFailoverNode("b", "10.0.0.2", "master", "", "old", 1),
+				newFailoverNode("a", "10.0.0.1", "master", "", "old", 0),
+			},
+			maxShards:     2,
+			wantPrimaries: []string{"a", "b"},
+		},
+		{
+			name: "shard with new replicas first",
+			nodes: redis.Nodes{
+				newFailoverNode("a", "10.0.0.1", "master", "", "old", 0),
+				newFailoverNode("b", "10.0.0.2", "master", "", "old", 1),
+				newFailoverNode("b1", "10.0.0.3", "slave", "b", "new"),
+				newFailoverNode("c", "10.0.0.4", "master", "", "new"),
+			},
+			maxShards:      1,
+			wantPrimaries:  []string{"b"},
+			wantUnattached: 1,
+		},
+		{
+			name: "replaced primary retired first",
+			nodes: redis.Nodes{
+				newFailoverNode("a", "10.0.0.1", "master", "", "old", 0),
+				newFailoverNode("b1", "10.0.0.3", "master", "", "new", 1),
+				newFailoverNode("b", "10.0.0.2", "slave", "b1", "old"),
+				newFailoverNode("d", "10.0.0.5", "master", "", "old"),
+			},
+			maxShards:     2,
+			wantPrimaries: []string{"b1", "a"},
+			wantStale:     1,
+		},
+		{
+			name: "updated cluster",
+			nodes: redis.Nodes{
+				newFailoverNode("a", "10.0.0.1", "master", "", "new", 0),
+				newFailoverNode("a1", "10.0.0.3", "slave", "a", "new"),
+			},
+			maxShards: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards, unattached, stale := classifyFailoverShards(tt.nodes, "new")
+			if len(unattached) != tt.wantUnattached || len(stale) != tt.wantStale {
+				t.Errorf("classifyFailoverShards() unattached = %v, stale = %v, want %d and %d", unattached, stale, tt.wantUnattached, tt.wantStale)
+			}
+			var primaries []string
+			for _, shard := range selectFailoverShards(shards, tt.maxShards) {
+				primaries = append(primaries, shard.primary.ID)
+			}
+			if !reflect.DeepEqual(primaries, tt.wantPrimaries) {
+				t.Errorf("selectFailoverShards() primaries = %v, want %v", primaries, tt.wantPrimaries)
+			}
+		})
+	}
+}
+
+func TestController_manageFailoverRollingUpdate(t *testing.T) {
+	primaries, replicationFactor := int32(1), int32(1)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+		Spec: rapi.RedisClusterSpec{
+			NumberOfPrimaries: &primaries,
+			ReplicationFactor: &replicationFactor,
+			PodTemplate:       &kapiv1.PodTemplateSpec{},
+			RollingUpdate:     &rapi.RollingUpdate{Method: rapi.RollingUpdateMethodFailover},
+		},
+	}
+	hash, err := pod.GeneratePodHash(cluster)
+	if err != nil {
+		t.Fatalf("unable to generate the pod hash: %v", err)
+	}
+	primary := newFailoverNode("primary", "10.0.0.1", "master", "", "old", 0)
+	replica := newFailoverNode("replica", "10.0.0.2", "slave", "primary", hash)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{client: fakeClient, recorder: recorder, podControl: pod.NewRedisClusterControl(fakeClient, recorder)}
+	fakeAdmin := admin.NewFakeAdmin()
+	fakeAdmin.GetInfoRet["10.0.0.1:6379"] = map[string]string{"master_repl_offset": "1000"}
+	fakeAdmin.GetInfoRet["10.0.0.2:6379"] = map[string]string{"master_link_status": "down", "slave_repl_offset": "0"}
+	fakeAdmin.GetClusterInfosRet = admin.ClusterInfosRetType{ClusterInfos: &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+		"replica": {Node: &redis.Node{ID: "replica", IP: "10.0.0.2", Port: "6379", Role: "master", Slots: redis.SlotSlice{0}}},
+	}}}
+	ctx := context.Background()
+
+	// the new replica is still synchronizing
+	if _, err = c.manageFailoverRollingUpdate(ctx, fakeAdmin, cluster, redis.Nodes{primary, replica}); err != nil {
+		t.Fatalf("manageFailoverRollingUpdate() error = %v", err)
+	}
+	if len(fakeAdmin.Failovers) != 0 {
+		t.Errorf("manageFailoverRollingUpdate() should wait for the synchronization of the replica, got failovers %v", fakeAdmin.Failovers)
+	}
+
+	fakeAdmin.GetInfoRet["10.0.0.2:6379"] = map[string]string{"master_link_status": "up", "master_sync_in_progress": "0", "slave_repl_offset": "1000"}
+	if _, err = c.manageFailoverRollingUpdate(ctx, fakeAdmin, cluster, redis.Nodes{primary, replica}); err != nil {
+		t.Fatalf("manageFailoverRollingUpdate() error = %v", err)
+	}
+	if option, ok := fakeAdmin.Failovers["10.0.0.2:6379"]; !ok || option != "" {
+		t.Errorf("manageFailoverRollingUpdate() should execute CLUSTER FAILOVER on the new replica, got %v", fakeAdmin.Failovers)
+	}
+	if event := <-recorder.Events; event != "Normal RollingUpdateFailover Replica replica took over primary primary of pod pod-primary" {
+		t.Errorf("manageFailoverRollingUpdate() unexpected event: %s", event)
+	}
+}
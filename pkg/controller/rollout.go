@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+const (
+	// defaultRolloutPause minimum duration of a pause before its automatic promotion when the strategy does not set it
+	defaultRolloutPause = 60 * time.Second
+	// defaultMaxErrorRatePercent maximum error rate of the replaced primaries when the health gates do not set it
+	defaultMaxErrorRatePercent = 1
+)
+
+// rolloutHeld returns true when the rolling update of the cluster waits for its promotion, or stops at its partition
+func rolloutHeld(cluster *rapi.RedisCluster) bool {
+	rollout := cluster.Status.Rollout
+	if rollout == nil {
+		return false
+	}
+	switch rollout.Phase {
+	case rapi.RolloutPhasePaused, rapi.RolloutPhasePartitioned, rapi.RolloutPhaseAborted:
+		return true
+	}
+	return false
+}
+
+// rolloutShards returns the number of shards of the cluster, and the number of shards whose primary runs the pod
+// template with the given hash
+func rolloutShards(cluster *rapi.RedisCluster, hash string) (int32, int32) {
+	var total, updated int32
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role != rapi.RedisClusterNodeRolePrimary || len(node.Slots) == 0 {
+			continue
+		}
+		total++
+		if node.Pod != nil && comparePodSpecMD5Hash(hash, node.Pod) {
+			updated++
+		}
+	}
+	return total, updated
+}
+
+// rolloutPassInProgress returns true while the pods of the replaced shards are created or deleted, or
+// while a shard mixes redis nodes of both pod templates: the phase of the rolling update only changes between two shards
+func rolloutPassInProgress(cluster *rapi.RedisCluster, hash string) bool {
+	if cluster.Spec.NumberOfPrimaries != nil && cluster.Spec.ReplicationFactor != nil && cluster.Status.Cluster.NumberOfPods != *cluster.Spec.NumberOfPrimaries*(1+*cluster.Spec.ReplicationFactor) {
+		return true
+	}
+	updatedPrimaries := make(map[string]bool)
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role == rapi.RedisClusterNodeRolePrimary && node.Pod != nil {
+			updatedPrimaries[node.ID] = comparePodSpecMD5Hash(hash, node.Pod)
+		}
+	}
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role != rapi.RedisClusterNodeRoleReplica || node.Pod == nil {
+			continue
+		}
+		if updated, ok := updatedPrimaries[node.PrimaryRef]; ok && updated != comparePodSpecMD5Hash(hash, node.Pod) {
+			return true
+		}
+	}
+	return false
+}
+
+func setRolloutPhase(rollout *rapi.RolloutStatus, phase rapi.RolloutPhase, message string) bool {
+	changed := rollout.Phase != phase
+	rollout.Phase = phase
+	rollout.Message = message
+	if phase != rapi.RolloutPhasePaused {
+		rollout.PauseTime = nil
+	}
+	return changed
+}
+
+// reconcileRollout tracks the rolling update of the shards to the pod template in the status, and pauses, promotes or
+// aborts it with the strategy of the cluster. The rolling update is not started by the reconciliation while it is held.
+func (c *Controller) reconcileRollout(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, now metav1.Time) error {
+	hash, err := podctrl.GeneratePodHash(cluster)
+	if err != nil {
+		return err
+	}
+	rollout := cluster.Status.Rollout
+	if comparePodsWithPodTemplate(cluster) {
+		if rollout != nil && rollout.Phase != rapi.RolloutPhaseCompleted {
+			rollout.TotalShards, rollout.UpdatedShards = rolloutShards(cluster, hash)
+			setRolloutPhase(rollout, rapi.RolloutPhaseCompleted, "")
+			rollout.HealthCounters = nil
+			c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutCompleted", "Rolling update of %d shards completed", rollout.TotalShards)
+		}
+		return nil
+	}
+
+	total, updated := rolloutShards(cluster, hash)
+	if rollout == nil || rollout.PodTemplateHash != hash {
+		rollout = &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhaseProgressing, PromotedShards: updated}
+		cluster.Status.Rollout = rollout
+		c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutStarted", "Rolling update of %d shards started", total)
+	}
+	rollout.TotalShards, rollout.UpdatedShards = total, updated
+	if rolloutPassInProgress(cluster, hash) {
+		return nil
+	}
+	var strategy *rapi.RolloutStrategy
+	if cluster.Spec.RollingUpdate != nil {
+		strategy = cluster.Spec.RollingUpdate.Strategy
+	}
+	if strategy == nil {
+		setRolloutPhase(rollout, rapi.RolloutPhaseProgressing, "")
+		return nil
+	}
+
+	_, promote := cluster.Annotations[rapi.RolloutPromoteAnnotationKey]
+	switch {
+	case strategy.Abort:
+		if setRolloutPhase(rollout, rapi.RolloutPhaseAborted, "aborted by the rolling update strategy") {
+			c.recorder.Eventf(cluster, v1.EventTypeWarning, "RolloutAborted", "Rolling update aborted with %d of %d shards updated", updated, total)
+		}
+	case promote:
+		// the copy is patched: the status computed by this reconciliation is kept in the cluster
+		promoted := cluster.DeepCopy()
+		delete(promoted.Annotations, rapi.RolloutPromoteAnnotationKey)
+		if err = c.client.Patch(ctx, promoted, kclient.MergeFrom(cluster)); err != nil {
+			return err
+		}
+		if rollout.Phase == rapi.RolloutPhasePaused || rollout.Phase == rapi.RolloutPhaseAborted {
+			if !c.checkRolloutGates(ctx, admin, cluster, infos, hash, strategy) {
+				return nil
+			}
+			setRolloutPhase(rollout, rapi.RolloutPhaseProgressing, "")
+			rollout.PromotedShards = updated
+			c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutPromoted", "Rolling update promoted with %d of %d shards updated", updated, total)
+		}
+	case strategy.Partition > 0 && updated >= total-strategy.Partition:
+		if setRolloutPhase(rollout, rapi.RolloutPhasePartitioned, fmt.Sprintf("%d shards are left on the previous pod template", total-updated)) {
+			c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutPartitioned", "Rolling update stopped at its partition with %d of %d shards updated", updated, total)
+		}
+	case rollout.Phase == rapi.RolloutPhasePaused:
+		if !strategy.AutoPromote {
+			return nil
+		}
+		pause := defaultRolloutPause
+		if strategy.PauseSeconds != nil {
+			pause = time.Duration(*strategy.PauseSeconds) * time.Second
+		}
+		if rollout.PauseTime != nil && now.Sub(rollout.PauseTime.Time) < pause {
+			return nil
+		}
+		if !c.checkRolloutGates(ctx, admin, cluster, infos, hash, strategy) {
+			return nil
+		}
+		setRolloutPhase(rollout, rapi.RolloutPhaseProgressing, "")
+		rollout.PromotedShards = updated
+		c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutPromoted", "Rolling update promoted with %d of %d shards updated, health gates passed", updated, total)
+	case rollout.Phase == rapi.RolloutPhaseAborted:
+		// an aborted rolling update only resumes when it is promoted
+	default:
+		if rollout.Phase == rapi.RolloutPhasePartitioned {
+			// the partition was lowered, the pauses are counted from the shards replaced since then
+			rollout.PromotedShards = updated
+		}
+		// the health gates are checked between two passes, before the next shards are replaced
+		if updated > 0 && !c.checkRolloutGates(ctx, admin, cluster, infos, hash, strategy) {
+			return nil
+		}
+		if strategy.PauseAfterShards > 0 && updated-rollout.PromotedShards >= strategy.PauseAfterShards && updated < total {
+			setRolloutPhase(rollout, rapi.RolloutPhasePaused, fmt.Sprintf("paused after %d of %d shards updated", updated, total))
+			rollout.PauseTime = &now
+			c.recorder.Eventf(cluster, v1.EventTypeNormal, "RolloutPaused", "Rolling update paused with %d of %d shards updated", updated, total)
+			return nil
+		}
+		setRolloutPhase(rollout, rapi.RolloutPhaseProgressing, "")
+	}
+	return nil
+}
+
+// checkRolloutGates checks the health gates of the rolling update, the rolling update is aborted when they fail.
+// It returns true if the health gates passed.
+func (c *Controller) checkRolloutGates(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, hash string, strategy *rapi.RolloutStrategy) bool {
+	rollout := cluster.Status.Rollout
+	counters, err := checkRolloutHealth(ctx, admin, cluster, infos, hash, strategy.HealthGates, rollout.HealthCounters)
+	// the next error rate is measured from this check, whatever its outcome
+	rollout.HealthCounters = nil
+	if len(counters) > 0 {
+		rollout.HealthCounters = counters
+	}
+	if err == nil {
+		return true
+	}
+	glog.Warningf("rolling update of RedisCluster %s/%s aborted: %v", cluster.Namespace, cluster.Name, err)
+	setRolloutPhase(rollout, rapi.RolloutPhaseAborted, fmt.Sprintf("health gate failed: %v", err))
+	c.recorder.Eventf(cluster, v1.EventTypeWarning, "RolloutAborted", "Rolling update aborted, health gate failed: %v", err)
+	return false
+}
+
+// checkRolloutHealth returns an error when the cluster state is not ok, a replica running the pod template is not in
+// sync with its primary, or a primary running the pod template answered too many commands with an error since the
+// previous check. It returns the command counters of the primaries running the pod template, by pod name.
+func checkRolloutHealth(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, hash string, gates *rapi.RolloutHealthGates, previous map[string]rapi.RolloutCounters) (map[string]rapi.RolloutCounters, error) {
+	counters := make(map[string]rapi.RolloutCounters)
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role != rapi.RedisClusterNodeRolePrimary || node.Pod == nil || !comparePodSpecMD5Hash(hash, node.Pod) {
+			continue
+		}
+		info, err := admin.GetInfo(ctx, node.IP+":"+node.Port, "stats")
+		if err != nil {
+			return counters, fmt.Errorf("unable to get the stats of primary %s: %v", node.PodName, err)
+		}
+		counters[node.PodName] = rapi.RolloutCounters{
+			CommandsProcessed: parseInfoInt(info["total_commands_processed"]),
+			ErrorReplies:      parseInfoInt(info["total_error_replies"]),
+		}
+	}
+
+	if infos == nil || infos.Status != redis.ClusterInfoConsistent {
+		return counters, fmt.Errorf("cluster state is not ok: the views of the nodes are not consistent")
+	}
+	for addr, nodeInfos := range infos.Infos {
+		if nodeInfos == nil || nodeInfos.Node == nil {
+			continue
+		}
+		if nodeInfos.Node.HasStatus(redis.NodeStatusFail) || nodeInfos.Node.HasStatus(redis.NodeStatusPFail) {
+			return counters, fmt.Errorf("cluster state is not ok: node %s is failing", addr)
+		}
+	}
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role != rapi.RedisClusterNodeRolePrimary || len(node.Slots) == 0 {
+			continue
+		}
+		state, err := admin.GetClusterState(ctx, node.IP+":"+node.Port)
+		if err != nil {
+			return counters, fmt.Errorf("unable to get the cluster state of primary %s: %v", node.PodName, err)
+		}
+		if state["cluster_state"] != "ok" {
+			return counters, fmt.Errorf("cluster state is not ok: primary %s reports the state %q", node.PodName, state["cluster_state"])
+		}
+	}
+
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.Role != rapi.RedisClusterNodeRoleReplica || node.Pod == nil || !comparePodSpecMD5Hash(hash, node.Pod) {
+			continue
+		}
+		info, err := admin.GetInfo(ctx, node.IP+":"+node.Port, "replication")
+		if err != nil {
+			return counters, fmt.Errorf("unable to get the replication info of replica %s: %v", node.PodName, err)
+		}
+		if status := info["master_link_status"]; status != "up" {
+			return counters, fmt.Errorf("replication link of replica %s is %q", node.PodName, status)
+		}
+	}
+
+	maxErrorRate := int64(defaultMaxErrorRatePercent)
+	if gates != nil && gates.MaxErrorRatePercent != nil {
+		maxErrorRate = int64(*gates.MaxErrorRatePercent)
+	}
+	for _, node := range cluster.Status.Cluster.Nodes {
+		current, ok := counters[node.PodName]
+		if !ok || node.Role != rapi.RedisClusterNodeRolePrimary {
+			continue
+		}
+		processed, errorReplies := current.CommandsProcessed, current.ErrorReplies
+		// the counters of a node that restarted since the previous check start from 0
+		if prev, ok := previous[node.PodName]; ok && prev.CommandsProcessed <= processed && prev.ErrorReplies <= errorReplies {
+			processed -= prev.CommandsProcessed
+			errorReplies -= prev.ErrorReplies
+		}
+		if processed > 0 && errorReplies*100 > maxErrorRate*processed {
+			return counters, fmt.Errorf("primary %s answered %d of %d commands with an error since the previous check", node.PodName, errorReplies, processed)
+		}
+	}
+	return counters, nil
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	podctrl "github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+// newRolloutCluster returns a cluster of 4 shards without replicas, the first nbUpdated primaries run the pod template
+func newRolloutCluster(t *testing.T, nbUpdated int, strategy *rapi.RolloutStrategy) *rapi.RedisCluster {
+	primaries, replicationFactor := int32(4), int32(0)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+		Spec: rapi.RedisClusterSpec{
+			NumberOfPrimaries: &primaries,
+			ReplicationFactor: &replicationFactor,
+			RollingUpdate:     &rapi.RollingUpdate{Strategy: strategy},
+			PodTemplate:       &kapiv1.PodTemplateSpec{Spec: kapiv1.PodSpec{Containers: []kapiv1.Container{{Name: "redis", Image: "redis:7"}}}},
+		},
+	}
+	hash, err := podctrl.GeneratePodHash(cluster)
+	if err != nil {
+		t.Fatalf("unable to generate the pod hash: %v", err)
+	}
+	slots := []string{"0-4095", "4096-8191", "8192-12287", "12288-16383"}
+	for i, slot := range slots {
+		podHash := "previous"
+		if i < nbUpdated {
+			podHash = hash
+		}
+		name := "pod" + string(rune('a'+i))
+		cluster.Status.Cluster.Nodes = append(cluster.Status.Cluster.Nodes, rapi.RedisClusterNode{
+			ID:      name,
+			PodName: name,
+			IP:      "10.0.0." + string(rune('1'+i)),
+			Port:    "6379",
+			Role:    rapi.RedisClusterNodeRolePrimary,
+			Slots:   []string{slot},
+			Pod:     &kapiv1.Pod{ObjectMeta: kmetav1.ObjectMeta{Name: name, Annotations: map[string]string{rapi.PodSpecMD5LabelKey: podHash}}},
+		})
+	}
+	cluster.Status.Cluster.NumberOfPods = 4
+	return cluster
+}
+
+func TestController_reconcileRollout(t *testing.T) {
+	ctx := context.Background()
+	now := kmetav1.Now()
+	infos := &redis.ClusterInfos{Status: redis.ClusterInfoConsistent}
+	tests := []struct {
+		name          string
+		nbUpdated     int
+		strategy      *rapi.RolloutStrategy
+		rollout       func(hash string) *rapi.RolloutStatus
+		annotations   map[string]string
+		errorReplies  string
+		wantPhase     rapi.RolloutPhase
+		wantHeld      bool
+		wantPromotion int32
+	}{
+		{
+			name:      "no strategy",
+			nbUpdated: 2,
+			wantPhase: rapi.RolloutPhaseProgressing,
+		},
+		{
+			name:      "new pod template",
+			nbUpdated: 1,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 1},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: "previous", Phase: rapi.RolloutPhaseCompleted}
+			},
+			wantPhase:     rapi.RolloutPhaseProgressing,
+			wantPromotion: 1,
+		},
+		{
+			name:      "pause after shards",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2},
+			wantPhase: rapi.RolloutPhasePaused,
+			wantHeld:  true,
+		},
+		{
+			name:      "partition reached",
+			nbUpdated: 3,
+			strategy:  &rapi.RolloutStrategy{Partition: 1},
+			wantPhase: rapi.RolloutPhasePartitioned,
+			wantHeld:  true,
+		},
+		{
+			name:      "abort",
+			nbUpdated: 1,
+			strategy:  &rapi.RolloutStrategy{Abort: true},
+			wantPhase: rapi.RolloutPhaseAborted,
+			wantHeld:  true,
+		},
+		{
+			name:      "paused without auto promotion",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &kmetav1.Time{Time: now.Add(-time.Hour)}}
+			},
+			wantPhase: rapi.RolloutPhasePaused,
+			wantHeld:  true,
+		},
+		{
+			name:      "promoted by the annotation",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &now}
+			},
+			annotations:   map[string]string{rapi.RolloutPromoteAnnotationKey: "true"},
+			wantPhase:     rapi.RolloutPhaseProgressing,
+			wantPromotion: 2,
+		},
+		{
+			name:      "auto promotion waits for the pause",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2, AutoPromote: true},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &now}
+			},
+			wantPhase: rapi.RolloutPhasePaused,
+			wantHeld:  true,
+		},
+		{
+			name:      "auto promotion with healthy shards",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2, AutoPromote: true},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &kmetav1.Time{Time: now.Add(-time.Hour)}}
+			},
+			errorReplies:  "1",
+			wantPhase:     rapi.RolloutPhaseProgressing,
+			wantPromotion: 2,
+		},
+		{
+			name:      "auto promotion aborted by the error rate",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2, AutoPromote: true},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &kmetav1.Time{Time: now.Add(-time.Hour)}}
+			},
+			errorReplies: "50",
+			wantPhase:    rapi.RolloutPhaseAborted,
+			wantHeld:     true,
+		},
+		{
+			name:         "pass gated without pauses",
+			nbUpdated:    2,
+			strategy:     &rapi.RolloutStrategy{},
+			errorReplies: "50",
+			wantPhase:    rapi.RolloutPhaseAborted,
+			wantHeld:     true,
+		},
+		{
+			name:      "promotion aborted by the error rate",
+			nbUpdated: 2,
+			strategy:  &rapi.RolloutStrategy{PauseAfterShards: 2},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhasePaused, PauseTime: &now}
+			},
+			annotations:  map[string]string{rapi.RolloutPromoteAnnotationKey: "true"},
+			errorReplies: "50",
+			wantPhase:    rapi.RolloutPhaseAborted,
+			wantHeld:     true,
+		},
+		{
+			name:      "completed",
+			nbUpdated: 4,
+			strategy:  &rapi.RolloutStrategy{Partition: 1},
+			rollout: func(hash string) *rapi.RolloutStatus {
+				return &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhaseProgressing}
+			},
+			wantPhase: rapi.RolloutPhaseCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newRolloutCluster(t, tt.nbUpdated, tt.strategy)
+			cluster.Annotations = tt.annotations
+			hash, _ := podctrl.GeneratePodHash(cluster)
+			cluster.Status.Rollout = &rapi.RolloutStatus{PodTemplateHash: hash, Phase: rapi.RolloutPhaseProgressing}
+			if tt.rollout != nil {
+				cluster.Status.Rollout = tt.rollout(hash)
+			}
+			fakeAdmin := admin.NewFakeAdmin()
+			for _, node := range cluster.Status.Cluster.Nodes {
+				fakeAdmin.GetInfoRet[node.IP+":"+node.Port] = map[string]string{"total_commands_processed": "100", "total_error_replies": tt.errorReplies}
+				fakeAdmin.GetClusterStateRet[node.IP+":"+node.Port] = map[string]string{"cluster_state": "ok"}
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy()).Build()
+			c := &Controller{client: fakeClient, recorder: record.NewFakeRecorder(10)}
+
+			if err := c.reconcileRollout(ctx, fakeAdmin, cluster, infos, now); err != nil {
+				t.Fatalf("reconcileRollout() error = %v", err)
+			}
+			rollout := cluster.Status.Rollout
+			if rollout == nil || rollout.Phase != tt.wantPhase {
+				t.Fatalf("rollout = %+v, want phase %s", rollout, tt.wantPhase)
+			}
+			if rollout.TotalShards != 4 || rollout.UpdatedShards != int32(tt.nbUpdated) {
+				t.Errorf("rollout shards = %d/%d, want %d/4", rollout.UpdatedShards, rollout.TotalShards, tt.nbUpdated)
+			}
+			if rollout.PromotedShards != tt.wantPromotion {
+				t.Errorf("rollout.PromotedShards = %d, want %d", rollout.PromotedShards, tt.wantPromotion)
+			}
+			if got := needRollingUpdate(cluster); got == tt.wantHeld && tt.nbUpdated < 4 {
+				t.Errorf("needRollingUpdate() = %v, want %v", got, !tt.wantHeld)
+			}
+			if tt.annotations != nil {
+				patched := &rapi.RedisCluster{}
+				if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, patched); err != nil {
+					t.Fatalf("unable to get the cluster: %v", err)
+				}
+				if _, ok := patched.Annotations[rapi.RolloutPromoteAnnotationKey]; ok {
+					t.Errorf("promote annotation should be removed")
+				}
+			}
+		})
+	}
+}
+
+func Test_checkRolloutHealth(t *testing.T) {
+	ctx := context.Background()
+	cluster := newRolloutCluster(t, 1, nil)
+	hash, _ := podctrl.GeneratePodHash(cluster)
+	replicaPod := &kapiv1.Pod{ObjectMeta: kmetav1.ObjectMeta{Name: "replica", Annotations: map[string]string{rapi.PodSpecMD5LabelKey: hash}}}
+	cluster.Status.Cluster.Nodes = append(cluster.Status.Cluster.Nodes, rapi.RedisClusterNode{ID: "replica", PodName: "replica", IP: "10.0.0.9", Port: "6379", Role: rapi.RedisClusterNodeRoleReplica, PrimaryRef: "poda", Pod: replicaPod})
+	consistent := &redis.ClusterInfos{Status: redis.ClusterInfoConsistent}
+
+	tests := []struct {
+		name         string
+		infos        *redis.ClusterInfos
+		linkStatus   string
+		clusterState string
+		errorReplies string
+		previous     map[string]rapi.RolloutCounters
+		wantErr      bool
+	}{
+		{name: "healthy", infos: consistent, linkStatus: "up", clusterState: "ok", errorReplies: "0"},
+		{name: "inconsistent views", infos: &redis.ClusterInfos{Status: redis.ClusterInfoInconsistent}, linkStatus: "up", clusterState: "ok", errorReplies: "0", wantErr: true},
+		{name: "replica out of sync", infos: consistent, linkStatus: "down", clusterState: "ok", errorReplies: "0", wantErr: true},
+		{name: "cluster state fail", infos: consistent, linkStatus: "up", clusterState: "fail", errorReplies: "0", wantErr: true},
+		{name: "error rate since the start", infos: consistent, linkStatus: "up", clusterState: "ok", errorReplies: "50", wantErr: true},
+		{
+			name: "errors before the previous check", infos: consistent, linkStatus: "up", clusterState: "ok", errorReplies: "50",
+			previous: map[string]rapi.RolloutCounters{"poda": {CommandsProcessed: 20, ErrorReplies: 50}},
+		},
+		{
+			name: "errors since the previous check", infos: consistent, linkStatus: "up", clusterState: "ok", errorReplies: "50",
+			previous: map[string]rapi.RolloutCounters{"poda": {CommandsProcessed: 20, ErrorReplies: 10}},
+			wantErr:  true,
+		},
+		{
+			name: "restarted node", infos: consistent, linkStatus: "up", clusterState: "ok", errorReplies: "50",
+			previous: map[string]rapi.RolloutCounters{"poda": {CommandsProcessed: 1000, ErrorReplies: 0}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAdmin := admin.NewFakeAdmin()
+			fakeAdmin.GetInfoRet["10.0.0.1:6379"] = map[string]string{"total_commands_processed": "120", "total_error_replies": tt.errorReplies}
+			fakeAdmin.GetInfoRet["10.0.0.9:6379"] = map[string]string{"master_link_status": tt.linkStatus}
+			for _, node := range cluster.Status.Cluster.Nodes {
+				fakeAdmin.GetClusterStateRet[node.IP+":"+node.Port] = map[string]string{"cluster_state": "ok"}
+			}
+			fakeAdmin.GetClusterStateRet["10.0.0.4:6379"] = map[string]string{"cluster_state": tt.clusterState}
+			counters, err := checkRolloutHealth(ctx, fakeAdmin, cluster, tt.infos, hash, nil, tt.previous)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkRolloutHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := map[string]rapi.RolloutCounters{"poda": {CommandsProcessed: 120, ErrorReplies: parseInfoInt(tt.errorReplies)}}
+			if !reflect.DeepEqual(counters, want) {
+				t.Errorf("checkRolloutHealth() counters = %v, want %v", counters, want)
+			}
+		})
+	}
+}
@@ -49,6 +49,8 @@ func reassignClusters(ctx context.Context, admin redis.AdminInterface, config *c
 			&redis.AdminOptions{
 				ConnectionTimeout:  time.Duration(config.DialTimeout) * time.Millisecond,
 				RenameCommandsFile: config.GetRenameCommandsFile(),
+				Auth:               redis.Auth{Username: config.Username, Password: config.Password},
+				TLSConfig:          config.TLSConfig,
 			})
 		for _, nodeAddr := range cluster {
 			if err := clusterAdmin.FlushAndReset(ctx, nodeAddr, redis.ResetHard); err != nil {
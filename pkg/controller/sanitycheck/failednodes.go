@@ -8,12 +8,23 @@ import (
 	"k8s.io/apimachinery/pkg/util/errors"
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
 )
 
 // FixFailedNodes fix failed nodes: in some cases (cluster without enough primary after crash or scale down), some nodes may still know about fail nodes
-func FixFailedNodes(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
+func FixFailedNodes(ctx context.Context, admin redis.AdminInterface, podControl pod.RedisClusterControlInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos, dryRun bool) (bool, error) {
 	forgetSet := listGhostNodes(cluster, infos)
+	retainedSet, err := listRetainedNodes(podControl, cluster)
+	if err != nil {
+		return false, err
+	}
+	for id := range retainedSet {
+		if forgetSet[id] {
+			glog.V(3).Infof("Sanitychecks: Failed node %s is not forgotten, its persistent volume claim is waiting for a new pod", id)
+			delete(forgetSet, id)
+		}
+	}
 	var errs []error
 	doneAnAction := false
 	for id := range forgetSet {
@@ -57,3 +68,19 @@ func listGhostNodes(cluster *rapi.RedisCluster, infos *redis.ClusterInfos) map[s
 	}
 	return ghostNodesSet
 }
+
+// listRetainedNodes: a retained node has a persistent volume claim that will be reused by a new pod,
+// so it will rejoin the cluster with the same node ID and must not be forgotten
+func listRetainedNodes(podControl pod.RedisClusterControlInterface, cluster *rapi.RedisCluster) (map[string]bool, error) {
+	retainedNodesSet := map[string]bool{}
+	claims, err := podControl.GetRedisClusterClaims(cluster)
+	if err != nil {
+		return retainedNodesSet, err
+	}
+	for _, claim := range claims {
+		if id, ok := claim.Annotations[rapi.NodeIDAnnotationKey]; ok && claim.DeletionTimestamp == nil {
+			retainedNodesSet[id] = true
+		}
+	}
+	return retainedNodesSet, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
 	"github.com/IBM/operator-for-redis-cluster/pkg/config"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/metrics"
 	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
 )
@@ -19,10 +20,13 @@ func RunSanityChecks(ctx context.Context, admin redis.AdminInterface, config *co
 		return false, nil
 	}
 	// * fix failed nodes: in some cases (cluster without enough primary after crash or scale down), some nodes may still know about fail nodes
-	if actionDone, err = FixFailedNodes(ctx, admin, cluster, infos, dryRun); err != nil {
+	if actionDone, err = FixFailedNodes(ctx, admin, podControl, cluster, infos, dryRun); err != nil {
 		return actionDone, err
 	} else if actionDone {
 		glog.V(2).Infof("FixFailedNodes executed an action on the cluster (dryRun: %v)", dryRun)
+		if !dryRun {
+			metrics.RecordSanityCheckAction(cluster, "FixFailedNodes")
+		}
 		return actionDone, nil
 	}
 
@@ -31,6 +35,9 @@ func RunSanityChecks(ctx context.Context, admin redis.AdminInterface, config *co
 		return actionDone, err
 	} else if actionDone {
 		glog.V(2).Infof("FixUntrustedNodes executed an action on the cluster (dryRun: %v)", dryRun)
+		if !dryRun {
+			metrics.RecordSanityCheckAction(cluster, "FixUntrustedNodes")
+		}
 		return actionDone, nil
 	}
 
@@ -39,6 +46,9 @@ func RunSanityChecks(ctx context.Context, admin redis.AdminInterface, config *co
 		return actionDone, err
 	} else if actionDone {
 		glog.V(2).Infof("FixTerminatingPods executed an action on the cluster (dryRun: %v)", dryRun)
+		if !dryRun {
+			metrics.RecordSanityCheckAction(cluster, "FixTerminatingPods")
+		}
 		return actionDone, nil
 	}
 
@@ -47,6 +57,9 @@ func RunSanityChecks(ctx context.Context, admin redis.AdminInterface, config *co
 		return actionDone, err
 	} else if actionDone {
 		glog.V(2).Infof("FixClusterSplit executed an action on the cluster (dryRun: %v)", dryRun)
+		if !dryRun {
+			metrics.RecordSanityCheckAction(cluster, "FixClusterSplit")
+		}
 		return actionDone, nil
 	}
 
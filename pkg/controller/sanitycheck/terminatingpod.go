@@ -36,7 +36,7 @@ func FixTerminatingPods(cluster *rapi.RedisCluster, podControl pod.RedisClusterC
 			actionDone = true
 			// it means that this pod should already been deleted since a wild
 			if !dryRun {
-				if err := podControl.DeletePod(cluster, p.Name); err != nil {
+				if err := deleteTerminatingPod(podControl, cluster, p.Name); err != nil {
 					errs = append(errs, err)
 				}
 			}
@@ -45,3 +45,12 @@ func FixTerminatingPods(cluster *rapi.RedisCluster, podControl pod.RedisClusterC
 
 	return actionDone, errors.NewAggregate(errs)
 }
+
+// deleteTerminatingPod deletes the pod. When storage is enabled, the pod is not forced: the kubelet may still have its
+// volume attached, and the claim kept for the next pod is only released once the pod is gone.
+func deleteTerminatingPod(podControl pod.RedisClusterControlInterface, cluster *rapi.RedisCluster, podName string) error {
+	if pod.IsStorageEnabled(cluster) {
+		return podControl.DeletePodKeepClaim(cluster, podName)
+	}
+	return podControl.DeletePod(cluster, podName)
+}
@@ -0,0 +1,77 @@
+package sanitycheck
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+func TestFixTerminatingPods(t *testing.T) {
+	stuck := newPod("stuck", "node1", "10.0.0.1")
+	stuck.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	terminating := newPod("terminating", "node2", "10.0.0.2")
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	running := newPod("running", "node3", "10.0.0.3")
+
+	tests := []struct {
+		name             string
+		storage          *rapi.RedisStorage
+		dryRun           bool
+		want             bool
+		wantPodDeleted   map[string]bool
+		wantPodForced    map[string]bool
+		wantClaimDeleted map[string]bool
+	}{
+		{
+			name:             "dry run",
+			dryRun:           true,
+			want:             true,
+			wantPodDeleted:   map[string]bool{},
+			wantPodForced:    map[string]bool{},
+			wantClaimDeleted: map[string]bool{},
+		},
+		{
+			name:             "storage disabled",
+			want:             true,
+			wantPodDeleted:   map[string]bool{"stuck": true},
+			wantPodForced:    map[string]bool{},
+			wantClaimDeleted: map[string]bool{"stuck": true},
+		},
+		{
+			// the kubelet may still have the volume attached: the pod is not forced and its claim is kept
+			name:             "storage enabled",
+			storage:          &rapi.RedisStorage{},
+			want:             true,
+			wantPodDeleted:   map[string]bool{"stuck": true},
+			wantPodForced:    map[string]bool{},
+			wantClaimDeleted: map[string]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podControl := newFakecontrol([]kapiv1.Pod{stuck, terminating, running})
+			cluster := &rapi.RedisCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-ns"},
+				Spec:       rapi.RedisClusterSpec{Storage: tt.storage},
+			}
+			got, err := FixTerminatingPods(cluster, podControl, time.Minute, tt.dryRun)
+			if err != nil || got != tt.want {
+				t.Errorf("FixTerminatingPods() = %v, %v, want %v", got, err, tt.want)
+			}
+			if !reflect.DeepEqual(podControl.isPodDeleted, tt.wantPodDeleted) {
+				t.Errorf("FixTerminatingPods() deleted pods = %v, want %v", podControl.isPodDeleted, tt.wantPodDeleted)
+			}
+			if !reflect.DeepEqual(podControl.isPodForced, tt.wantPodForced) {
+				t.Errorf("FixTerminatingPods() forced pods = %v, want %v", podControl.isPodForced, tt.wantPodForced)
+			}
+			if !reflect.DeepEqual(podControl.isClaimDeleted, tt.wantClaimDeleted) {
+				t.Errorf("FixTerminatingPods() deleted claims = %v, want %v", podControl.isClaimDeleted, tt.wantClaimDeleted)
+			}
+		})
+	}
+}
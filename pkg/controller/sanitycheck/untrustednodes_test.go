@@ -185,15 +185,19 @@ func TestFixUntrustedNodes(t *testing.T) {
 
 // Fakecontrol fake control
 type Fakecontrol struct {
-	pods         []kapiv1.Pod
-	pod          *kapiv1.Pod
-	isPodDeleted map[string]bool
+	pods           []kapiv1.Pod
+	pod            *kapiv1.Pod
+	isPodDeleted   map[string]bool
+	isPodForced    map[string]bool
+	isClaimDeleted map[string]bool
 }
 
 func newFakecontrol(pods []kapiv1.Pod) *Fakecontrol {
 	return &Fakecontrol{
-		pods:         pods,
-		isPodDeleted: map[string]bool{},
+		pods:           pods,
+		isPodDeleted:   map[string]bool{},
+		isPodForced:    map[string]bool{},
+		isClaimDeleted: map[string]bool{},
 	}
 }
 
@@ -212,14 +216,31 @@ func (f *Fakecontrol) CreatePodOnNode(redisCluster *rapi.RedisCluster, nodeName
 	return f.pod, nil
 }
 
+// GetRedisClusterClaims return list of PersistentVolumeClaim attached to a RedisCluster
+func (f *Fakecontrol) GetRedisClusterClaims(redisCluster *rapi.RedisCluster) ([]kapiv1.PersistentVolumeClaim, error) {
+	return nil, nil
+}
+
 // DeletePod used to delete a pod from its name
 func (f *Fakecontrol) DeletePod(redisCluster *rapi.RedisCluster, podName string) error {
 	f.isPodDeleted[podName] = true
+	if f.isClaimDeleted != nil {
+		f.isClaimDeleted[podName] = true
+	}
 	return nil
 }
 
 // DeletePodNow used to delete a pod from its name
 func (f *Fakecontrol) DeletePodNow(redisCluster *rapi.RedisCluster, podName string) error {
+	f.isPodDeleted[podName] = true
+	if f.isPodForced != nil {
+		f.isPodForced[podName] = true
+	}
+	return nil
+}
+
+// DeletePodKeepClaim used to delete a pod from its name without its persistent volume claim
+func (f *Fakecontrol) DeletePodKeepClaim(redisCluster *rapi.RedisCluster, podName string) error {
 	f.isPodDeleted[podName] = true
 	return nil
 }
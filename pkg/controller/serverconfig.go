@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+)
+
+// restartRequiredConfig redis settings that CONFIG SET cannot change at runtime, they are only applied when the
+// redis-server process starts
+var restartRequiredConfig = map[string]bool{
+	"aclfile":                  true,
+	"always-show-logo":         true,
+	"appenddirname":            true,
+	"appendfilename":           true,
+	"cluster-config-file":      true,
+	"cluster-enabled":          true,
+	"cluster-port":             true,
+	"daemonize":                true,
+	"databases":                true,
+	"disable-thp":              true,
+	"enable-debug-command":     true,
+	"enable-module-command":    true,
+	"enable-protected-configs": true,
+	"io-threads":               true,
+	"io-threads-do-reads":      true,
+	"logfile":                  true,
+	"pidfile":                  true,
+	"rdbchecksum":              true,
+	"set-proc-title":           true,
+	"supervised":               true,
+	"syslog-enabled":           true,
+	"syslog-facility":          true,
+	"syslog-ident":             true,
+	"tcp-backlog":              true,
+	"unixsocket":               true,
+	"unixsocketgroup":          true,
+	"unixsocketperm":           true,
+}
+
+// isRestartRequired returns true if the setting is only applied by a restart of the redis nodes
+func isRestartRequired(field string) bool {
+	return restartRequiredConfig[field]
+}
+
+// splitConfigChanges splits the config changes into the settings applied with CONFIG SET, and the settings that
+// require a restart of the redis nodes
+func splitConfigChanges(changes map[string]string) (map[string]string, map[string]string) {
+	dynamic := make(map[string]string)
+	restart := make(map[string]string)
+	for field, val := range changes {
+		if isRestartRequired(field) {
+			restart[field] = val
+		} else {
+			dynamic[field] = val
+		}
+	}
+	return dynamic, restart
+}
+
+// restartConfigHash returns the MD5 hash of the settings of the config that require a restart of the redis nodes,
+// empty if the config has no such setting
+func restartConfigHash(config map[string]string) string {
+	var fields []string
+	for field := range config {
+		if isRestartRequired(field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	sort.Strings(fields)
+	hash := md5.New()
+	for _, field := range fields {
+		fmt.Fprintf(hash, "%s %s\n", field, config[field])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// setRestartHash sets the hash of the settings requiring a restart in the status, the pods are compared with it.
+// The hash is recorded as the baseline the first time the cluster is seen: the pods created before the settings
+// were tracked already run with them, they are only rolled once the settings change.
+func setRestartHash(cluster *rapi.RedisCluster, config map[string]string) {
+	hash := restartConfigHash(config)
+	if cluster.Status.Config == nil {
+		cluster.Status.Config = &rapi.ConfigStatus{BaselineRestartHash: hash}
+	}
+	cluster.Status.Config.RestartHash = hash
+}
+
+// setConfigKeys sets the settings waiting for the rolling update of the pods, the settings rejected by the redis
+// nodes and the drifted nodes in the status, the failed keys and the drifted nodes are expected sorted
+func setConfigKeys(cluster *rapi.RedisCluster, pendingChanges map[string]string, failedKeys, driftedNodes []string) {
+	pendingKeys := make([]string, 0, len(pendingChanges))
+	for field := range pendingChanges {
+		pendingKeys = append(pendingKeys, field)
+	}
+	sort.Strings(pendingKeys)
+	if cluster.Status.Config == nil {
+		if len(pendingKeys) == 0 && len(failedKeys) == 0 && len(driftedNodes) == 0 {
+			return
+		}
+		cluster.Status.Config = &rapi.ConfigStatus{}
+	}
+	cluster.Status.Config.PendingKeys = nilIfEmpty(pendingKeys)
+	cluster.Status.Config.FailedKeys = nilIfEmpty(failedKeys)
+	cluster.Status.Config.DriftedNodes = nilIfEmpty(driftedNodes)
+}
+
+// podNamesByAddr returns the sorted pod names of the redis nodes with the given addresses, the address is used for
+// the nodes missing from the status
+func podNamesByAddr(cluster *rapi.RedisCluster, addrs []string) []string {
+	podNames := make(map[string]string)
+	for _, node := range cluster.Status.Cluster.Nodes {
+		if node.PodName != "" {
+			podNames[node.IP+":"+node.Port] = node.PodName
+		}
+	}
+	names := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if name, ok := podNames[addr]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, addr)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func Test_splitConfigChanges(t *testing.T) {
+	changes := map[string]string{
+		"maxmemory":        "10Gb",
+		"databases":        "4",
+		"maxmemory-policy": "volatile-lfu",
+		"io-threads":       "2",
+	}
+	dynamic, restart := splitConfigChanges(changes)
+	wantDynamic := map[string]string{"maxmemory": "10Gb", "maxmemory-policy": "volatile-lfu"}
+	wantRestart := map[string]string{"databases": "4", "io-threads": "2"}
+	if !reflect.DeepEqual(dynamic, wantDynamic) {
+		t.Errorf("splitConfigChanges() dynamic = %v, want %v", dynamic, wantDynamic)
+	}
+	if !reflect.DeepEqual(restart, wantRestart) {
+		t.Errorf("splitConfigChanges() restart = %v, want %v", restart, wantRestart)
+	}
+}
+
+func Test_restartConfigHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]string
+		other    map[string]string
+		wantSame bool
+	}{
+		{
+			name:     "dynamic settings changed",
+			config:   map[string]string{"databases": "4", "maxmemory": "1Gb"},
+			other:    map[string]string{"databases": "4", "maxmemory": "2Gb"},
+			wantSame: true,
+		},
+		{
+			name:     "restart settings changed",
+			config:   map[string]string{"databases": "4", "maxmemory": "1Gb"},
+			other:    map[string]string{"databases": "8", "maxmemory": "1Gb"},
+			wantSame: false,
+		},
+		{
+			name:     "restart setting added",
+			config:   map[string]string{"databases": "4"},
+			other:    map[string]string{"databases": "4", "io-threads": "2"},
+			wantSame: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, other := restartConfigHash(tt.config), restartConfigHash(tt.other)
+			if (hash == other) != tt.wantSame {
+				t.Errorf("restartConfigHash() = %s and %s, want same hash: %v", hash, other, tt.wantSame)
+			}
+		})
+	}
+	if hash := restartConfigHash(map[string]string{"maxmemory": "1Gb"}); hash != "" {
+		t.Errorf("restartConfigHash() = %s, want empty hash without restart settings", hash)
+	}
+}
+
+func Test_setConfigKeys(t *testing.T) {
+	cluster := &rapi.RedisCluster{}
+	setConfigKeys(cluster, nil, nil, nil)
+	if cluster.Status.Config != nil {
+		t.Errorf("setConfigKeys() status.config = %v, want nil without changes", cluster.Status.Config)
+	}
+
+	setRestartHash(cluster, map[string]string{"databases": "8"})
+	setConfigKeys(cluster, map[string]string{"io-threads": "2", "databases": "8"}, []string{"maxmemory"}, []string{"redis-node-1"})
+	want := &rapi.ConfigStatus{
+		RestartHash:         restartConfigHash(map[string]string{"databases": "8"}),
+		BaselineRestartHash: restartConfigHash(map[string]string{"databases": "8"}),
+		PendingKeys:         []string{"databases", "io-threads"},
+		FailedKeys:          []string{"maxmemory"},
+		DriftedNodes:        []string{"redis-node-1"},
+	}
+	if !reflect.DeepEqual(cluster.Status.Config, want) {
+		t.Errorf("setConfigKeys() status.config = %v, want %v", cluster.Status.Config, want)
+	}
+
+	setConfigKeys(cluster, nil, nil, nil)
+	if cluster.Status.Config.PendingKeys != nil || cluster.Status.Config.FailedKeys != nil || cluster.Status.Config.DriftedNodes != nil {
+		t.Errorf("setConfigKeys() status.config = %v, want the keys cleared", cluster.Status.Config)
+	}
+}
+
+func Test_setRestartHash(t *testing.T) {
+	cluster := &rapi.RedisCluster{}
+	setRestartHash(cluster, map[string]string{"maxmemory": "1gb"})
+	if cluster.Status.Config == nil || cluster.Status.Config.BaselineRestartHash != "" || cluster.Status.Config.RestartHash != "" {
+		t.Fatalf("setRestartHash() status.config = %v, want an empty baseline without restart settings", cluster.Status.Config)
+	}
+	setRestartHash(cluster, map[string]string{"databases": "8"})
+	if want := restartConfigHash(map[string]string{"databases": "8"}); cluster.Status.Config.RestartHash != want || cluster.Status.Config.BaselineRestartHash != "" {
+		t.Errorf("setRestartHash() status.config = %v, want the restart hash %s and the baseline kept", cluster.Status.Config, want)
+	}
+
+	// the restart settings of a cluster seen for the first time are the baseline
+	cluster = &rapi.RedisCluster{}
+	setRestartHash(cluster, map[string]string{"databases": "8"})
+	if want := restartConfigHash(map[string]string{"databases": "8"}); cluster.Status.Config.RestartHash != want || cluster.Status.Config.BaselineRestartHash != want {
+		t.Errorf("setRestartHash() status.config = %v, want the restart hash %s as baseline", cluster.Status.Config, want)
+	}
+}
+
+func Test_checkServerConfig(t *testing.T) {
+	fakeAdmin := admin.NewFakeAdmin()
+	fakeAdmin.GetNodeConfigRet = map[string]map[string]string{
+		"10.0.0.1:6379": {"maxmemory": "1073741824", "maxmemory-policy": "volatile-lfu", "databases": "16"},
+		"10.0.0.2:6379": {"maxmemory": "1073741824", "maxmemory-policy": "allkeys-lru", "databases": "16"},
+	}
+	clusterConfig := map[string]string{"maxmemory": "1gb", "maxmemory-policy": "volatile-lfu", "databases": "16"}
+
+	nodeChanges, err := checkServerConfig(context.Background(), fakeAdmin, clusterConfig)
+	if err != nil {
+		t.Fatalf("checkServerConfig() error = %v", err)
+	}
+	want := map[string]map[string]string{
+		"10.0.0.1:6379": {},
+		"10.0.0.2:6379": {"maxmemory-policy": "volatile-lfu"},
+	}
+	if !reflect.DeepEqual(nodeChanges, want) {
+		t.Errorf("checkServerConfig() = %v, want %v", nodeChanges, want)
+	}
+}
+
+func Test_podNamesByAddr(t *testing.T) {
+	cluster := &rapi.RedisCluster{
+		Status: rapi.RedisClusterStatus{
+			Cluster: rapi.RedisClusterState{
+				Nodes: []rapi.RedisClusterNode{
+					{IP: "10.0.0.1", Port: "6379", PodName: "redis-node-b"},
+					{IP: "10.0.0.2", Port: "6379", PodName: "redis-node-a"},
+				},
+			},
+		},
+	}
+	got := podNamesByAddr(cluster, []string{"10.0.0.1:6379", "10.0.0.3:6379", "10.0.0.2:6379"})
+	want := []string{"10.0.0.3:6379", "redis-node-a", "redis-node-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podNamesByAddr() = %v, want %v", got, want)
+	}
+}
@@ -43,7 +43,7 @@ func NewServicesControl(client client.Client, rec record.EventRecorder) *Service
 // GetRedisClusterService used to retrieve the Kubernetes Service associated to the RedisCluster
 func (s *ServicesControl) GetRedisClusterService(redisCluster *rapi.RedisCluster) (*v1.Service, error) {
 	serviceName := types.NamespacedName{
-		Name:      getServiceName(redisCluster),
+		Name:      redisCluster.GetServiceName(),
 		Namespace: redisCluster.Namespace,
 	}
 	svc := &v1.Service{}
@@ -56,7 +56,7 @@ func (s *ServicesControl) GetRedisClusterService(redisCluster *rapi.RedisCluster
 
 // CreateRedisClusterService used to create the Kubernetes Service needed to access the Redis Cluster
 func (s *ServicesControl) CreateRedisClusterService(redisCluster *rapi.RedisCluster) (*v1.Service, error) {
-	serviceName := getServiceName(redisCluster)
+	serviceName := redisCluster.GetServiceName()
 	desiredLabels, err := pod.GetLabelsSet(redisCluster)
 	if err != nil {
 		return nil, err
@@ -93,17 +93,9 @@ func (s *ServicesControl) CreateRedisClusterService(redisCluster *rapi.RedisClus
 func (s *ServicesControl) DeleteRedisClusterService(redisCluster *rapi.RedisCluster) error {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getServiceName(redisCluster),
+			Name:      redisCluster.GetServiceName(),
 			Namespace: redisCluster.Namespace,
 		},
 	}
 	return s.KubeClient.Delete(context.Background(), svc)
 }
-
-func getServiceName(redisCluster *rapi.RedisCluster) string {
-	serviceName := redisCluster.Name
-	if redisCluster.Spec.ServiceName != "" {
-		serviceName = redisCluster.Spec.ServiceName
-	}
-	return serviceName
-}
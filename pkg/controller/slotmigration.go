@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+// slotMigrationClusterRequest maps a RedisSlotMigration to the reconciliation of its RedisCluster
+func slotMigrationClusterRequest(obj kclient.Object) []reconcile.Request {
+	slotMigration, ok := obj.(*rapi.RedisSlotMigration)
+	if !ok || slotMigration.Spec.ClusterName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: kclient.ObjectKey{Namespace: slotMigration.Namespace, Name: slotMigration.Spec.ClusterName}}}
+}
+
+// getSlotMigrations returns the RedisSlotMigrations of the cluster, oldest first
+func getSlotMigrations(ctx context.Context, kubeClient kclient.Client, cluster *rapi.RedisCluster) ([]rapi.RedisSlotMigration, error) {
+	slotMigrationList := &rapi.RedisSlotMigrationList{}
+	if err := kubeClient.List(ctx, slotMigrationList, kclient.InNamespace(cluster.Namespace)); err != nil {
+		return nil, err
+	}
+	var slotMigrations []rapi.RedisSlotMigration
+	for _, slotMigration := range slotMigrationList.Items {
+		if slotMigration.Spec.ClusterName == cluster.Name {
+			slotMigrations = append(slotMigrations, slotMigration)
+		}
+	}
+	sort.Slice(slotMigrations, func(i, j int) bool {
+		if !slotMigrations[i].CreationTimestamp.Equal(&slotMigrations[j].CreationTimestamp) {
+			return slotMigrations[i].CreationTimestamp.Before(&slotMigrations[j].CreationTimestamp)
+		}
+		return slotMigrations[i].Name < slotMigrations[j].Name
+	})
+	return slotMigrations, nil
+}
+
+// getPinnedSlots returns the destination primary of the slots migrated by the completed RedisSlotMigrations of the
+// cluster, the most recent migration of a slot wins
+func getPinnedSlots(ctx context.Context, kubeClient kclient.Client, cluster *rapi.RedisCluster) (map[redis.Slot]string, error) {
+	slotMigrations, err := getSlotMigrations(ctx, kubeClient, cluster)
+	if err != nil {
+		return nil, err
+	}
+	var completed []rapi.RedisSlotMigration
+	for _, slotMigration := range slotMigrations {
+		if slotMigration.Status.Phase == rapi.SlotMigrationPhaseCompleted && slotMigration.Status.CompletionTime != nil {
+			completed = append(completed, slotMigration)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].Status.CompletionTime.Before(completed[j].Status.CompletionTime)
+	})
+	pinnedSlots := make(map[redis.Slot]string)
+	for _, slotMigration := range completed {
+		for _, slotRange := range slotMigration.Status.Slots {
+			slots, _, _, err := redis.DecodeSlotRange(slotRange.Slots)
+			if err != nil {
+				return nil, err
+			}
+			for _, slot := range slots {
+				pinnedSlots[slot] = slotMigration.Status.DestinationID
+			}
+		}
+	}
+	return pinnedSlots, nil
+}
+
+// reconcileSlotMigrations runs the next step of the oldest unfinished RedisSlotMigration of the cluster,
+// it returns true while a migration is in progress
+func (c *Controller) reconcileSlotMigrations(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, infos *redis.ClusterInfos) (bool, error) {
+	slotMigrations, err := getSlotMigrations(ctx, c.client, cluster)
+	if err != nil {
+		return false, err
+	}
+	for i := range slotMigrations {
+		slotMigration := &slotMigrations[i]
+		switch slotMigration.Status.Phase {
+		case rapi.SlotMigrationPhaseCompleted, rapi.SlotMigrationPhaseFailed:
+			continue
+		case rapi.SlotMigrationPhaseRunning:
+			return true, c.runSlotMigration(ctx, admin, cluster, infos.GetNodes(), slotMigration)
+		default:
+			return true, c.startSlotMigration(ctx, cluster, infos.GetNodes(), slotMigration, admin.GetHashMaxSlot())
+		}
+	}
+	return false, nil
+}
+
+// startSlotMigration resolves the destination primary, and splits the slots in ranges owned by the same source primary
+func (c *Controller) startSlotMigration(ctx context.Context, cluster *rapi.RedisCluster, nodes redis.Nodes, slotMigration *rapi.RedisSlotMigration, maxSlot redis.Slot) error {
+	dest, err := getSlotMigrationDestination(nodes, cluster.Status.Cluster.Nodes, slotMigration.Spec.Destination)
+	if err != nil {
+		return c.failSlotMigration(ctx, slotMigration, err.Error())
+	}
+	var slots redis.SlotSlice
+	for _, slotRange := range slotMigration.Spec.Slots {
+		rangeSlots, importing, migrating, err := redis.DecodeSlotRange(slotRange)
+		if err != nil || importing != nil || migrating != nil || len(rangeSlots) == 0 || rangeSlots[len(rangeSlots)-1] > maxSlot {
+			return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("invalid slot range %q", slotRange))
+		}
+		slots = redis.AddSlots(slots, rangeSlots)
+	}
+	sort.Sort(slots)
+	slotOwners := make(map[redis.Slot]*redis.Node)
+	for _, node := range nodes.FilterByFunc(redis.IsPrimaryWithSlot) {
+		for _, slot := range node.Slots {
+			slotOwners[slot] = node
+		}
+	}
+
+	now := metav1.Now()
+	status := &slotMigration.Status
+	status.Phase = rapi.SlotMigrationPhaseRunning
+	status.StartTime = &now
+	status.Message = ""
+	status.DestinationID = dest.ID
+	status.TotalSlots = int32(len(slots))
+	status.MigratedSlots = 0
+	status.Slots = planSlotMigration(slots, slotOwners, dest, int(*cluster.Spec.Scaling.SlotBatchSize))
+	for _, slotRange := range status.Slots {
+		if slotRange.Phase == rapi.SlotMigrationPhaseCompleted {
+			rangeSlots, _, _, _ := redis.DecodeSlotRange(slotRange.Slots)
+			status.MigratedSlots += int32(len(rangeSlots))
+		}
+	}
+	if err = c.client.Status().Update(ctx, slotMigration); err != nil {
+		return err
+	}
+	c.recorder.Eventf(slotMigration, v1.EventTypeNormal, "SlotMigrationStarted", "Migration of %d slots of RedisCluster %s to %s started", len(slots), cluster.Name, dest.ID)
+	return nil
+}
+
+// planSlotMigration splits the slots in ranges of at most batchSize slots owned by the same source primary,
+// the slots already owned by the destination are completed
+func planSlotMigration(slots redis.SlotSlice, slotOwners map[redis.Slot]*redis.Node, dest *redis.Node, batchSize int) []rapi.SlotRangeMigrationStatus {
+	var plan []rapi.SlotRangeMigrationStatus
+	var rangeSlots redis.SlotSlice
+	sourceID := ""
+	flush := func() {
+		if len(rangeSlots) == 0 {
+			return
+		}
+		slotRange := rapi.SlotRangeMigrationStatus{Slots: redis.SlotRangesFromSlots(rangeSlots)[0].String(), SourceID: sourceID, Phase: rapi.SlotMigrationPhasePending}
+		if sourceID == dest.ID {
+			slotRange.Phase = rapi.SlotMigrationPhaseCompleted
+			slotRange.Message = "already owned by the destination"
+		} else if sourceID == "" {
+			slotRange.Phase = rapi.SlotMigrationPhaseFailed
+			slotRange.Message = "not assigned to a primary"
+		}
+		plan = append(plan, slotRange)
+		rangeSlots = nil
+	}
+	for _, slot := range slots {
+		owner := ""
+		if node, ok := slotOwners[slot]; ok {
+			owner = node.ID
+		}
+		if len(rangeSlots) > 0 && (owner != sourceID || slot != rangeSlots[len(rangeSlots)-1]+1 || len(rangeSlots) >= batchSize) {
+			flush()
+		}
+		sourceID = owner
+		rangeSlots = append(rangeSlots, slot)
+	}
+	flush()
+	return plan
+}
+
+// runSlotMigration migrates the next slot range with the scaling settings of the cluster,
+// and completes the migration once all the slot ranges are migrated
+func (c *Controller) runSlotMigration(ctx context.Context, admin redis.AdminInterface, cluster *rapi.RedisCluster, nodes redis.Nodes, slotMigration *rapi.RedisSlotMigration) error {
+	status := &slotMigration.Status
+	for _, slotRange := range status.Slots {
+		if slotRange.Phase == rapi.SlotMigrationPhaseFailed {
+			return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("slots %s %s", slotRange.Slots, slotRange.Message))
+		}
+	}
+	var slotRange *rapi.SlotRangeMigrationStatus
+	for i := range status.Slots {
+		if status.Slots[i].Phase == rapi.SlotMigrationPhasePending {
+			slotRange = &status.Slots[i]
+			break
+		}
+	}
+	if slotRange == nil {
+		now := metav1.Now()
+		status.Phase = rapi.SlotMigrationPhaseCompleted
+		status.CompletionTime = &now
+		if err := c.client.Status().Update(ctx, slotMigration); err != nil {
+			return err
+		}
+		c.recorder.Eventf(slotMigration, v1.EventTypeNormal, "SlotMigrationCompleted", "Migration of %d slots of RedisCluster %s completed", status.TotalSlots, cluster.Name)
+		return nil
+	}
+
+	dest, err := nodes.GetNodeByID(status.DestinationID)
+	if err != nil || !redis.IsPrimaryWithSlot(dest) && !redis.IsPrimaryWithNoSlot(dest) {
+		return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("destination %s is not a primary of the cluster anymore", status.DestinationID))
+	}
+	source, err := nodes.GetNodeByID(slotRange.SourceID)
+	if err != nil {
+		return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("source %s of slots %s is not part of the cluster anymore", slotRange.SourceID, slotRange.Slots))
+	}
+	slots, _, _, err := redis.DecodeSlotRange(slotRange.Slots)
+	if err != nil {
+		return c.failSlotMigration(ctx, slotMigration, err.Error())
+	}
+	for _, slot := range slots {
+		if !redis.Contains(source.Slots, slot) {
+			return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("slot %d is not owned by %s anymore", slot, source.ID))
+		}
+	}
+
+	glog.Infof("migrating slots %s of RedisCluster %s/%s from %s to %s", slotRange.Slots, cluster.Namespace, cluster.Name, source.ID, dest.ID)
+	progress := &redis.MigrationProgress{}
+	progress.AddPlannedSlots(int32(len(slots)))
+	err = admin.MigrateKeys(redis.WithMigrationProgress(ctx, progress), source, dest, slots, &cluster.Spec, true, true, nodes.FilterByFunc(redis.IsPrimaryWithSlot))
+	recordMigrationMetrics(cluster, progress.Snapshot(), time.Now())
+	if err != nil {
+		// the slots with keys left on the source are not reassigned, they remain owned by the source
+		slotRange.Phase = rapi.SlotMigrationPhaseFailed
+		slotRange.Message = err.Error()
+		status.MigratedSlots += int32(len(redis.RemoveSlots(slots, source.Slots)))
+		return c.failSlotMigration(ctx, slotMigration, fmt.Sprintf("unable to migrate slots %s: %v", slotRange.Slots, err))
+	}
+	slotRange.Phase = rapi.SlotMigrationPhaseCompleted
+	status.MigratedSlots += int32(len(slots))
+	return c.client.Status().Update(ctx, slotMigration)
+}
+
+// getSlotMigrationDestination returns the primary node matching the destination, by node ID or by the pod name
+// recorded in the cluster status
+func getSlotMigrationDestination(nodes redis.Nodes, clusterNodes []rapi.RedisClusterNode, destination rapi.SlotMigrationDestination) (*redis.Node, error) {
+	if (destination.NodeID == "") == (destination.PodName == "") {
+		return nil, fmt.Errorf("exactly one of destination.nodeID and destination.podName must be set")
+	}
+	nodeID := destination.NodeID
+	if destination.PodName != "" {
+		for _, clusterNode := range clusterNodes {
+			if clusterNode.PodName == destination.PodName {
+				nodeID = clusterNode.ID
+				break
+			}
+		}
+		if nodeID == "" {
+			return nil, fmt.Errorf("destination pod %s is not part of the cluster", destination.PodName)
+		}
+	}
+	node, err := nodes.GetNodeByID(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("destination %s is not part of the cluster", nodeID)
+	}
+	if !redis.IsPrimaryWithSlot(node) && !redis.IsPrimaryWithNoSlot(node) {
+		return nil, fmt.Errorf("destination %s is not a primary", node.ID)
+	}
+	return node, nil
+}
+
+func (c *Controller) failSlotMigration(ctx context.Context, slotMigration *rapi.RedisSlotMigration, message string) error {
+	glog.Errorf("RedisSlotMigration %s/%s failed: %s", slotMigration.Namespace, slotMigration.Name, message)
+	now := metav1.Now()
+	slotMigration.Status.Phase = rapi.SlotMigrationPhaseFailed
+	slotMigration.Status.CompletionTime = &now
+	slotMigration.Status.Message = message
+	if err := c.client.Status().Update(ctx, slotMigration); err != nil {
+		return err
+	}
+	c.recorder.Event(slotMigration, v1.EventTypeWarning, "SlotMigrationFailed", message)
+	return nil
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
+)
+
+func Test_planSlotMigration(t *testing.T) {
+	primary1 := &redis.Node{ID: "primary1", Slots: redis.SlotSlice{0, 1, 2, 3, 4}}
+	primary2 := &redis.Node{ID: "primary2", Slots: redis.SlotSlice{5, 6, 7}}
+	slotOwners := map[redis.Slot]*redis.Node{}
+	for _, node := range []*redis.Node{primary1, primary2} {
+		for _, slot := range node.Slots {
+			slotOwners[slot] = node
+		}
+	}
+
+	got := planSlotMigration(redis.SlotSlice{0, 1, 2, 4, 5, 6, 9}, slotOwners, primary2, 2)
+	want := []rapi.SlotRangeMigrationStatus{
+		{Slots: "0-1", SourceID: "primary1", Phase: rapi.SlotMigrationPhasePending},
+		{Slots: "2-2", SourceID: "primary1", Phase: rapi.SlotMigrationPhasePending},
+		{Slots: "4-4", SourceID: "primary1", Phase: rapi.SlotMigrationPhasePending},
+		{Slots: "5-6", SourceID: "primary2", Phase: rapi.SlotMigrationPhaseCompleted, Message: "already owned by the destination"},
+		{Slots: "9-9", Phase: rapi.SlotMigrationPhaseFailed, Message: "not assigned to a primary"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planSlotMigration() = %v, want %v", got, want)
+	}
+}
+
+func TestController_reconcileSlotMigrations(t *testing.T) {
+	batchSize := int32(10)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+		Spec:       rapi.RedisClusterSpec{Scaling: &rapi.Scaling{Migration: rapi.Migration{SlotBatchSize: &batchSize}}},
+		Status: rapi.RedisClusterStatus{Cluster: rapi.RedisClusterState{Nodes: []rapi.RedisClusterNode{
+			{ID: "primary1", PodName: "pod1"},
+			{ID: "primary2", PodName: "pod2"},
+		}}},
+	}
+	slotMigration := &rapi.RedisSlotMigration{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "move"},
+		Spec: rapi.RedisSlotMigrationSpec{
+			ClusterName: "cluster",
+			Slots:       []string{"2-3"},
+			Destination: rapi.SlotMigrationDestination{PodName: "pod2"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(slotMigration).Build()
+	c := &Controller{client: fakeClient, recorder: record.NewFakeRecorder(10)}
+	fakeAdmin := admin.NewFakeAdmin()
+	infos := &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+		"primary1": {Node: &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Role: "master", Slots: redis.SlotSlice{0, 1, 2, 3}}},
+		"primary2": {Node: &redis.Node{ID: "primary2", IP: "10.0.0.2", Port: "6379", Role: "master", Slots: redis.SlotSlice{4, 5}}},
+	}}
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "ns", Name: "move"}
+
+	// start, migrate the single range, then complete
+	for i := 0; i < 3; i++ {
+		migrating, err := c.reconcileSlotMigrations(ctx, fakeAdmin, cluster, infos)
+		if err != nil || !migrating {
+			t.Fatalf("reconcileSlotMigrations() = %v, %v, want a migration in progress", migrating, err)
+		}
+	}
+	if err := fakeClient.Get(ctx, key, slotMigration); err != nil {
+		t.Fatalf("unable to get the slot migration: %v", err)
+	}
+	status := slotMigration.Status
+	if status.Phase != rapi.SlotMigrationPhaseCompleted || status.DestinationID != "primary2" || status.TotalSlots != 2 || status.MigratedSlots != 2 {
+		t.Errorf("reconcileSlotMigrations() unexpected status: %v", status)
+	}
+	if migrating, err := c.reconcileSlotMigrations(ctx, fakeAdmin, cluster, infos); err != nil || migrating {
+		t.Errorf("reconcileSlotMigrations() = %v, %v, want no migration in progress", migrating, err)
+	}
+
+	pinnedSlots, err := getPinnedSlots(ctx, fakeClient, cluster)
+	if err != nil {
+		t.Fatalf("getPinnedSlots() unexpected error: %v", err)
+	}
+	if want := map[redis.Slot]string{2: "primary2", 3: "primary2"}; !reflect.DeepEqual(pinnedSlots, want) {
+		t.Errorf("getPinnedSlots() = %v, want %v", pinnedSlots, want)
+	}
+}
+
+func TestController_reconcileSlotMigrations_keysLeft(t *testing.T) {
+	batchSize := int32(10)
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"},
+		Spec:       rapi.RedisClusterSpec{Scaling: &rapi.Scaling{Migration: rapi.Migration{SlotBatchSize: &batchSize}}},
+	}
+	slotMigration := &rapi.RedisSlotMigration{
+		ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "move", CreationTimestamp: kmetav1.NewTime(time.Now())},
+		Spec: rapi.RedisSlotMigrationSpec{
+			ClusterName: "cluster",
+			Slots:       []string{"0"},
+			Destination: rapi.SlotMigrationDestination{NodeID: "primary2"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(slotMigration).Build()
+	c := &Controller{client: fakeClient, recorder: record.NewFakeRecorder(10)}
+	fakeAdmin := admin.NewFakeAdmin()
+	fakeAdmin.AddrError["10.0.0.1:6379"] = fmt.Errorf("unable to migrate the keys of slots [0], they are left on 10.0.0.1:6379")
+	infos := &redis.ClusterInfos{Infos: map[string]*redis.NodeInfos{
+		"primary1": {Node: &redis.Node{ID: "primary1", IP: "10.0.0.1", Port: "6379", Role: "master", Slots: redis.SlotSlice{0}}},
+		"primary2": {Node: &redis.Node{ID: "primary2", IP: "10.0.0.2", Port: "6379", Role: "master", Slots: redis.SlotSlice{1}}},
+	}}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.reconcileSlotMigrations(ctx, fakeAdmin, cluster, infos); err != nil {
+			t.Fatalf("reconcileSlotMigrations() unexpected error: %v", err)
+		}
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "move"}, slotMigration); err != nil {
+		t.Fatalf("unable to get the slot migration: %v", err)
+	}
+	if slotMigration.Status.Phase != rapi.SlotMigrationPhaseFailed || slotMigration.Status.Slots[0].Phase != rapi.SlotMigrationPhaseFailed || slotMigration.Status.MigratedSlots != 0 || !strings.Contains(slotMigration.Status.Slots[0].Message, "left on") {
+		t.Errorf("reconcileSlotMigrations() should fail when keys are left on the source, got %v", slotMigration.Status)
+	}
+}
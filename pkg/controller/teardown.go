@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+const (
+	// slotMigrationTimeout maximum duration the teardown waits for the slot migrations in progress
+	slotMigrationTimeout = 5 * time.Minute
+)
+
+// addTeardownFinalizer adds the teardown finalizer to a RedisCluster, only the metadata of the RedisCluster is patched
+func (c *Controller) addTeardownFinalizer(ctx context.Context, cluster *rapi.RedisCluster) error {
+	patch := kclient.MergeFrom(cluster.DeepCopy())
+	controllerutil.AddFinalizer(cluster, rapi.TeardownFinalizer)
+	return c.client.Patch(ctx, cluster, patch)
+}
+
+// setDeletionBlocked sets the DeletionBlocked condition of a cluster deleted while deletionProtection is set,
+// the event is only emitted when the condition is set. It returns true if the condition was not set yet.
+func (c *Controller) setDeletionBlocked(cluster *rapi.RedisCluster) bool {
+	if hasCondition(&cluster.Status, rapi.RedisClusterDeletionBlocked) {
+		return false
+	}
+	setDeletionBlockedCondition(&cluster.Status, true)
+	c.recorder.Event(cluster, v1.EventTypeWarning, "DeletionBlocked", "Deletion blocked by spec.deletionProtection, the teardown starts once it is cleared")
+	return true
+}
+
+// teardown deletes the resources of a deleted RedisCluster step by step: the final backup is taken,
+// the slot migrations in progress are completed, then the pods, service, pod disruption budget and
+// config map are deleted, and the finalizer is removed
+func (c *Controller) teardown(ctx context.Context, cluster *rapi.RedisCluster) (ctrl.Result, error) {
+	result := ctrl.Result{}
+	if !controllerutil.ContainsFinalizer(cluster, rapi.TeardownFinalizer) {
+		return result, nil
+	}
+	if cluster.Spec.Paused {
+		glog.V(3).Infof("reconciliation of RedisCluster %s/%s paused, waiting to tear it down", cluster.Namespace, cluster.Name)
+		return result, nil
+	}
+
+	if cluster.Spec.FinalBackup != nil {
+		completed, err := c.finalBackup(ctx, cluster)
+		if err != nil || !completed {
+			return ctrl.Result{RequeueAfter: requeueDelay}, err
+		}
+	}
+
+	pods, err := c.podControl.GetRedisClusterPods(cluster)
+	if err != nil {
+		return result, err
+	}
+	if len(pods) > 0 {
+		if !podsTerminating(pods) && c.waitSlotMigrations(ctx, cluster, pods) {
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+		var errs []error
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			if err = c.podControl.DeletePod(cluster, pod.Name); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+		}
+		return ctrl.Result{RequeueAfter: requeueDelay}, utilerrors.NewAggregate(errs)
+	}
+
+	if err = c.deleteClusterResources(ctx, cluster); err != nil {
+		return result, err
+	}
+	c.recorder.Event(cluster, v1.EventTypeNormal, "TeardownCompleted", "Pods, service, pod disruption budget and config map deleted")
+	patch := kclient.MergeFrom(cluster.DeepCopy())
+	controllerutil.RemoveFinalizer(cluster, rapi.TeardownFinalizer)
+	return result, c.client.Patch(ctx, cluster, patch)
+}
+
+// finalBackup creates the RedisClusterBackup taken before the pods are deleted, it returns true once the backup is completed.
+// A failed backup blocks the teardown until spec.finalBackup is cleared.
+func (c *Controller) finalBackup(ctx context.Context, cluster *rapi.RedisCluster) (bool, error) {
+	name := fmt.Sprintf("%s-final-%d", cluster.Name, cluster.DeletionTimestamp.Unix())
+	redisClusterBackup := &rapi.RedisClusterBackup{}
+	err := c.client.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, redisClusterBackup)
+	if errors.IsNotFound(err) {
+		// the backup is not owned by the cluster, so that it is not garbage collected along with it
+		redisClusterBackup = &rapi.RedisClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: name},
+			Spec: rapi.RedisClusterBackupSpec{
+				ClusterName: cluster.Name,
+				Storage:     *cluster.Spec.FinalBackup.DeepCopy(),
+			},
+		}
+		if err = c.client.Create(ctx, redisClusterBackup); err != nil {
+			return false, err
+		}
+		c.recorder.Eventf(cluster, v1.EventTypeNormal, "FinalBackupStarted", "Final backup %s started", name)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	switch redisClusterBackup.Status.Phase {
+	case rapi.BackupPhaseCompleted:
+		return true, nil
+	case rapi.BackupPhaseFailed:
+		c.recorder.Eventf(cluster, v1.EventTypeWarning, "FinalBackupFailed", "Final backup %s failed: %s, clear spec.finalBackup to delete the cluster without backup", name, redisClusterBackup.Status.Message)
+	}
+	return false, nil
+}
+
+// waitSlotMigrations returns true while slots are migrated between the redis nodes,
+// the teardown stops waiting once slotMigrationTimeout has elapsed since the deletion of the cluster
+func (c *Controller) waitSlotMigrations(ctx context.Context, cluster *rapi.RedisCluster, pods []v1.Pod) bool {
+	migrating, err := c.hasSlotMigrations(ctx, cluster, pods)
+	if err == nil && !migrating {
+		return false
+	}
+	if time.Since(cluster.DeletionTimestamp.Time) > slotMigrationTimeout {
+		c.recorder.Eventf(cluster, v1.EventTypeWarning, "SlotMigrationTimeout", "Slot migrations not completed after %v, deleting the pods", slotMigrationTimeout)
+		return false
+	}
+	if err != nil {
+		glog.Errorf("unable to check the slot migrations of RedisCluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+	}
+	return true
+}
+
+func (c *Controller) hasSlotMigrations(ctx context.Context, cluster *rapi.RedisCluster, pods []v1.Pod) (bool, error) {
+	redisConfig, err := getRedisClusterConfig(c.client, c.config.redis, cluster)
+	if err != nil {
+		return false, err
+	}
+	admin, err := redis.NewRedisAdmin(ctx, pods, redisConfig)
+	if err != nil {
+		return false, fmt.Errorf("unable to create the redis.Admin, err:%v", err)
+	}
+	defer admin.Close()
+	clusterInfos, err := admin.GetClusterInfos(ctx)
+	if err != nil {
+		return false, err
+	}
+	return nodesMigratingSlots(clusterInfos.GetNodes()), nil
+}
+
+// nodesMigratingSlots returns true if a node is migrating or importing slots
+func nodesMigratingSlots(nodes redis.Nodes) bool {
+	for _, node := range nodes {
+		if len(node.MigratingSlots) > 0 || len(node.ImportingSlots) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func podsTerminating(pods []v1.Pod) bool {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteClusterResources deletes the service, pod disruption budget and config map of the cluster
+func (c *Controller) deleteClusterResources(ctx context.Context, cluster *rapi.RedisCluster) error {
+	var errs []error
+	if err := c.serviceControl.DeleteRedisClusterService(cluster); err != nil && !errors.IsNotFound(err) {
+		errs = append(errs, err)
+	}
+	if err := c.podDisruptionBudgetControl.DeleteRedisClusterPodDisruptionBudget(cluster); err != nil && !errors.IsNotFound(err) {
+		errs = append(errs, err)
+	}
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name}}
+	if err := c.client.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/controller/pod"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+)
+
+func TestController_teardown(t *testing.T) {
+	now := kmetav1.Now()
+	cluster := &rapi.RedisCluster{
+		ObjectMeta: kmetav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "cluster",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{rapi.TeardownFinalizer},
+		},
+		Spec: rapi.RedisClusterSpec{
+			FinalBackup: &rapi.BackupStorage{Local: &rapi.LocalBackupStorage{Path: "/backups"}},
+		},
+	}
+	configMap := &kapiv1.ConfigMap{ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"}}
+	service := &kapiv1.Service{ObjectMeta: kmetav1.ObjectMeta{Namespace: "ns", Name: "cluster"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, configMap, service).Build()
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{
+		client:                     fakeClient,
+		recorder:                   recorder,
+		podControl:                 pod.NewRedisClusterControl(fakeClient, recorder),
+		serviceControl:             NewServicesControl(fakeClient, recorder),
+		podDisruptionBudgetControl: NewPodDisruptionBudgetsControl(fakeClient, recorder),
+	}
+	ctx := context.Background()
+
+	result, err := c.teardown(ctx, cluster)
+	if err != nil || result.RequeueAfter == 0 {
+		t.Fatalf("teardown() = %v, %v, want to wait for the final backup", result, err)
+	}
+	backups := &rapi.RedisClusterBackupList{}
+	if err = fakeClient.List(ctx, backups); err != nil || len(backups.Items) != 1 {
+		t.Fatalf("teardown() should create the final backup, got %v, %v", backups.Items, err)
+	}
+	finalBackup := &backups.Items[0]
+	if finalBackup.Spec.ClusterName != "cluster" || finalBackup.Spec.Storage.Local == nil || len(finalBackup.OwnerReferences) != 0 {
+		t.Errorf("teardown() unexpected final backup: %v", finalBackup)
+	}
+
+	finalBackup.Status.Phase = rapi.BackupPhaseCompleted
+	if err = fakeClient.Update(ctx, finalBackup); err != nil {
+		t.Fatalf("unable to update the final backup: %v", err)
+	}
+	if _, err = c.teardown(ctx, cluster); err != nil {
+		t.Fatalf("teardown() unexpected error: %v", err)
+	}
+	if err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, configMap); !errors.IsNotFound(err) {
+		t.Errorf("teardown() should delete the config map, got %v", err)
+	}
+	if err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, service); !errors.IsNotFound(err) {
+		t.Errorf("teardown() should delete the service, got %v", err)
+	}
+	deleted := &rapi.RedisCluster{}
+	if err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, deleted); err == nil && len(deleted.Finalizers) != 0 {
+		t.Errorf("teardown() should remove the finalizer, got %v", deleted.Finalizers)
+	}
+}
+
+func TestController_setDeletionBlocked(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+	cluster := &rapi.RedisCluster{}
+
+	if !c.setDeletionBlocked(cluster) || !hasCondition(&cluster.Status, rapi.RedisClusterDeletionBlocked) {
+		t.Errorf("Controller.setDeletionBlocked() should set the DeletionBlocked condition")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "DeletionBlocked") {
+		t.Errorf("Controller.setDeletionBlocked() event = %q, want a DeletionBlocked event", event)
+	}
+	if c.setDeletionBlocked(cluster) || len(recorder.Events) != 0 {
+		t.Errorf("Controller.setDeletionBlocked() should emit a single event while the deletion is blocked")
+	}
+}
+
+func Test_nodesMigratingSlots(t *testing.T) {
+	nodes := redis.Nodes{
+		{ID: "primary1", MigratingSlots: map[redis.Slot]string{}},
+		{ID: "primary2", ImportingSlots: map[redis.Slot]string{}},
+	}
+	if nodesMigratingSlots(nodes) {
+		t.Errorf("nodesMigratingSlots() should return false without slot migrations")
+	}
+	nodes[1].ImportingSlots[42] = "primary1"
+	if !nodesMigratingSlots(nodes) {
+		t.Errorf("nodesMigratingSlots() should return true when a node imports slots")
+	}
+}
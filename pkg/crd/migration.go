@@ -0,0 +1,111 @@
+// Package crd migrates the RedisClusters stored in a previous version of the RedisCluster CustomResourceDefinition
+// to its v1beta1 storage version
+package crd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/IBM/operator-for-redis-cluster/api/v1beta1"
+)
+
+const (
+	// RedisClusterCRDName name of the RedisCluster CustomResourceDefinition
+	RedisClusterCRDName = v1beta1.ResourcePlural + "." + v1beta1.GroupName
+	// RetryInterval interval between two attempts of the migration
+	RetryInterval = 10 * time.Second
+)
+
+var _ manager.LeaderElectionRunnable = &StorageVersionMigrator{}
+
+// StorageVersionMigrator rewrites the RedisClusters stored in a previous version once v1beta1 is the storage version
+// of the RedisCluster CustomResourceDefinition, then removes the previous versions from its stored versions.
+// The conversion webhook and the storage version are set by the CustomResourceDefinition of the chart.
+type StorageVersionMigrator struct {
+	kubeClient client.Client
+	reader     client.Reader
+}
+
+// NewStorageVersionMigrator initializes and returns a StorageVersionMigrator, the reader must not be backed by a cache
+func NewStorageVersionMigrator(kubeClient client.Client, reader client.Reader) *StorageVersionMigrator {
+	return &StorageVersionMigrator{
+		kubeClient: kubeClient,
+		reader:     reader,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, a single operator migrates the RedisClusters
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable, the migration is retried until it succeeds
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	err := wait.PollImmediateUntilWithContext(ctx, RetryInterval, func(ctx context.Context) (bool, error) {
+		if err := m.Migrate(ctx); err != nil {
+			glog.Errorf("unable to migrate the RedisCluster storage version: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// Migrate rewrites the RedisClusters stored in a previous version, and records v1beta1 as the only stored version
+func (m *StorageVersionMigrator) Migrate(ctx context.Context) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.reader.Get(ctx, types.NamespacedName{Name: RedisClusterCRDName}, crd); err != nil {
+		return err
+	}
+	if storageVersion(crd) != v1beta1.ResourceVersion || crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != apiextensionsv1.WebhookConverter {
+		return fmt.Errorf("%s does not store %s with the conversion webhook, the CustomResourceDefinition of the chart must be applied with the webhooks enabled", RedisClusterCRDName, v1beta1.ResourceVersion)
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == v1beta1.ResourceVersion {
+		return nil
+	}
+
+	// an update without changes is enough to store a RedisCluster in the storage version
+	redisClusters := &v1beta1.RedisClusterList{}
+	if err := m.reader.List(ctx, redisClusters); err != nil {
+		return err
+	}
+	for _, redisCluster := range redisClusters.Items {
+		key := types.NamespacedName{Namespace: redisCluster.Namespace, Name: redisCluster.Name}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latest := &v1beta1.RedisCluster{}
+			if err := m.reader.Get(ctx, key, latest); err != nil {
+				return err
+			}
+			return m.kubeClient.Update(ctx, latest)
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to migrate RedisCluster %s: %v", key, err)
+		}
+	}
+	glog.Infof("migrated %d RedisClusters to %s", len(redisClusters.Items), v1beta1.ResourceVersion)
+
+	crd.Status.StoredVersions = []string{v1beta1.ResourceVersion}
+	return m.kubeClient.Status().Update(ctx, crd)
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package crd
+
+import (
+	"context"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	cfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/IBM/operator-for-redis-cluster/api/v1beta1"
+)
+
+func newRedisClusterCRD(storage string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: RedisClusterCRDName},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.WebhookConverter},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: storage == "v1alpha1"},
+				{Name: "v1beta1", Served: true, Storage: storage == "v1beta1"},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func TestStorageVersionMigrator_Migrate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	fakeClient := cfake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newRedisClusterCRD("v1beta1", "v1alpha1", "v1beta1"),
+		&v1beta1.RedisCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cluster"}},
+	).Build()
+	migrator := NewStorageVersionMigrator(fakeClient, fakeClient)
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() unexpected error: %v", err)
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: RedisClusterCRDName}, crd); err != nil {
+		t.Fatalf("unable to get the CustomResourceDefinition: %v", err)
+	}
+	if len(crd.Status.StoredVersions) != 1 || crd.Status.StoredVersions[0] != v1beta1.ResourceVersion {
+		t.Errorf("Migrate() stored versions = %v, want [v1beta1]", crd.Status.StoredVersions)
+	}
+	redisCluster := &v1beta1.RedisCluster{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "cluster"}, redisCluster); err != nil {
+		t.Fatalf("unable to get the RedisCluster: %v", err)
+	}
+	if redisCluster.ResourceVersion == "1" {
+		t.Errorf("Migrate() should rewrite the RedisCluster")
+	}
+
+	alphaClient := cfake.NewClientBuilder().WithScheme(scheme).WithObjects(newRedisClusterCRD("v1alpha1", "v1alpha1")).Build()
+	if err := NewStorageVersionMigrator(alphaClient, alphaClient).Migrate(ctx); err == nil {
+		t.Errorf("Migrate() should return an error when v1alpha1 is the storage version")
+	}
+
+	noWebhookCRD := newRedisClusterCRD("v1beta1", "v1alpha1", "v1beta1")
+	noWebhookCRD.Spec.Conversion = nil
+	noWebhookClient := cfake.NewClientBuilder().WithScheme(scheme).WithObjects(noWebhookCRD).Build()
+	if err := NewStorageVersionMigrator(noWebhookClient, noWebhookClient).Migrate(ctx); err == nil {
+		t.Errorf("Migrate() should return an error without the conversion webhook")
+	}
+}
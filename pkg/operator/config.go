@@ -14,7 +14,11 @@ type Config struct {
 	KubeAPIServer         string
 	HealthCheckAddr       string
 	MetricsAddr           string
+	WebhookEnabled        bool
+	WebhookPort           int
+	WebhookCertDir        string
 	Redis                 config.Redis
+	Tracing               config.Tracing
 }
 
 // NewRedisOperatorConfig builds and returns a redis-operator Config
@@ -36,5 +40,9 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.KubeAPIServer, "kube-api-server", c.KubeAPIServer, "Address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	fs.StringVar(&c.HealthCheckAddr, "health-check-addr", "0.0.0.0:8086", "Listen address of the http server which serves kubernetes probes")
 	fs.StringVar(&c.MetricsAddr, "metricsAddr", "0.0.0.0:2112", "Listen address of the metrics server which serves controller metrics")
+	fs.BoolVar(&c.WebhookEnabled, "webhook-enabled", false, "Serve the RedisCluster defaulting and validating admission webhooks")
+	fs.IntVar(&c.WebhookPort, "webhook-port", 9443, "Listen port of the admission webhook server")
+	fs.StringVar(&c.WebhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Folder containing the tls.crt and tls.key files of the admission webhook server")
 	c.Redis.AddFlags(fs)
+	c.Tracing.AddFlags(fs)
 }
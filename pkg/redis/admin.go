@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"math"
 	"net"
@@ -39,6 +40,8 @@ type AdminInterface interface {
 	DetachReplica(ctx context.Context, replica *Node) error
 	// StartFailover executes the failover of a redis primary with the corresponding addr
 	StartFailover(ctx context.Context, addr string) error
+	// Failover executes CLUSTER FAILOVER on the replica with the corresponding addr, option is empty, FORCE or TAKEOVER
+	Failover(ctx context.Context, addr string, option string) error
 	// ForgetNode forces the cluster to forget a node
 	ForgetNode(ctx context.Context, id string) error
 	// ForgetNodeByAddr forces the cluster to forget the node with the specified address
@@ -55,11 +58,14 @@ type AdminInterface interface {
 	GetKeysInSlot(ctx context.Context, addr string, slot Slot, batch string, limit bool) ([]string, error)
 	// CountKeysInSlot counts the keys in a given slot on the node
 	CountKeysInSlot(ctx context.Context, addr string, slot Slot) (int64, error)
+	// GetMemoryUsage gets the number of bytes used by a key and its value on the node
+	GetMemoryUsage(ctx context.Context, addr string, key string) (int64, error)
 	// GetKeys gets keys in a slot
 	GetKeys(ctx context.Context, addr string, slot Slot, batch string) ([]string, error)
 	// DeleteKeys deletes keys
 	DeleteKeys(ctx context.Context, addr string, keys []string) error
-	// MigrateKeys migrates keys from the source to destination node and returns number of slot migrated
+	// MigrateKeys migrates keys from the source to destination node, the slots whose keys could not be migrated are left
+	// on the source and listed in the returned error
 	MigrateKeys(ctx context.Context, source *Node, dest *Node, slots SlotSlice, spec *rapi.RedisClusterSpec, replace, scaling bool, primaries Nodes) error
 	// FlushAndReset flushes and resets the cluster configuration of the node
 	FlushAndReset(ctx context.Context, addr string, mode string) error
@@ -73,6 +79,20 @@ type AdminInterface interface {
 	GetConfig(ctx context.Context, pattern string) (map[string]string, error)
 	// SetConfig sets the specified redis server configuration
 	SetConfig(ctx context.Context, addr string, config []string) error
+	// GetNodeConfig gets the redis server configuration of the node matching the pattern
+	GetNodeConfig(ctx context.Context, addr string, pattern string) (map[string]string, error)
+	// GetClusterConfig gets the redis server configuration matching the pattern of all the nodes, by node address
+	GetClusterConfig(ctx context.Context, pattern string) (map[string]map[string]string, error)
+	// GetInfo gets a section of the redis server information of the node
+	GetInfo(ctx context.Context, addr string, section string) (map[string]string, error)
+	// GetClusterState gets the fields of the CLUSTER INFO command of the node, such as cluster_state
+	GetClusterState(ctx context.Context, addr string) (map[string]string, error)
+	// GetInfos gets a section of the redis server information of the nodes, by node address
+	GetInfos(ctx context.Context, addrs []string, section string) (map[string]map[string]string, error)
+	// BackgroundSave saves the dataset of the node in its RDB file in the background
+	BackgroundSave(ctx context.Context, addr string) error
+	// RestoreKeys restores serialized keys on the node in a pipeline
+	RestoreKeys(ctx context.Context, addr string, entries []DumpEntry) error
 }
 
 // AdminOptions optional options for redis admin
@@ -80,12 +100,15 @@ type AdminOptions struct {
 	ConnectionTimeout  time.Duration
 	ClientName         string
 	RenameCommandsFile string
+	Auth               Auth
+	TLSConfig          *tls.Config
 }
 
 // Admin wraps redis cluster admin logic
 type Admin struct {
 	hashMaxSlots Slot
 	cnx          AdminConnectionsInterface
+	auth         Auth
 }
 
 // NewRedisAdmin builds and returns new Admin from the list of pods
@@ -94,9 +117,9 @@ func NewRedisAdmin(ctx context.Context, pods []corev1.Pod, cfg *config.Redis) (A
 	for _, pod := range pods {
 		redisPort := DefaultRedisPort
 		for _, container := range pod.Spec.Containers {
-			if container.Name == "redis-node" {
+			if container.Name == rapi.RedisNodeContainerName {
 				for _, port := range container.Ports {
-					if port.Name == "redis" {
+					if port.Name == rapi.RedisPortName {
 						redisPort = fmt.Sprintf("%d", port.ContainerPort)
 						break
 					}
@@ -108,6 +131,8 @@ func NewRedisAdmin(ctx context.Context, pods []corev1.Pod, cfg *config.Redis) (A
 	adminConfig := AdminOptions{
 		ConnectionTimeout:  time.Duration(cfg.DialTimeout) * time.Millisecond,
 		RenameCommandsFile: cfg.GetRenameCommandsFile(),
+		Auth:               Auth{Username: cfg.Username, Password: cfg.Password},
+		TLSConfig:          cfg.TLSConfig,
 	}
 
 	return NewAdmin(ctx, nodesAddrs, &adminConfig), nil
@@ -119,11 +144,14 @@ func NewAdmin(ctx context.Context, addrs []string, options *AdminOptions) AdminI
 	a := &Admin{
 		hashMaxSlots: HashMaxSlots,
 	}
+	if options != nil {
+		a.auth = options.Auth
+	}
 
 	// perform initial connections
 	a.cnx = NewAdminConnections(ctx, addrs, options)
 
-	return a
+	return withTracing(a)
 }
 
 // Connections returns the connection map of all clients
@@ -235,6 +263,22 @@ func (a *Admin) GetClusterInfosSelected(ctx context.Context, addrs []string) (*C
 	return infos, clusterErr
 }
 
+// Failover executes CLUSTER FAILOVER on the replica with the corresponding addr, so that it takes over its primary.
+// The option is empty, FORCE to skip the handshake with the primary, or TAKEOVER to skip the agreement of the cluster.
+func (a *Admin) Failover(ctx context.Context, addr string, option string) error {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return err
+	}
+	args := []string{"FAILOVER"}
+	if option != "" {
+		args = append(args, option)
+	}
+	var resp string
+	cmdErr := c.DoCmd(ctx, &resp, "CLUSTER", args...)
+	return a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute CLUSTER FAILOVER")
+}
+
 // StartFailover used to force the failover of a specific redis primary node
 func (a *Admin) StartFailover(ctx context.Context, addr string) error {
 	c, err := a.Connections().Get(ctx, addr)
@@ -475,6 +519,22 @@ func (a *Admin) CountKeysInSlot(ctx context.Context, addr string, slot Slot) (in
 	return resp, nil
 }
 
+// GetMemoryUsage exec the redis command to get the number of bytes used by a key and its value on a node,
+// 0 is returned if the key does not exist
+func (a *Admin) GetMemoryUsage(ctx context.Context, addr string, key string) (int64, error) {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp int64
+	cmdErr := c.DoCmd(ctx, &resp, "MEMORY", "USAGE", key)
+	if err := a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute MEMORY USAGE"); err != nil {
+		return 0, err
+	}
+	return resp, nil
+}
+
 // GetKeys uses the GETKEYSINSLOT command to get the number of keys specified by keyBatch
 func (a *Admin) GetKeys(ctx context.Context, addr string, slot Slot, batch string) ([]string, error) {
 	var keys []string
@@ -516,22 +576,35 @@ func (a *Admin) migrateSlot(ctx context.Context, source *Node, dest *Node, slot
 		if len(keys) == 0 {
 			break
 		}
-		var args []string
-		if replace {
-			args = append([]string{dest.IP, dest.Port, "", "0", timeout, "REPLACE", "KEYS"}, keys...)
-		} else {
-			args = append([]string{dest.IP, dest.Port, "", "0", timeout, "KEYS"}, keys...)
-		}
+		args := a.migrateArgs(dest, timeout, replace, keys)
 		var resp string
 		cmdErr := c.DoCmdWithRetries(ctx, &resp, "MIGRATE", args...)
 		if err = a.Connections().ValidateResp(ctx, &resp, cmdErr, source.IPPort(), "unable to run command MIGRATE"); err != nil {
 			return err
 		}
+		MigrationProgressFrom(ctx).addKeys(len(keys))
 	}
 
 	return nil
 }
 
+// migrateArgs builds the MIGRATE arguments, authenticating against the destination node when a password is set
+func (a *Admin) migrateArgs(dest *Node, timeout string, replace bool, keys []string) []string {
+	args := []string{dest.IP, dest.Port, "", "0", timeout}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+	if a.auth.Password != "" {
+		if a.auth.Username != "" {
+			args = append(args, "AUTH2", a.auth.Username, a.auth.Password)
+		} else {
+			args = append(args, "AUTH", a.auth.Password)
+		}
+	}
+	args = append(args, "KEYS")
+	return append(args, keys...)
+}
+
 // MigrateKeys from the source node to the destination node. If replace is true, replace key on busy error.
 // Timeout is in milliseconds
 func (a *Admin) MigrateKeys(ctx context.Context, source *Node, dest *Node, slots SlotSlice, spec *rapi.RedisClusterSpec, replace, scaling bool, primaries Nodes) error {
@@ -558,6 +631,9 @@ func (a *Admin) MigrateKeys(ctx context.Context, source *Node, dest *Node, slots
 		return err
 	}
 
+	progress := MigrationProgressFrom(ctx)
+	var failedSlots SlotSlice
+	var failedMutex sync.Mutex
 	glog.V(6).Info("3) Migrate keys")
 	for i := 0; i < len(slots); i = i + slotBatchSize {
 		wg := sync.WaitGroup{}
@@ -572,9 +648,15 @@ func (a *Admin) MigrateKeys(ctx context.Context, source *Node, dest *Node, slots
 				defer wg.Done()
 				if scaling || *spec.RollingUpdate.KeyMigration {
 					if err := a.migrateSlot(ctx, source, dest, slot, keyBatchSize, timeoutStr, replace); err != nil {
-						glog.Error(err)
+						glog.Errorf("unable to migrate the keys of slot %s from %s to %s: %v", slot, source.IPPort(), dest.IPPort(), err)
+						failedMutex.Lock()
+						failedSlots = append(failedSlots, slot)
+						failedMutex.Unlock()
+						progress.addSlot(true)
+						return
 					}
 				}
+				progress.addSlot(false)
 			}()
 		}
 		wg.Wait()
@@ -586,13 +668,32 @@ func (a *Admin) MigrateKeys(ctx context.Context, source *Node, dest *Node, slots
 		}
 		glog.V(6).Infof("batch migration of slots %d-%d from %s to %s completed in %s", slots[i], slots[endIndex-1], source.IPPort(), dest.IPPort(), time.Since(batchStart))
 	}
-	a.setMigrationSlots(ctx, source, dest, slots)
-	source.Slots = RemoveSlots(source.Slots, slots)
-	a.setPrimarySlots(ctx, source, dest, slots, primaries)
-	glog.V(2).Infof("batch migration of %d slots from %s to %s completed in %s", len(slots), source.IPPort(), dest.IPPort(), time.Since(start))
+	// the keys of a slot are all migrated once GETKEYSINSLOT returns no key, the slots with keys left on the source are
+	// not assigned to the destination: they stay owned by the source
+	migratedSlots := RemoveSlots(append(SlotSlice{}, slots...), failedSlots)
+	a.setMigrationSlots(ctx, source, dest, migratedSlots)
+	source.Slots = RemoveSlots(source.Slots, migratedSlots)
+	a.setPrimarySlots(ctx, source, dest, migratedSlots, primaries)
+	glog.V(2).Infof("batch migration of %d slots from %s to %s completed in %s", len(migratedSlots), source.IPPort(), dest.IPPort(), time.Since(start))
+	if len(failedSlots) > 0 {
+		a.clearSlotState(ctx, source, dest, failedSlots)
+		return fmt.Errorf("unable to migrate the keys of slots %s, they are left on %s", failedSlots, source.IPPort())
+	}
 	return nil
 }
 
+// clearSlotState clears the migrating and importing states of the slots, the keys already migrated stay on the
+// destination but are no longer reachable until the slots are assigned to it
+func (a *Admin) clearSlotState(ctx context.Context, src *Node, dest *Node, slots SlotSlice) {
+	glog.V(6).Info("Send SETSLOT STABLE command to source: ", src.IPPort(), " and destination: ", dest.IPPort(), " total: ", len(slots), " : ", slots)
+	if err := a.SetSlots(ctx, src.IPPort(), "STABLE", slots, "", 0); err != nil {
+		glog.Warningf("error during SETSLOT STABLE on %s: %v", src.IPPort(), err)
+	}
+	if err := a.SetSlots(ctx, dest.IPPort(), "STABLE", slots, "", 0); err != nil {
+		glog.Warningf("error during SETSLOT STABLE on %s: %v", dest.IPPort(), err)
+	}
+}
+
 func (a *Admin) setSlotState(ctx context.Context, src *Node, dest *Node, slots SlotSlice) error {
 	glog.V(6).Info("1) Send SETSLOT IMPORTING command target:", dest.IPPort(), " source-node:", src.IPPort(), " total:", len(slots), " : ", slots)
 	err := a.SetSlots(ctx, dest.IPPort(), "IMPORTING", slots, src.ID, 0)
@@ -745,6 +846,9 @@ func (a *Admin) getInfos(ctx context.Context, c ClientInterface, addr string) (*
 func (a *Admin) RebuildConnectionMap(ctx context.Context, addrs []string, options *AdminOptions) {
 	a.cnx.Reset()
 	a.cnx = NewAdminConnections(ctx, addrs, options)
+	if options != nil {
+		a.auth = options.Auth
+	}
 }
 
 // GetConfig gets the running redis server configuration matching the pattern
@@ -778,3 +882,159 @@ func (a *Admin) SetConfig(ctx context.Context, addr string, config []string) err
 	}
 	return nil
 }
+
+// GetNodeConfig gets the redis server configuration of the node matching the pattern
+func (a *Admin) GetNodeConfig(ctx context.Context, addr string, pattern string) (map[string]string, error) {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	var resp []string
+	cmdErr := c.DoCmd(ctx, &resp, "CONFIG", "GET", pattern)
+	if err = a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute CONFIG GET"); err != nil {
+		return nil, err
+	}
+	return decodeConfig(resp), nil
+}
+
+// GetClusterConfig gets the redis server configuration matching the pattern of all the nodes, by node address.
+// The nodes are queried in parallel, the configuration of the nodes that answered is returned with the error.
+func (a *Admin) GetClusterConfig(ctx context.Context, pattern string) (map[string]map[string]string, error) {
+	type nodeResp struct {
+		addr string
+		resp []string
+		err  error
+	}
+	clients := a.Connections().GetAll()
+	nbClients := len(clients)
+	resps := make(chan nodeResp, nbClients)
+	for addr, c := range clients {
+		addr, c := addr, c
+		go func() {
+			var resp []string
+			err := c.DoCmd(ctx, &resp, "CONFIG", "GET", pattern)
+			resps <- nodeResp{addr: addr, resp: resp, err: err}
+		}()
+	}
+	configs := make(map[string]map[string]string)
+	var failedAddrs []string
+	// the responses are validated sequentially: a failed connection is replaced in the connection map
+	for i := 0; i < nbClients; i++ {
+		r := <-resps
+		if err := a.Connections().ValidateResp(ctx, &r.resp, r.err, r.addr, "unable to execute CONFIG GET"); err != nil {
+			failedAddrs = append(failedAddrs, r.addr)
+			continue
+		}
+		configs[r.addr] = decodeConfig(r.resp)
+	}
+	if len(failedAddrs) > 0 {
+		return configs, fmt.Errorf("unable to get the config of nodes %v", failedAddrs)
+	}
+	return configs, nil
+}
+
+// decodeConfig returns the configuration from the field and value pairs of a CONFIG GET response
+func decodeConfig(resp []string) map[string]string {
+	cfg := make(map[string]string)
+	for i := 0; i < len(resp)-1; i += 2 {
+		cfg[resp[i]] = resp[i+1]
+	}
+	return cfg
+}
+
+// GetInfo gets a section of the redis server information of the node
+func (a *Admin) GetInfo(ctx context.Context, addr string, section string) (map[string]string, error) {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	var resp string
+	cmdErr := c.DoCmd(ctx, &resp, "INFO", section)
+	if err = a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute INFO"); err != nil {
+		return nil, err
+	}
+	return DecodeInfo(resp), nil
+}
+
+// GetClusterState gets the fields of the CLUSTER INFO command of the node, such as cluster_state
+func (a *Admin) GetClusterState(ctx context.Context, addr string) (map[string]string, error) {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	var resp string
+	cmdErr := c.DoCmd(ctx, &resp, "CLUSTER", "INFO")
+	if err = a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute CLUSTER INFO"); err != nil {
+		return nil, err
+	}
+	return DecodeInfo(resp), nil
+}
+
+// GetInfos gets a section of the redis server information of the nodes, by node address.
+// The nodes are queried in parallel, the information of the nodes that answered is returned with the error.
+func (a *Admin) GetInfos(ctx context.Context, addrs []string, section string) (map[string]map[string]string, error) {
+	type nodeResp struct {
+		addr string
+		info map[string]string
+		err  error
+	}
+	resps := make(chan nodeResp, len(addrs))
+	for _, addr := range addrs {
+		addr := addr
+		go func() {
+			info, err := a.GetInfo(ctx, addr, section)
+			resps <- nodeResp{addr: addr, info: info, err: err}
+		}()
+	}
+	infos := make(map[string]map[string]string)
+	var failedAddrs []string
+	for range addrs {
+		r := <-resps
+		if r.err != nil {
+			failedAddrs = append(failedAddrs, r.addr)
+			continue
+		}
+		infos[r.addr] = r.info
+	}
+	if len(failedAddrs) > 0 {
+		return infos, fmt.Errorf("unable to get the information of nodes %v", failedAddrs)
+	}
+	return infos, nil
+}
+
+// BackgroundSave saves the dataset of the node in its RDB file in the background.
+// The save is scheduled if an AOF rewrite is in progress.
+func (a *Admin) BackgroundSave(ctx context.Context, addr string) error {
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return err
+	}
+	var resp string
+	cmdErr := c.DoCmd(ctx, &resp, "BGSAVE", "SCHEDULE")
+	return a.Connections().ValidateResp(ctx, &resp, cmdErr, addr, "unable to execute BGSAVE")
+}
+
+// RestoreKeys restores keys serialized in the DUMP format on the node, existing keys are replaced.
+// Keys with an expiration are restored with their absolute expiration time.
+func (a *Admin) RestoreKeys(ctx context.Context, addr string, entries []DumpEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	c, err := a.Connections().Get(ctx, addr)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ExpireAt > 0 {
+			c.PipeAppend(radix.Cmd(nil, "RESTORE", entry.Key, strconv.FormatInt(entry.ExpireAt, 10), string(entry.Payload), "REPLACE", "ABSTTL"))
+		} else {
+			c.PipeAppend(radix.Cmd(nil, "RESTORE", entry.Key, "0", string(entry.Payload), "REPLACE"))
+		}
+	}
+	err = c.DoPipe(ctx)
+	c.PipeReset()
+	if err != nil {
+		return fmt.Errorf("unable to execute RESTORE on node %s: %v", addr, err)
+	}
+	return nil
+}
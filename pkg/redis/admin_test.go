@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake"
+)
+
+func TestAdminMigrateArgs(t *testing.T) {
+	dest := &Node{IP: "10.0.0.1", Port: "6379"}
+	keys := []string{"key1", "key2"}
+	testTable := []struct {
+		name    string
+		auth    Auth
+		replace bool
+		want    []string
+	}{
+		{
+			name: "no auth",
+			want: []string{"10.0.0.1", "6379", "", "0", "10000", "KEYS", "key1", "key2"},
+		},
+		{
+			name:    "no auth with replace",
+			replace: true,
+			want:    []string{"10.0.0.1", "6379", "", "0", "10000", "REPLACE", "KEYS", "key1", "key2"},
+		},
+		{
+			name: "password only",
+			auth: Auth{Password: "secret"},
+			want: []string{"10.0.0.1", "6379", "", "0", "10000", "AUTH", "secret", "KEYS", "key1", "key2"},
+		},
+		{
+			name:    "username and password with replace",
+			auth:    Auth{Username: "operator", Password: "secret"},
+			replace: true,
+			want:    []string{"10.0.0.1", "6379", "", "0", "10000", "REPLACE", "AUTH2", "operator", "secret", "KEYS", "key1", "key2"},
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Admin{auth: tt.auth}
+			if got := a.migrateArgs(dest, "10000", tt.replace, keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("migrateArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminMigrateKeysFailedSlot(t *testing.T) {
+	sourceSrv := fake.NewRedisServer(t)
+	defer sourceSrv.Close()
+	destSrv := fake.NewRedisServer(t)
+	defer destSrv.Close()
+	sourceHost, sourcePort, _ := net.SplitHostPort(sourceSrv.GetHostPort())
+	destHost, destPort, _ := net.SplitHostPort(destSrv.GetHostPort())
+	source := &Node{ID: "source", IP: sourceHost, Port: sourcePort, Slots: SlotSlice{1}}
+	dest := &Node{ID: "dest", IP: destHost, Port: destPort}
+
+	destSrv.PushResponse("CLUSTER SETSLOT 1 IMPORTING source", "OK")
+	sourceSrv.PushResponse("CLUSTER SETSLOT 1 MIGRATING dest", "OK")
+	// GETKEYSINSLOT has no response: the keys of the slot cannot be migrated
+	sourceSrv.PushResponse("CLUSTER SETSLOT 1 STABLE", "OK")
+	destSrv.PushResponse("CLUSTER SETSLOT 1 STABLE", "OK")
+
+	idleTimeout, keyBatchSize, slotBatchSize := int32(10000), int32(10), int32(1)
+	spec := &rapi.RedisClusterSpec{
+		Scaling: &rapi.Scaling{Migration: rapi.Migration{IdleTimeoutMillis: &idleTimeout, KeyBatchSize: &keyBatchSize, SlotBatchSize: &slotBatchSize}},
+	}
+	ctx := context.Background()
+	a := NewAdmin(ctx, []string{source.IPPort(), dest.IPPort()}, nil)
+	defer a.Close()
+	if err := a.MigrateKeys(ctx, source, dest, SlotSlice{1}, spec, false, true, Nodes{source, dest}); err == nil {
+		t.Errorf("MigrateKeys() expected an error for the failed slot")
+	}
+	if !reflect.DeepEqual(source.Slots, SlotSlice{1}) {
+		t.Errorf("MigrateKeys() source slots = %v, want the failed slot left on the source", source.Slots)
+	}
+	for name, srv := range map[string]*fake.RedisServer{"source": sourceSrv, "destination": destSrv} {
+		srv.Lock()
+		left := srv.Responses["CLUSTER SETSLOT 1 STABLE"]
+		srv.Unlock()
+		if len(left) != 0 {
+			t.Errorf("MigrateKeys() expected CLUSTER SETSLOT 1 STABLE on the %s", name)
+		}
+	}
+}
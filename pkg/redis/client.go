@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"strings"
 	"time"
@@ -39,22 +40,45 @@ type Client struct {
 	pipeline        *radix.Pipeline
 }
 
+// Auth credentials used to authenticate a client connection
+// if only the password is set, the connection authenticates as the default user
+type Auth struct {
+	Username string
+	Password string
+}
+
 // NewClient build a client connection and connect to a redis address
-func NewClient(ctx context.Context, addr string, cnxTimeout time.Duration, commandsMapping map[string]string) (*Client, error) {
+// the connection is encrypted if tlsConfig is not nil
+func NewClient(ctx context.Context, addr string, cnxTimeout time.Duration, commandsMapping map[string]string, auth Auth, tlsConfig *tls.Config) (*Client, error) {
 	var err error
 	c := &Client{
 		commandsMapping: commandsMapping,
 		pipeline:        radix.NewPipeline(),
 	}
 	dialer := &radix.Dialer{
-		NetDialer: &net.Dialer{
-			Timeout: cnxTimeout,
-		},
+		NetDialer: newNetDialer(cnxTimeout, tlsConfig),
+		AuthUser:  auth.Username,
+		AuthPass:  auth.Password,
 	}
 	c.client, err = dialer.Dial(ctx, "tcp", addr)
 	return c, err
 }
 
+func newNetDialer(cnxTimeout time.Duration, tlsConfig *tls.Config) interface {
+	DialContext(context.Context, string, string) (net.Conn, error)
+} {
+	netDialer := &net.Dialer{
+		Timeout: cnxTimeout,
+	}
+	if tlsConfig == nil {
+		return netDialer
+	}
+	return &tls.Dialer{
+		NetDialer: netDialer,
+		Config:    tlsConfig,
+	}
+}
+
 // Close closes the connection.
 func (c *Client) Close() error {
 	return c.client.Close()
@@ -1,9 +1,8 @@
 package redis
 
 import (
-	"sort"
-
 	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -14,9 +13,12 @@ type Cluster struct {
 	NodeSelector   map[string]string
 	Nodes          map[string]*Node
 	KubeNodes      []corev1.Node
+	TopologyKeys   []string
 	Status         rapi.ClusterStatus
 	NodesPlacement rapi.NodesPlacementInfo
 	ActionsInfo    ClusterActionsInfo
+	// PinnedSlots ID of the primary node each slot is pinned to by a RedisSlotMigration
+	PinnedSlots map[Slot]string
 }
 
 // ClusterActionsInfo stores information about the current action on the Cluster
@@ -35,41 +37,14 @@ func NewCluster(name, namespace string) *Cluster {
 	return c
 }
 
-// GetZones gets all available zones from the list of k8s nodes
+// GetZones gets all available topology domains from the list of k8s nodes
 func (c *Cluster) GetZones() []string {
-	set := make(map[string]struct{})
-	var zones []string
-	for _, node := range c.KubeNodes {
-		zone, ok := node.Labels[corev1.LabelTopologyZone]
-		if ok {
-			set[zone] = struct{}{}
-		} else {
-			set[rapi.UnknownZone] = struct{}{}
-		}
-	}
-	if len(set) == 0 {
-		set[rapi.UnknownZone] = struct{}{}
-	}
-	for key := range set {
-		zones = append(zones, key)
-	}
-	sort.Strings(zones)
-	return zones
+	return utils.GetZones(c.KubeNodes, c.TopologyKeys)
 }
 
-// GetZone gets the zone label from the specified k8s node
+// GetZone gets the topology domain of the specified k8s node
 func (c *Cluster) GetZone(nodeName string) string {
-	for _, node := range c.KubeNodes {
-		if node.Name == nodeName {
-			label, ok := node.Labels[corev1.LabelTopologyZone]
-			if ok {
-				return label
-			} else {
-				return rapi.UnknownZone
-			}
-		}
-	}
-	return rapi.UnknownZone
+	return utils.GetZone(nodeName, c.KubeNodes, c.TopologyKeys)
 }
 
 // AddNode used to add new Node in the cluster
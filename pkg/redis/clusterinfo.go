@@ -71,6 +71,21 @@ func DecodeNodeStartTime(input *string) (time.Time, error) {
 	return time.Now(), fmt.Errorf("Error while decoding redis instance uptime in seconds. No data found")
 }
 
+// DecodeInfo decodes the output of the INFO command into a map of fields, section headers are skipped
+func DecodeInfo(input string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, ":"); i > 0 {
+			info[line[:i]] = line[i+1:]
+		}
+	}
+	return info
+}
+
 // DecodeNodeInfos decode from the cmd output the Redis nodes info. Second argument is the node on which we are connected to request info
 func DecodeNodeInfos(input *string, addr string) *NodeInfos {
 	infos := NewNodeInfos()
@@ -114,3 +114,16 @@ func TestNodeToString(t *testing.T) {
 	}
 
 }
+
+func TestDecodeInfo(t *testing.T) {
+	input := "# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:1\r\nrdb_last_bgsave_status:ok\r\n\r\n# Keyspace\r\ndb0:keys=3,expires=0,avg_ttl=0\r\n"
+	expected := map[string]string{
+		"loading":                "0",
+		"rdb_bgsave_in_progress": "1",
+		"rdb_last_bgsave_status": "ok",
+		"db0":                    "keys=3,expires=0,avg_ttl=0",
+	}
+	if info := DecodeInfo(input); !reflect.DeepEqual(info, expected) {
+		t.Errorf("DecodeInfo() = %v, want %v", info, expected)
+	}
+}
@@ -3,12 +3,14 @@ package redis
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -50,12 +52,15 @@ type AdminConnectionsInterface interface {
 }
 
 // AdminConnections connection map for redis cluster
-// currently the admin connection is not threadSafe since it is only use in the Events thread.
+// the map is safe for concurrent use, the nodes can be queried in parallel.
 type AdminConnections struct {
+	mutex             sync.Mutex
 	clients           map[string]ClientInterface
 	connectionTimeout time.Duration
 	commandsMapping   map[string]string
 	clientName        string
+	auth              Auth
+	tlsConfig         *tls.Config
 }
 
 func init() {
@@ -78,6 +83,8 @@ func NewAdminConnections(ctx context.Context, addrs []string, options *AdminOpti
 			cnx.commandsMapping = buildCommandReplaceMapping(options.RenameCommandsFile)
 		}
 		cnx.clientName = options.ClientName
+		cnx.auth = options.Auth
+		cnx.tlsConfig = options.TLSConfig
 	}
 	cnx.AddAll(ctx, addrs)
 	return cnx
@@ -85,6 +92,8 @@ func NewAdminConnections(ctx context.Context, addrs []string, options *AdminOpti
 
 // Close used to close all possible resources instantiated by the Connections
 func (cnx *AdminConnections) Close() {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	for _, c := range cnx.clients {
 		c.Close()
 	}
@@ -99,6 +108,8 @@ func (cnx *AdminConnections) Add(ctx context.Context, addr string) error {
 
 // Remove disconnect and remove the client connection from the map
 func (cnx *AdminConnections) Remove(addr string) {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	if c, ok := cnx.clients[addr]; ok {
 		c.Close()
 		delete(cnx.clients, addr)
@@ -109,13 +120,19 @@ func (cnx *AdminConnections) Remove(addr string) {
 // connects if the connection is not in the map yet
 func (cnx *AdminConnections) Update(ctx context.Context, addr string) (ClientInterface, error) {
 	// if already exist close the current connection
+	cnx.mutex.Lock()
 	if c, ok := cnx.clients[addr]; ok {
 		c.Close()
+		delete(cnx.clients, addr)
 	}
+	cnx.mutex.Unlock()
 
+	// the lock is not held while connecting, a connection error reconnects
 	c, err := cnx.connect(ctx, addr)
 	if err == nil && c != nil {
+		cnx.mutex.Lock()
 		cnx.clients[addr] = c
+		cnx.mutex.Unlock()
 	} else {
 		glog.V(3).Infof("Cannot connect to %s ", addr)
 	}
@@ -125,11 +142,21 @@ func (cnx *AdminConnections) Update(ctx context.Context, addr string) (ClientInt
 // Get returns a client connection for the given adress,
 // connects if the connection is not in the map yet
 func (cnx *AdminConnections) Get(ctx context.Context, addr string) (ClientInterface, error) {
-	if c, ok := cnx.clients[addr]; ok {
+	cnx.mutex.Lock()
+	c, ok := cnx.clients[addr]
+	cnx.mutex.Unlock()
+	if ok {
 		return c, nil
 	}
 	c, err := cnx.connect(ctx, addr)
 	if err == nil && c != nil {
+		cnx.mutex.Lock()
+		defer cnx.mutex.Unlock()
+		// another caller may have connected in the meantime
+		if existing, ok := cnx.clients[addr]; ok {
+			c.Close()
+			return existing, nil
+		}
 		cnx.clients[addr] = c
 	}
 	return c, err
@@ -143,14 +170,17 @@ func (cnx *AdminConnections) GetRandom() (ClientInterface, error) {
 
 // GetDifferentFrom returns random a client connection different from given address
 func (cnx *AdminConnections) GetDifferentFrom(addr string) (ClientInterface, error) {
+	cnx.mutex.Lock()
 	if len(cnx.clients) == 1 {
 		for a, c := range cnx.clients {
+			cnx.mutex.Unlock()
 			if a != addr {
 				return c, nil
 			}
 			return nil, errors.New(ErrNotFound)
 		}
 	}
+	cnx.mutex.Unlock()
 
 	for {
 		a, c, err := cnx.getRandomKeyClient()
@@ -165,11 +195,19 @@ func (cnx *AdminConnections) GetDifferentFrom(addr string) (ClientInterface, err
 
 // GetAll returns a map of all clients per address
 func (cnx *AdminConnections) GetAll() map[string]ClientInterface {
-	return cnx.clients
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
+	clients := make(map[string]ClientInterface, len(cnx.clients))
+	for addr, c := range cnx.clients {
+		clients[addr] = c
+	}
+	return clients
 }
 
 //GetSelected returns a map of clients based on the input addresses
 func (cnx *AdminConnections) GetSelected(addrs []string) map[string]ClientInterface {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	clientsSelected := make(map[string]ClientInterface)
 	for _, addr := range addrs {
 		if client, ok := cnx.clients[addr]; ok {
@@ -207,6 +245,8 @@ func (cnx *AdminConnections) ReplaceAll(ctx context.Context, addrs []string) {
 
 // Reset close all connections and clear the connection map
 func (cnx *AdminConnections) Reset() {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	for _, c := range cnx.clients {
 		c.Close()
 	}
@@ -230,6 +270,8 @@ func (cnx *AdminConnections) ValidateResp(ctx context.Context, resp interface{},
 
 // GetRandom returns a client connection to a random node of the client map
 func (cnx *AdminConnections) getRandomKeyClient() (string, ClientInterface, error) {
+	cnx.mutex.Lock()
+	defer cnx.mutex.Unlock()
 	nbClient := len(cnx.clients)
 	if nbClient == 0 {
 		return "", nil, errors.New(ErrNotFound)
@@ -268,7 +310,7 @@ func (cnx *AdminConnections) handleError(ctx context.Context, addr string, err e
 }
 
 func (cnx *AdminConnections) connect(ctx context.Context, addr string) (ClientInterface, error) {
-	c, err := NewClient(ctx, addr, cnx.connectionTimeout, cnx.commandsMapping)
+	c, err := NewClient(ctx, addr, cnx.connectionTimeout, cnx.commandsMapping, cnx.auth, cnx.tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,38 @@
+package redis
+
+import "strings"
+
+// DumpEntry represents a key serialized in the DUMP format
+type DumpEntry struct {
+	Key string
+	// Payload serialized value, as returned by the DUMP command
+	Payload []byte
+	// ExpireAt absolute expiration time of the key in milliseconds, 0 if the key does not expire
+	ExpireAt int64
+}
+
+// KeySlot returns the hash slot of the key, computed on its hash tag if it has one
+func KeySlot(key string) Slot {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return Slot(crc16(key) % uint16(HashMaxSlots+1))
+}
+
+// crc16 implements the CRC16 XMODEM checksum used by redis to hash the keys
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,25 @@
+package redis
+
+import "testing"
+
+func TestKeySlot(t *testing.T) {
+	testTable := []struct {
+		key  string
+		slot Slot
+	}{
+		{"123456789", 0x31C3 % 16384},
+		{"foo", 12182},
+		{"bar", 5061},
+		{"{user1000}.following", KeySlot("user1000")},
+		{"foo{{bar}}zap", KeySlot("{bar")},
+		{"{bar}foo", 5061},
+	}
+	for _, tt := range testTable {
+		if got := KeySlot(tt.key); got != tt.slot {
+			t.Errorf("KeySlot(%q) = %d, want %d", tt.key, got, tt.slot)
+		}
+	}
+	if KeySlot("foo{}{bar}") == KeySlot("bar") {
+		t.Errorf("KeySlot() should hash the whole key when the hash tag is empty")
+	}
+}
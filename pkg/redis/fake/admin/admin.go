@@ -46,9 +46,21 @@ type Admin struct {
 	GetKeysInSlotRet map[string]GetKeysInSlotRetType
 	// CountKeysInSlotRet map of returned data for CountKeysInSlot function
 	CountKeysInSlotRet map[string]CountKeysInSlotRetType
+	// GetMemoryUsageRet map of returned data for GetMemoryUsage function, by key
+	GetMemoryUsageRet map[string]int64
 	// GetKeysRet map of returned data for GetKeys function
 	GetKeysRet map[string]GetKeysInSlotRetType
-	cnx        *Connections
+	// GetInfoRet map of returned data for GetInfo function
+	GetInfoRet map[string]map[string]string
+	// GetClusterStateRet map of returned data for GetClusterState function
+	GetClusterStateRet map[string]map[string]string
+	// GetNodeConfigRet map of returned data for GetNodeConfig function
+	GetNodeConfigRet map[string]map[string]string
+	// RestoredKeys map of the keys restored by the RestoreKeys function
+	RestoredKeys map[string][]redis.DumpEntry
+	// Failovers map of the option of the CLUSTER FAILOVER executed by the Failover function, by replica addr
+	Failovers map[string]string
+	cnx       *Connections
 }
 
 // NewFakeAdmin returns new AdminInterface for fake admin
@@ -60,6 +72,12 @@ func NewFakeAdmin() *Admin {
 		GetClusterInfosSelectedRet: ClusterInfosRetType{},
 		GetKeysInSlotRet:           make(map[string]GetKeysInSlotRetType),
 		CountKeysInSlotRet:         make(map[string]CountKeysInSlotRetType),
+		GetMemoryUsageRet:          make(map[string]int64),
+		GetInfoRet:                 make(map[string]map[string]string),
+		GetClusterStateRet:         make(map[string]map[string]string),
+		GetNodeConfigRet:           make(map[string]map[string]string),
+		RestoredKeys:               make(map[string][]redis.DumpEntry),
+		Failovers:                  make(map[string]string),
 		cnx:                        &Connections{},
 	}
 }
@@ -115,6 +133,15 @@ func (a *Admin) StartFailover(ctx context.Context, addr string) error {
 	return val
 }
 
+// Failover executes CLUSTER FAILOVER on the replica with the corresponding addr
+func (a *Admin) Failover(ctx context.Context, addr string, option string) error {
+	if err := a.AddrError[addr]; err != nil {
+		return err
+	}
+	a.Failovers[addr] = option
+	return nil
+}
+
 // ForgetNode forces a redis cluster node to forget a specific node
 func (a *Admin) ForgetNode(ctx context.Context, addr string) error {
 	val, ok := a.AddrError[addr]
@@ -187,6 +214,11 @@ func (a *Admin) CountKeysInSlot(ctx context.Context, addr string, node redis.Slo
 	return val.NbKeys, val.Err
 }
 
+// GetMemoryUsage gets the number of bytes used by a key and its value
+func (a *Admin) GetMemoryUsage(ctx context.Context, addr string, key string) (int64, error) {
+	return a.GetMemoryUsageRet[key], a.AddrError[addr]
+}
+
 // GetKeys uses GETKEYSINSLOT command to get keys in a slot
 // Number of keys returned specified by batch
 func (a *Admin) GetKeys(ctx context.Context, addr string, slot redis.Slot, batch string) ([]string, error) {
@@ -268,3 +300,60 @@ func (a *Admin) SetConfig(ctx context.Context, addr string, config []string) err
 	}
 	return val
 }
+
+// GetNodeConfig gets the redis server configuration of the node matching the pattern
+func (a *Admin) GetNodeConfig(ctx context.Context, addr string, pattern string) (map[string]string, error) {
+	return a.GetNodeConfigRet[addr], a.AddrError[addr]
+}
+
+// GetClusterConfig gets the redis server configuration matching the pattern of all the nodes, by node address
+func (a *Admin) GetClusterConfig(ctx context.Context, pattern string) (map[string]map[string]string, error) {
+	configs := make(map[string]map[string]string)
+	var err error
+	for addr, cfg := range a.GetNodeConfigRet {
+		if addrErr := a.AddrError[addr]; addrErr != nil {
+			err = addrErr
+			continue
+		}
+		configs[addr] = cfg
+	}
+	return configs, err
+}
+
+// GetInfo gets a section of the redis server information of the node
+func (a *Admin) GetInfo(ctx context.Context, addr string, section string) (map[string]string, error) {
+	return a.GetInfoRet[addr], a.AddrError[addr]
+}
+
+// GetClusterState gets the fields of the CLUSTER INFO command of the node
+func (a *Admin) GetClusterState(ctx context.Context, addr string) (map[string]string, error) {
+	return a.GetClusterStateRet[addr], a.AddrError[addr]
+}
+
+// GetInfos gets a section of the redis server information of the nodes, by node address
+func (a *Admin) GetInfos(ctx context.Context, addrs []string, section string) (map[string]map[string]string, error) {
+	infos := make(map[string]map[string]string)
+	var err error
+	for _, addr := range addrs {
+		if addrErr := a.AddrError[addr]; addrErr != nil {
+			err = addrErr
+			continue
+		}
+		infos[addr] = a.GetInfoRet[addr]
+	}
+	return infos, err
+}
+
+// BackgroundSave saves the dataset of the node in its RDB file in the background
+func (a *Admin) BackgroundSave(ctx context.Context, addr string) error {
+	return a.AddrError[addr]
+}
+
+// RestoreKeys restores serialized keys on the node
+func (a *Admin) RestoreKeys(ctx context.Context, addr string, entries []redis.DumpEntry) error {
+	if err := a.AddrError[addr]; err != nil {
+		return err
+	}
+	a.RestoredKeys[addr] = append(a.RestoredKeys[addr], entries...)
+	return nil
+}
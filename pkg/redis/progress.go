@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type migrationProgressKey struct{}
+
+// MigrationProgress counts the slots and keys migrated during a cluster operation, it is safe for concurrent use
+type MigrationProgress struct {
+	mu            sync.Mutex
+	startTime     time.Time
+	slotsPlanned  int32
+	slotsMigrated int32
+	slotsFailed   int32
+	keysMoved     int64
+}
+
+// MigrationProgressSnapshot is a copy of the counters of a MigrationProgress
+type MigrationProgressSnapshot struct {
+	StartTime     time.Time
+	SlotsPlanned  int32
+	SlotsMigrated int32
+	SlotsFailed   int32
+	KeysMoved     int64
+}
+
+// WithMigrationProgress returns a context in which the slot migrations are counted in progress
+func WithMigrationProgress(ctx context.Context, progress *MigrationProgress) context.Context {
+	return context.WithValue(ctx, migrationProgressKey{}, progress)
+}
+
+// MigrationProgressFrom returns the MigrationProgress of the context, nil if the migrations are not counted
+func MigrationProgressFrom(ctx context.Context) *MigrationProgress {
+	progress, _ := ctx.Value(migrationProgressKey{}).(*MigrationProgress)
+	return progress
+}
+
+// AddPlannedSlots adds slots to migrate, the first planned slots start the operation
+func (p *MigrationProgress) AddPlannedSlots(nbSlots int32) {
+	if p == nil || nbSlots <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.startTime.IsZero() {
+		p.startTime = time.Now()
+	}
+	p.slotsPlanned += nbSlots
+}
+
+func (p *MigrationProgress) addSlot(failed bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if failed {
+		p.slotsFailed++
+	} else {
+		p.slotsMigrated++
+	}
+}
+
+func (p *MigrationProgress) addKeys(nbKeys int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keysMoved += int64(nbKeys)
+}
+
+// Snapshot returns a copy of the counters
+func (p *MigrationProgress) Snapshot() MigrationProgressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return MigrationProgressSnapshot{
+		StartTime:     p.startTime,
+		SlotsPlanned:  p.slotsPlanned,
+		SlotsMigrated: p.slotsMigrated,
+		SlotsFailed:   p.slotsFailed,
+		KeysMoved:     p.keysMoved,
+	}
+}
@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMigrationProgress(t *testing.T) {
+	if progress := MigrationProgressFrom(context.Background()); progress != nil {
+		t.Fatalf("MigrationProgressFrom() = %v, want nil", progress)
+	}
+	// the counters of a nil progress are ignored
+	MigrationProgressFrom(context.Background()).addSlot(false)
+
+	progress := &MigrationProgress{}
+	ctx := WithMigrationProgress(context.Background(), progress)
+	MigrationProgressFrom(ctx).AddPlannedSlots(3)
+	MigrationProgressFrom(ctx).addSlot(false)
+	MigrationProgressFrom(ctx).addSlot(true)
+	MigrationProgressFrom(ctx).addKeys(42)
+
+	snapshot := progress.Snapshot()
+	if snapshot.StartTime.IsZero() || snapshot.SlotsPlanned != 3 || snapshot.SlotsMigrated != 1 || snapshot.SlotsFailed != 1 || snapshot.KeysMoved != 42 {
+		t.Errorf("Snapshot() = %+v, want 3 planned, 1 migrated, 1 failed and 42 keys", snapshot)
+	}
+}
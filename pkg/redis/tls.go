@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// NewTLSConfig returns the tls configuration used to connect to the redis nodes from PEM encoded
+// client certificate, private key and CA certificate
+func NewTLSConfig(certPEM, keyPEM, caPEM []byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the tls key pair: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("unable to load the tls CA certificate")
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		// redis nodes are reached by IP: like redis-server, only the certificate chain is verified
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return verifyChain(cs, roots)
+		},
+	}, nil
+}
+
+// NewTLSConfigFromFiles returns a tls configuration that reads the client certificate, private key
+// and CA certificate from the given files on each handshake, so that a certificate rotation is
+// picked up by new connections
+func NewTLSConfigFromFiles(certFile, keyFile, caFile string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load the tls key pair: %v", err)
+			}
+			return &cert, nil
+		},
+		// redis nodes are reached by IP: like redis-server, only the certificate chain is verified
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			caPEM, err := os.ReadFile(caFile)
+			if err != nil {
+				return fmt.Errorf("unable to read the tls CA certificate: %v", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(caPEM) {
+				return errors.New("unable to load the tls CA certificate")
+			}
+			return verifyChain(cs, roots)
+		},
+	}
+}
+
+func verifyChain(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("no peer certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
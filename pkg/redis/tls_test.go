@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	otherCA := newTestCert(t, "other-ca", nil)
+	client := newTestCert(t, "client", ca)
+	server := newTestCert(t, "10.0.0.1", ca)
+	otherServer := newTestCert(t, "10.0.0.2", otherCA)
+
+	if _, err := NewTLSConfig([]byte("foo"), client.keyPEM, ca.certPEM); err == nil {
+		t.Errorf("NewTLSConfig() should return an error with an invalid certificate")
+	}
+	if _, err := NewTLSConfig(client.certPEM, client.keyPEM, []byte("foo")); err == nil {
+		t.Errorf("NewTLSConfig() should return an error with an invalid CA certificate")
+	}
+
+	cfg, err := NewTLSConfig(client.certPEM, client.keyPEM, ca.certPEM)
+	if err != nil {
+		t.Fatalf("NewTLSConfig() unexpected error: %v", err)
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Errorf("NewTLSConfig() expected one client certificate, got %d", len(cfg.Certificates))
+	}
+	if err = cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.cert}}); err != nil {
+		t.Errorf("VerifyConnection() unexpected error: %v", err)
+	}
+	if err = cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{otherServer.cert}}); err == nil {
+		t.Errorf("VerifyConnection() should reject a certificate signed by another CA")
+	}
+	if err = cfg.VerifyConnection(tls.ConnectionState{}); err == nil {
+		t.Errorf("VerifyConnection() should reject a connection without peer certificate")
+	}
+}
+
+func TestNewTLSConfigFromFiles(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	otherCA := newTestCert(t, "other-ca", nil)
+	client := newTestCert(t, "client", ca)
+	server := newTestCert(t, "10.0.0.1", ca)
+
+	dir, err := os.MkdirTemp("", "redis-tls")
+	if err != nil {
+		t.Fatalf("unable to create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	write := func(file string, content []byte) {
+		if err := os.WriteFile(file, content, 0600); err != nil {
+			t.Fatalf("unable to write %s: %v", file, err)
+		}
+	}
+	write(certFile, client.certPEM)
+	write(keyFile, client.keyPEM)
+	write(caFile, ca.certPEM)
+
+	cfg := NewTLSConfigFromFiles(certFile, keyFile, caFile)
+	if _, err = cfg.GetClientCertificate(nil); err != nil {
+		t.Errorf("GetClientCertificate() unexpected error: %v", err)
+	}
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.cert}}
+	if err = cfg.VerifyConnection(state); err != nil {
+		t.Errorf("VerifyConnection() unexpected error: %v", err)
+	}
+
+	// rotating the CA is picked up by the next handshake
+	write(caFile, otherCA.certPEM)
+	if err = cfg.VerifyConnection(state); err == nil {
+		t.Errorf("VerifyConnection() should reject a certificate signed by the previous CA")
+	}
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
+	"github.com/IBM/operator-for-redis-cluster/pkg/tracing"
+)
+
+const (
+	nodeAddrAttr   = attribute.Key("redis.node.address")
+	nodeIDAttr     = attribute.Key("redis.node.id")
+	destAddrAttr   = attribute.Key("redis.destination.address")
+	slotAttr       = attribute.Key("redis.slot")
+	slotsCountAttr = attribute.Key("redis.slots.count")
+	keysCountAttr  = attribute.Key("redis.keys.count")
+	actionAttr     = attribute.Key("redis.action")
+)
+
+// tracedAdmin traces each call of an AdminInterface in a span of the trace carried by the context
+type tracedAdmin struct {
+	AdminInterface
+}
+
+// withTracing returns an AdminInterface tracing the calls of the admin
+func withTracing(admin AdminInterface) AdminInterface {
+	return &tracedAdmin{AdminInterface: admin}
+}
+
+func (t *tracedAdmin) InitRedisCluster(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.InitRedisCluster", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.InitRedisCluster(ctx, addr)
+}
+
+func (t *tracedAdmin) GetClusterInfos(ctx context.Context) (infos *ClusterInfos, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetClusterInfos")
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetClusterInfos(ctx)
+}
+
+func (t *tracedAdmin) GetClusterInfosSelected(ctx context.Context, addrs []string) (infos *ClusterInfos, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetClusterInfosSelected", attribute.StringSlice("redis.node.addresses", addrs))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetClusterInfosSelected(ctx, addrs)
+}
+
+func (t *tracedAdmin) AttachNodeToCluster(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.AttachNodeToCluster", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.AttachNodeToCluster(ctx, addr)
+}
+
+func (t *tracedAdmin) AttachReplicaToPrimary(ctx context.Context, replica *Node, primary *Node) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.AttachReplicaToPrimary", nodeAddrAttr.String(replica.IPPort()), destAddrAttr.String(primary.IPPort()))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.AttachReplicaToPrimary(ctx, replica, primary)
+}
+
+func (t *tracedAdmin) DetachReplica(ctx context.Context, replica *Node) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.DetachReplica", nodeAddrAttr.String(replica.IPPort()))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.DetachReplica(ctx, replica)
+}
+
+func (t *tracedAdmin) StartFailover(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.StartFailover", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.StartFailover(ctx, addr)
+}
+
+func (t *tracedAdmin) Failover(ctx context.Context, addr string, option string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.Failover", nodeAddrAttr.String(addr), actionAttr.String(option))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.Failover(ctx, addr, option)
+}
+
+func (t *tracedAdmin) ForgetNode(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.ForgetNode", nodeIDAttr.String(id))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.ForgetNode(ctx, id)
+}
+
+func (t *tracedAdmin) ForgetNodeByAddr(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.ForgetNodeByAddr", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.ForgetNodeByAddr(ctx, addr)
+}
+
+func (t *tracedAdmin) SetSlot(ctx context.Context, addr, action string, slot Slot, node *Node) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.SetSlot", nodeAddrAttr.String(addr), actionAttr.String(action), slotAttr.Int(int(slot)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.SetSlot(ctx, addr, action, slot, node)
+}
+
+func (t *tracedAdmin) SetSlots(ctx context.Context, addr string, action string, slots SlotSlice, nodeID string, retryAttempt int) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.SetSlots", nodeAddrAttr.String(addr), actionAttr.String(action), slotsCountAttr.Int(len(slots)), nodeIDAttr.String(nodeID))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.SetSlots(ctx, addr, action, slots, nodeID, retryAttempt)
+}
+
+func (t *tracedAdmin) AddSlots(ctx context.Context, addr string, slots SlotSlice) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.AddSlots", nodeAddrAttr.String(addr), slotsCountAttr.Int(len(slots)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.AddSlots(ctx, addr, slots)
+}
+
+func (t *tracedAdmin) DelSlots(ctx context.Context, addr string, slots SlotSlice) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.DelSlots", nodeAddrAttr.String(addr), slotsCountAttr.Int(len(slots)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.DelSlots(ctx, addr, slots)
+}
+
+func (t *tracedAdmin) GetKeysInSlot(ctx context.Context, addr string, slot Slot, batch string, limit bool) (keys []string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetKeysInSlot", nodeAddrAttr.String(addr), slotAttr.Int(int(slot)))
+	defer func() {
+		span.SetAttributes(keysCountAttr.Int(len(keys)))
+		tracing.End(span, err)
+	}()
+	return t.AdminInterface.GetKeysInSlot(ctx, addr, slot, batch, limit)
+}
+
+func (t *tracedAdmin) CountKeysInSlot(ctx context.Context, addr string, slot Slot) (nbKeys int64, err error) {
+	ctx, span := tracing.Start(ctx, "redis.CountKeysInSlot", nodeAddrAttr.String(addr), slotAttr.Int(int(slot)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.CountKeysInSlot(ctx, addr, slot)
+}
+
+func (t *tracedAdmin) GetMemoryUsage(ctx context.Context, addr string, key string) (usage int64, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetMemoryUsage", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetMemoryUsage(ctx, addr, key)
+}
+
+func (t *tracedAdmin) GetKeys(ctx context.Context, addr string, slot Slot, batch string) (keys []string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetKeys", nodeAddrAttr.String(addr), slotAttr.Int(int(slot)))
+	defer func() {
+		span.SetAttributes(keysCountAttr.Int(len(keys)))
+		tracing.End(span, err)
+	}()
+	return t.AdminInterface.GetKeys(ctx, addr, slot, batch)
+}
+
+func (t *tracedAdmin) DeleteKeys(ctx context.Context, addr string, keys []string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.DeleteKeys", nodeAddrAttr.String(addr), keysCountAttr.Int(len(keys)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.DeleteKeys(ctx, addr, keys)
+}
+
+func (t *tracedAdmin) MigrateKeys(ctx context.Context, source *Node, dest *Node, slots SlotSlice, spec *rapi.RedisClusterSpec, replace, scaling bool, primaries Nodes) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.MigrateKeys", nodeAddrAttr.String(source.IPPort()), destAddrAttr.String(dest.IPPort()), slotsCountAttr.Int(len(slots)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.MigrateKeys(ctx, source, dest, slots, spec, replace, scaling, primaries)
+}
+
+func (t *tracedAdmin) FlushAndReset(ctx context.Context, addr string, mode string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.FlushAndReset", nodeAddrAttr.String(addr), actionAttr.String(mode))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.FlushAndReset(ctx, addr, mode)
+}
+
+func (t *tracedAdmin) FlushAll(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.FlushAll", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.FlushAll(ctx, addr)
+}
+
+func (t *tracedAdmin) RebuildConnectionMap(ctx context.Context, addrs []string, options *AdminOptions) {
+	ctx, span := tracing.Start(ctx, "redis.RebuildConnectionMap", attribute.StringSlice("redis.node.addresses", addrs))
+	defer span.End()
+	t.AdminInterface.RebuildConnectionMap(ctx, addrs, options)
+}
+
+func (t *tracedAdmin) GetConfig(ctx context.Context, pattern string) (cfg map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetConfig")
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetConfig(ctx, pattern)
+}
+
+func (t *tracedAdmin) SetConfig(ctx context.Context, addr string, config []string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.SetConfig", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.SetConfig(ctx, addr, config)
+}
+
+func (t *tracedAdmin) GetNodeConfig(ctx context.Context, addr string, pattern string) (cfg map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetNodeConfig", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetNodeConfig(ctx, addr, pattern)
+}
+
+func (t *tracedAdmin) GetClusterConfig(ctx context.Context, pattern string) (cfgs map[string]map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetClusterConfig")
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetClusterConfig(ctx, pattern)
+}
+
+func (t *tracedAdmin) GetInfo(ctx context.Context, addr string, section string) (info map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetInfo", nodeAddrAttr.String(addr), actionAttr.String(section))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetInfo(ctx, addr, section)
+}
+
+func (t *tracedAdmin) GetClusterState(ctx context.Context, addr string) (state map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetClusterState", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetClusterState(ctx, addr)
+}
+
+func (t *tracedAdmin) GetInfos(ctx context.Context, addrs []string, section string) (infos map[string]map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "redis.GetInfos", actionAttr.String(section))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.GetInfos(ctx, addrs, section)
+}
+
+func (t *tracedAdmin) BackgroundSave(ctx context.Context, addr string) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.BackgroundSave", nodeAddrAttr.String(addr))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.BackgroundSave(ctx, addr)
+}
+
+func (t *tracedAdmin) RestoreKeys(ctx context.Context, addr string, entries []DumpEntry) (err error) {
+	ctx, span := tracing.Start(ctx, "redis.RestoreKeys", nodeAddrAttr.String(addr), keysCountAttr.Int(len(entries)))
+	defer func() { tracing.End(span, err) }()
+	return t.AdminInterface.RestoreKeys(ctx, addr, entries)
+}
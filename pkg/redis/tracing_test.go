@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type addSlotsAdmin struct {
+	AdminInterface
+	err error
+}
+
+func (a *addSlotsAdmin) AddSlots(ctx context.Context, addr string, slots SlotSlice) error {
+	return a.err
+}
+
+func TestTracedAdmin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	admin := withTracing(&addSlotsAdmin{err: errors.New("CLUSTERDOWN")})
+	if err := admin.AddSlots(context.Background(), "10.0.0.1:6379", SlotSlice{1, 2, 3}); err == nil {
+		t.Fatalf("AddSlots() error = nil, want the error of the admin")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans ended, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "redis.AddSlots" {
+		t.Errorf("span name = %q, want redis.AddSlots", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want %v", span.Status().Code, codes.Error)
+	}
+	attrs := map[string]string{}
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["redis.node.address"] != "10.0.0.1:6379" || attrs["redis.slots.count"] != "3" {
+		t.Errorf("span attributes = %v, want the node address and the slot count", attrs)
+	}
+}
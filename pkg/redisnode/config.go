@@ -1,9 +1,14 @@
 package redisnode
 
 import (
+	"crypto/tls"
+	"path/filepath"
 	"time"
 
+	rapi "github.com/IBM/operator-for-redis-cluster/api/v1alpha1"
 	"github.com/IBM/operator-for-redis-cluster/pkg/config"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
+	"github.com/caarlos0/env/v6"
 	"github.com/spf13/pflag"
 )
 
@@ -14,6 +19,8 @@ const (
 	RedisStartDelayDefault = 10 * time.Second
 	// HTTPServerAddrDefault default http server address
 	HTTPServerAddrDefault = "0.0.0.0:8080"
+
+	tlsWatchInterval = 30 * time.Second
 )
 
 // Config contains configuration for redis-operator
@@ -25,6 +32,10 @@ type Config struct {
 	RedisStartWait  time.Duration
 	RedisStartDelay time.Duration
 	HTTPServerAddr  string
+	RedisUsername   string `env:"REDIS_USERNAME"`
+	RedisPassword   string `env:"REDIS_PASSWORD"`
+	TLSDir          string `env:"REDIS_TLS_DIR"`
+	PersistentData  bool   `env:"REDIS_PERSISTENT_DATA"`
 }
 
 // NewRedisNodeConfig builds and returns a redis-operator Config
@@ -33,6 +44,50 @@ func NewRedisNodeConfig() *Config {
 	return &Config{}
 }
 
+// ParseEnvironment parses Config for environment variables
+func (c *Config) ParseEnvironment() error {
+	if err := env.Parse(c); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Auth returns the credentials used to connect to the redis nodes
+func (c *Config) Auth() redis.Auth {
+	return redis.Auth{
+		Username: c.RedisUsername,
+		Password: c.RedisPassword,
+	}
+}
+
+// TLSCertFile returns the path of the tls certificate
+func (c *Config) TLSCertFile() string {
+	return filepath.Join(c.TLSDir, rapi.TLSCertKey)
+}
+
+// TLSKeyFile returns the path of the tls private key
+func (c *Config) TLSKeyFile() string {
+	return filepath.Join(c.TLSDir, rapi.TLSPrivateKeyKey)
+}
+
+// TLSCAFile returns the path of the tls CA certificate
+func (c *Config) TLSCAFile() string {
+	return filepath.Join(c.TLSDir, rapi.TLSCAKey)
+}
+
+// TLSFiles returns the paths of all the tls files
+func (c *Config) TLSFiles() []string {
+	return []string{c.TLSCertFile(), c.TLSKeyFile(), c.TLSCAFile()}
+}
+
+// TLSConfig returns the tls configuration used to connect to the redis nodes, nil if tls is disabled
+func (c *Config) TLSConfig() *tls.Config {
+	if c.TLSDir == "" {
+		return nil
+	}
+	return redis.NewTLSConfigFromFiles(c.TLSCertFile(), c.TLSKeyFile(), c.TLSCAFile())
+}
+
 // AddFlags add cobra flags to populate Config
 func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.KubeConfigFile, "kubeconfig", c.KubeConfigFile, "location of kubeconfig file for access to kubernetes service")
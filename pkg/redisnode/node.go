@@ -17,7 +17,8 @@ import (
 )
 
 const (
-	dataFolder = "/redis-data"
+	dataFolder         = "/redis-data"
+	nodeConfigFileName = "node.conf"
 )
 
 // Node struct that represent a RedisNodeWrapper
@@ -54,7 +55,15 @@ func (n *Node) UpdateNodeConfigFile() error {
 		}
 	}
 
-	if err := n.addSettingInConfigFile("port " + n.config.Redis.ServerPort); err != nil {
+	if n.config.TLSDir != "" {
+		// plaintext is disabled, the redis port only accepts tls connections
+		if err := n.addSettingInConfigFile("port 0"); err != nil {
+			return err
+		}
+		if err := n.addSettingInConfigFile("tls-port " + n.config.Redis.ServerPort); err != nil {
+			return err
+		}
+	} else if err := n.addSettingInConfigFile("port " + n.config.Redis.ServerPort); err != nil {
 		return err
 	}
 
@@ -104,6 +113,14 @@ func (n *Node) UpdateNodeConfigFile() error {
 	if err := n.addSettingInConfigFile("cluster-node-timeout " + strconv.Itoa(n.config.Redis.ClusterNodeTimeout)); err != nil {
 		return err
 	}
+
+	if err := n.addAuthSettingsInConfigFile(); err != nil {
+		return err
+	}
+
+	if err := n.addTLSSettingsInConfigFile(); err != nil {
+		return err
+	}
 	if n.config.Redis.GetRenameCommandsFile() != "" {
 
 		if err := n.addSettingInConfigFile("include " + n.config.Redis.GetRenameCommandsFile()); err != nil {
@@ -121,6 +138,45 @@ func (n *Node) UpdateNodeConfigFile() error {
 	return nil
 }
 
+// addAuthSettingsInConfigFile configures the credentials used by replicas to sync with their primary.
+// Without username, the password protects the default user. With a username, the ACL user must be
+// defined in the additional configuration files.
+func (n *Node) addAuthSettingsInConfigFile() error {
+	auth := n.config.Auth()
+	if auth.Password == "" {
+		return nil
+	}
+	password := strconv.Quote(auth.Password)
+	if auth.Username == "" {
+		if err := n.addSettingInConfigFile("requirepass " + password); err != nil {
+			return err
+		}
+	} else if err := n.addSettingInConfigFile("masteruser " + strconv.Quote(auth.Username)); err != nil {
+		return err
+	}
+	return n.addSettingInConfigFile("masterauth " + password)
+}
+
+// addTLSSettingsInConfigFile enables tls for the client, replication and cluster bus connections
+func (n *Node) addTLSSettingsInConfigFile() error {
+	if n.config.TLSDir == "" {
+		return nil
+	}
+	settings := []string{
+		"tls-cert-file " + n.config.TLSCertFile(),
+		"tls-key-file " + n.config.TLSKeyFile(),
+		"tls-ca-cert-file " + n.config.TLSCAFile(),
+		"tls-cluster yes",
+		"tls-replication yes",
+	}
+	for _, setting := range settings {
+		if err := n.addSettingInConfigFile(setting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // addSettingInConfigFile add a line in the redis configuration file
 func (n *Node) addSettingInConfigFile(line string) error {
 	f, err := os.OpenFile(n.config.Redis.ConfigFileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
@@ -203,6 +259,16 @@ func (n *Node) StartFailover(ctx context.Context) error {
 	return n.RedisAdmin.StartFailover(ctx, n.Addr)
 }
 
+// HasClusterConfig returns true if the data folder contains the cluster configuration of a previous run
+func (n *Node) HasClusterConfig() bool {
+	return hasClusterConfig(dataFolder)
+}
+
+func hasClusterConfig(folder string) bool {
+	info, err := os.Stat(filepath.Join(folder, nodeConfigFileName))
+	return err == nil && info.Size() > 0
+}
+
 // ClearDataFolder completely erase all files in the /data folder
 func (n *Node) ClearDataFolder() error {
 	return clearFolder(dataFolder)
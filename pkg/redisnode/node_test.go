@@ -18,6 +18,9 @@ func TestUpdateNodeConfigFile(t *testing.T) {
 		maxMemory         uint64
 		podRequestLimit   string
 		additionalConfigs []string
+		username          string
+		password          string
+		tlsDir            string
 		expectedConfig    string
 	}{
 		{
@@ -63,6 +66,59 @@ cluster-config-file /redis-data/node.conf
 dir /redis-data
 cluster-node-timeout 321`,
 		},
+		{
+			name:      "with password",
+			maxMemory: 1048576,
+			password:  "hello world",
+			expectedConfig: `include /redis-conf/redis.conf
+port 1234
+cluster-enabled yes
+maxmemory 1048576
+maxmemory-policy allkeys-lru
+bind 0.0.0.0
+cluster-config-file /redis-data/node.conf
+dir /redis-data
+cluster-node-timeout 321
+requirepass "hello world"
+masterauth "hello world"`,
+		},
+		{
+			name:      "with username and password",
+			maxMemory: 1048576,
+			username:  "replication",
+			password:  "secret",
+			expectedConfig: `include /redis-conf/redis.conf
+port 1234
+cluster-enabled yes
+maxmemory 1048576
+maxmemory-policy allkeys-lru
+bind 0.0.0.0
+cluster-config-file /redis-data/node.conf
+dir /redis-data
+cluster-node-timeout 321
+masteruser "replication"
+masterauth "secret"`,
+		},
+		{
+			name:      "with tls",
+			maxMemory: 1048576,
+			tlsDir:    "/redis-tls",
+			expectedConfig: `include /redis-conf/redis.conf
+port 0
+tls-port 1234
+cluster-enabled yes
+maxmemory 1048576
+maxmemory-policy allkeys-lru
+bind 0.0.0.0
+cluster-config-file /redis-data/node.conf
+dir /redis-data
+cluster-node-timeout 321
+tls-cert-file /redis-tls/tls.crt
+tls-key-file /redis-tls/tls.key
+tls-ca-cert-file /redis-tls/ca.crt
+tls-cluster yes
+tls-replication yes`,
+		},
 	}
 
 	for _, tc := range tt {
@@ -115,6 +171,9 @@ cluster-node-timeout 321`,
 					ConfigFileName:      redisConfFile.Name(),
 					ConfigFiles:         additionalConfigFileNames,
 				},
+				RedisUsername: tc.username,
+				RedisPassword: tc.password,
+				TLSDir:        tc.tlsDir,
 			}
 
 			node := NewNode(&c, a)
@@ -167,3 +226,27 @@ func TestAdminCommands(t *testing.T) {
 		t.Errorf("StartFailover failed: %s", err)
 	}
 }
+
+func TestHasClusterConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "redis-data")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if hasClusterConfig(dir) {
+		t.Errorf("hasClusterConfig() should be false without node.conf")
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, nodeConfigFileName), []byte{}, 0644); err != nil {
+		t.Fatalf("Couldn't write node.conf: %v", err)
+	}
+	if hasClusterConfig(dir) {
+		t.Errorf("hasClusterConfig() should be false with an empty node.conf")
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, nodeConfigFileName), []byte("vars currentEpoch 1 lastVoteEpoch 0\n"), 0644); err != nil {
+		t.Fatalf("Couldn't write node.conf: %v", err)
+	}
+	if !hasClusterConfig(dir) {
+		t.Errorf("hasClusterConfig() should be true with a node.conf")
+	}
+}
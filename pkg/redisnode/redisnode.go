@@ -2,6 +2,9 @@ package redisnode
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -34,6 +37,8 @@ type RedisNode struct {
 	kubeClient clientset.Interface
 	redisAdmin redis.AdminInterface
 	admOptions redis.AdminOptions
+	// rejoin is true when the node restarts with the data and node ID of a previous run
+	rejoin bool
 
 	// Kubernetes Probes handler
 	health healthcheck.Handler
@@ -80,6 +85,8 @@ func (r *RedisNode) Run(stop <-chan struct{}) error {
 		return err
 	}
 
+	go r.watchTLSCertificates(node, stop)
+
 	glog.Info("Awaiting stop signal")
 	<-stop
 	glog.Info("Receive Stop Signal...")
@@ -120,6 +127,8 @@ func (r *RedisNode) init() (*Node, error) {
 	r.admOptions = redis.AdminOptions{
 		ConnectionTimeout:  time.Duration(r.config.Redis.DialTimeout) * time.Millisecond,
 		RenameCommandsFile: r.config.Redis.GetRenameCommandsFile(),
+		Auth:               r.config.Auth(),
+		TLSConfig:          r.config.TLSConfig(),
 	}
 	host, err := os.Hostname()
 	if err != nil {
@@ -140,9 +149,14 @@ func (r *RedisNode) init() (*Node, error) {
 		glog.Fatal("Unable to update the configuration file, err:", err)
 	}
 
-	err = me.ClearDataFolder() // may be needed if container crashes and restart at the same place
-	if err != nil {
-		glog.Errorf("Unable to clear data folder, err: %v", err)
+	if r.config.PersistentData {
+		r.rejoin = me.HasClusterConfig()
+		glog.Infof("Persistent data folder, restarting with previous node ID: %v", r.rejoin)
+	} else {
+		err = me.ClearDataFolder() // may be needed if container crashes and restart at the same place
+		if err != nil {
+			glog.Errorf("Unable to clear data folder, err: %v", err)
+		}
 	}
 
 	r.httpServer = &http.Server{Addr: r.config.HTTPServerAddr}
@@ -159,7 +173,7 @@ func (r *RedisNode) run(me *Node) (*Node, error) {
 	// Start redis server and wait for it to be accessible
 	chRedis := make(chan error)
 	go WrapRedis(r.config, chRedis)
-	starter := testAndWaitConnection(ctx, me.Addr, r.config.RedisStartWait)
+	starter := testAndWaitConnection(ctx, me.Addr, r.config.Auth(), r.config.TLSConfig(), r.config.RedisStartWait)
 	if starter != nil {
 		glog.Error("Error while waiting for redis to start: ", starter)
 		return nil, starter
@@ -169,7 +183,17 @@ func (r *RedisNode) run(me *Node) (*Node, error) {
 		// Initial redis server configuration
 		nodes, initCluster := r.isClusterInitialization(me.Addr)
 
-		if initCluster {
+		if r.rejoin {
+			// the node is still part of the cluster: meeting the other nodes only updates its address
+			glog.Infof("Rejoining cluster with the previous node ID")
+			r.redisAdmin.RebuildConnectionMap(ctx, nodes, &r.admOptions)
+			if len(nodes) > 0 {
+				if err := me.AttachNodeToCluster(ctx, me.Addr); err != nil {
+					glog.Error("Unable to rejoin the cluster, err:", err)
+					return false, nil
+				}
+			}
+		} else if initCluster {
 			glog.Infof("Initializing cluster with slots from 0 to %d", redis.HashMaxSlots)
 			if err := me.InitRedisCluster(ctx, me.Addr); err != nil {
 				glog.Error("Unable to init the cluster with this node, err:", err)
@@ -197,6 +221,47 @@ func (r *RedisNode) run(me *Node) (*Node, error) {
 	return me, nil
 }
 
+// watchTLSCertificates reloads the redis-server tls configuration when the mounted certificates are rotated
+func (r *RedisNode) watchTLSCertificates(me *Node, stop <-chan struct{}) {
+	if r.config.TLSDir == "" {
+		return
+	}
+	ctx := context.Background()
+	lastHash, err := hashFiles(r.config.TLSFiles()...)
+	if err != nil {
+		glog.Errorf("Unable to read the tls certificates, err: %v", err)
+	}
+	wait.Until(func() {
+		hash, err := hashFiles(r.config.TLSFiles()...)
+		if err != nil {
+			glog.Errorf("Unable to read the tls certificates, err: %v", err)
+			return
+		}
+		if hash == lastHash {
+			return
+		}
+		glog.Info("TLS certificates changed, reloading the redis-server tls configuration")
+		// setting any tls file makes redis-server reload all the certificates
+		if err := r.redisAdmin.SetConfig(ctx, me.Addr, []string{"tls-cert-file", r.config.TLSCertFile()}); err != nil {
+			glog.Errorf("Unable to reload the tls configuration, err: %v", err)
+			return
+		}
+		lastHash = hash
+	}, tlsWatchInterval, stop)
+}
+
+func hashFiles(files ...string) (string, error) {
+	hash := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(content)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func (r *RedisNode) isClusterInitialization(currentIP string) ([]string, bool) {
 	var initCluster = true
 	nodesAddr, _ := getRedisNodesAddrs(r.kubeClient, r.config.Cluster.Namespace, r.config.Cluster.NodeService)
@@ -232,6 +297,11 @@ func (r *RedisNode) handleStop(me *Node) error {
 		glog.Errorf("Failover node:%s  error:%s", me.Addr, err)
 	}
 
+	if r.config.PersistentData {
+		// the node keeps its node ID and rejoins the cluster on restart
+		return err
+	}
+
 	if err = me.ForgetNode(ctx); err != nil {
 		glog.Errorf("Forget node:%s  error:%s", me.Addr, err)
 	}
@@ -241,9 +311,11 @@ func (r *RedisNode) handleStop(me *Node) error {
 
 func (r *RedisNode) configureHealth(ctx context.Context) error {
 	addr := net.JoinHostPort("127.0.0.1", r.config.Redis.ServerPort)
+	auth := r.config.Auth()
+	tlsConfig := r.config.TLSConfig()
 	health := healthcheck.NewHandler()
 	health.AddReadinessCheck("Check redis-node readiness", func() error {
-		if err := readinessCheck(ctx, addr); err != nil {
+		if err := readinessCheck(ctx, addr, auth, tlsConfig); err != nil {
 			glog.Errorf("readiness check failed, err:%v", err)
 			return err
 		}
@@ -251,7 +323,7 @@ func (r *RedisNode) configureHealth(ctx context.Context) error {
 	})
 
 	health.AddLivenessCheck("Check redis-node liveness", func() error {
-		if err := livenessCheck(ctx, addr); err != nil {
+		if err := livenessCheck(ctx, addr, auth, tlsConfig); err != nil {
 			glog.Errorf("liveness check failed, err:%v", err)
 			return err
 		}
@@ -264,8 +336,8 @@ func (r *RedisNode) configureHealth(ctx context.Context) error {
 	return nil
 }
 
-func readinessCheck(ctx context.Context, addr string) error {
-	client, rediserr := redis.NewClient(ctx, addr, time.Second, map[string]string{}) // will fail if node not accessible or slot range not set
+func readinessCheck(ctx context.Context, addr string, auth redis.Auth, tlsConfig *tls.Config) error {
+	client, rediserr := redis.NewClient(ctx, addr, time.Second, map[string]string{}, auth, tlsConfig) // will fail if node not accessible or slot range not set
 	if rediserr != nil {
 		return fmt.Errorf("Readiness failed, err: %v", rediserr)
 	}
@@ -283,8 +355,8 @@ func readinessCheck(ctx context.Context, addr string) error {
 	return nil
 }
 
-func livenessCheck(ctx context.Context, addr string) error {
-	client, rediserr := redis.NewClient(ctx, addr, time.Second, map[string]string{}) // will fail if node not accessible or slot range not set
+func livenessCheck(ctx context.Context, addr string, auth redis.Auth, tlsConfig *tls.Config) error {
+	client, rediserr := redis.NewClient(ctx, addr, time.Second, map[string]string{}, auth, tlsConfig) // will fail if node not accessible or slot range not set
 	if rediserr != nil {
 		return fmt.Errorf("Liveness failed, err: %v", rediserr)
 	}
@@ -328,7 +400,7 @@ func WrapRedis(c *Config, ch chan error) {
 	ch <- nil
 }
 
-func testAndWaitConnection(ctx context.Context, addr string, maxWait time.Duration) error {
+func testAndWaitConnection(ctx context.Context, addr string, auth redis.Auth, tlsConfig *tls.Config, maxWait time.Duration) error {
 	startTime := time.Now()
 	waitTime := maxWait
 	for {
@@ -337,24 +409,15 @@ func testAndWaitConnection(ctx context.Context, addr string, maxWait time.Durati
 		if timeout <= 0 {
 			return errors.New("Timeout reached")
 		}
-		dialer := &radix.Dialer{
-			NetDialer: &net.Dialer{
-				Timeout: timeout,
-			},
-		}
-		client, err := dialer.Dial(ctx, "tcp", addr)
+		client, err := redis.NewClient(ctx, addr, timeout, map[string]string{}, auth, tlsConfig)
 		if err != nil {
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
-		defer client.Close()
 		var resp string
-		if err := client.Do(ctx, radix.Cmd(&resp, "PING")); err != nil {
-			client.Close()
-			time.Sleep(100 * time.Millisecond)
-			continue
-		} else if resp != "PONG" {
-			client.Close()
+		err = client.DoCmd(ctx, &resp, "PING")
+		client.Close()
+		if err != nil || resp != "PONG" {
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
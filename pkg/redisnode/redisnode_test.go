@@ -14,6 +14,7 @@ import (
 	kfakeclient "k8s.io/client-go/kubernetes/fake"
 
 	"github.com/IBM/operator-for-redis-cluster/pkg/config"
+	"github.com/IBM/operator-for-redis-cluster/pkg/redis"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake"
 	"github.com/IBM/operator-for-redis-cluster/pkg/redis/fake/admin"
 )
@@ -28,7 +29,7 @@ func TestTestAndWaitConnection(t *testing.T) {
 	resp := "PONG"
 	redisSrv1.PushResponse(rq, resp)
 
-	err := testAndWaitConnection(ctx, addr1, 1)
+	err := testAndWaitConnection(ctx, addr1, redis.Auth{}, nil, 1)
 	if err != nil {
 		t.Errorf("Unexpected error while waiting for fake redis node: %v", err)
 	}
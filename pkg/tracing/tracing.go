@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/IBM/operator-for-redis-cluster/pkg/config"
+	"github.com/IBM/operator-for-redis-cluster/pkg/utils"
+)
+
+const (
+	// tracerName name of the tracer of the operator spans
+	tracerName = "github.com/IBM/operator-for-redis-cluster"
+	// serviceName name of the service reported in the spans
+	serviceName = "redis-operator"
+)
+
+// Setup configures the global tracer provider with the exporter of the config, the spans are dropped with the
+// none exporter. The returned function flushes the spans and stops the exporter.
+func Setup(ctx context.Context, cfg *config.Tracing) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	switch cfg.Exporter {
+	case "", config.TracingExporterNone:
+		return noop, nil
+	case config.TracingExporterOTLP:
+	default:
+		return noop, fmt.Errorf("unsupported tracing exporter %q, supported exporters: %s, %s", cfg.Exporter, config.TracingExporterNone, config.TracingExporterOTLP)
+	}
+
+	var options []otlptracegrpc.Option
+	if cfg.Endpoint != "" {
+		options = append(options, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return noop, fmt.Errorf("unable to create the OTLP exporter: %v", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceVersionKey.String(utils.OPERATOR_VERSION),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("unable to create the tracing resource: %v", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the given attributes, the returned context carries the span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error of the operation in the span, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -16,6 +16,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// TopologyDomainSeparator separator of the label values of a topology domain
+const TopologyDomainSeparator = "/"
+
 const (
 	Kilobyte = 1000
 	Kibibyte = 1024
@@ -72,56 +75,163 @@ func ListPods(ctx context.Context, kubeClient client.Client, opts []client.ListO
 	return podList.Items, nil
 }
 
+// GetZoneSkew returns the largest difference of the number of nodes between the topology domains sharing the same
+// parent label values, at each level of the topology keys: with the zone then the host, the skew across the zones and
+// the skew across the hosts of each zone
 func GetZoneSkew(zoneToNodes map[string][]string) int {
-	if len(zoneToNodes) == 0 {
-		return 0
+	depth := 0
+	for zone := range zoneToNodes {
+		if labels := TopologyLabels(zone); labels > depth {
+			depth = labels
+		}
 	}
-	largestZoneSize := 0
-	smallestZoneSize := math.MaxInt32
-	for _, nodes := range zoneToNodes {
-		if len(nodes) > largestZoneSize {
-			largestZoneSize = len(nodes)
+	skew := 0
+	for level := 1; level <= depth; level++ {
+		parentToSizes := make(map[string]map[string]int)
+		for zone, nodes := range zoneToNodes {
+			parent := TopologyPrefix(zone, level-1)
+			if _, ok := parentToSizes[parent]; !ok {
+				parentToSizes[parent] = make(map[string]int)
+			}
+			parentToSizes[parent][TopologyPrefix(zone, level)] += len(nodes)
 		}
-		if len(nodes) < smallestZoneSize {
-			smallestZoneSize = len(nodes)
+		for _, sizes := range parentToSizes {
+			largestZoneSize := 0
+			smallestZoneSize := math.MaxInt32
+			for _, size := range sizes {
+				if size > largestZoneSize {
+					largestZoneSize = size
+				}
+				if size < smallestZoneSize {
+					smallestZoneSize = size
+				}
+			}
+			if largestZoneSize-smallestZoneSize > skew {
+				skew = largestZoneSize - smallestZoneSize
+			}
 		}
 	}
-	return largestZoneSize - smallestZoneSize
+	return skew
 }
 
-func GetZone(nodeName string, kubeNodes []corev1.Node) string {
-	for _, node := range kubeNodes {
-		if node.Name == nodeName {
-			label, ok := node.Labels[corev1.LabelTopologyZone]
-			if ok {
-				return label
-			} else {
-				return rapi.UnknownZone
-			}
+// TopologyLabels returns the number of label values of the topology domain
+func TopologyLabels(domain string) int {
+	return strings.Count(domain, TopologyDomainSeparator) + 1
+}
+
+// TopologyPrefix returns the first depth label values of the topology domain, e.g. the zone of a zone/host domain
+func TopologyPrefix(domain string, depth int) string {
+	if depth <= 0 {
+		return ""
+	}
+	values := strings.SplitN(domain, TopologyDomainSeparator, depth+1)
+	if len(values) <= depth {
+		return domain
+	}
+	return strings.Join(values[:depth], TopologyDomainSeparator)
+}
+
+// CommonTopologyLabels returns the number of leading label values the topology domains share
+func CommonTopologyLabels(a, b string) int {
+	aValues := strings.Split(a, TopologyDomainSeparator)
+	bValues := strings.Split(b, TopologyDomainSeparator)
+	common := 0
+	for common < len(aValues) && common < len(bValues) && aValues[common] == bValues[common] {
+		common++
+	}
+	return common
+}
+
+// GetTopologyKeys returns the node labels the redis nodes of the cluster are spread across, the zone label by default
+func GetTopologyKeys(cluster *rapi.RedisCluster) []string {
+	if len(cluster.Spec.TopologyKeys) > 0 {
+		return cluster.Spec.TopologyKeys
+	}
+	return []string{corev1.LabelTopologyZone}
+}
+
+// GetTopologyDomain returns the values of the topology labels of the k8s node joined with a slash, the unknown zone
+// stands for a missing label. The zone label is used without topology keys.
+func GetTopologyDomain(node *corev1.Node, topologyKeys []string) string {
+	if len(topologyKeys) == 0 {
+		topologyKeys = []string{corev1.LabelTopologyZone}
+	}
+	values := make([]string, len(topologyKeys))
+	for i, key := range topologyKeys {
+		value, ok := node.Labels[key]
+		if !ok {
+			value = rapi.UnknownZone
+		}
+		values[i] = value
+	}
+	return strings.Join(values, TopologyDomainSeparator)
+}
+
+// GetZone returns the topology domain of the k8s node hosting the pod
+func GetZone(nodeName string, kubeNodes []corev1.Node, topologyKeys []string) string {
+	for i := range kubeNodes {
+		if kubeNodes[i].Name == nodeName {
+			return GetTopologyDomain(&kubeNodes[i], topologyKeys)
 		}
 	}
-	return rapi.UnknownZone
+	return GetTopologyDomain(&corev1.Node{}, topologyKeys)
 }
 
-func GetZones(nodes []corev1.Node) []string {
+// GetZones returns the topology domains of the k8s nodes, in the order the redis nodes are spread across them
+func GetZones(nodes []corev1.Node, topologyKeys []string) []string {
 	set := make(map[string]struct{})
 	var zones []string
-	for _, node := range nodes {
-		zone, ok := node.Labels[corev1.LabelTopologyZone]
-		if ok {
-			set[zone] = struct{}{}
-		} else {
-			set[rapi.UnknownZone] = struct{}{}
-		}
+	for i := range nodes {
+		set[GetTopologyDomain(&nodes[i], topologyKeys)] = struct{}{}
 	}
 	if len(set) == 0 {
-		set[rapi.UnknownZone] = struct{}{}
+		set[GetTopologyDomain(&corev1.Node{}, topologyKeys)] = struct{}{}
 	}
 	for key := range set {
 		zones = append(zones, key)
 	}
-	sort.Strings(zones)
-	return zones
+	return sortTopologyDomains(zones)
+}
+
+// sortTopologyDomains sorts the domains of each first label value, and interleaves them: consecutive domains differ
+// by their first label value first, so that the replicas placed round-robin land in another zone before another host
+// of the same zone
+func sortTopologyDomains(domains []string) []string {
+	groups := make(map[string][]string)
+	var firsts []string
+	for _, domain := range domains {
+		first, rest, nested := domain, "", false
+		if i := strings.Index(domain, TopologyDomainSeparator); i >= 0 {
+			first, rest, nested = domain[:i], domain[i+len(TopologyDomainSeparator):], true
+		}
+		if _, ok := groups[first]; !ok {
+			firsts = append(firsts, first)
+			groups[first] = nil
+		}
+		if nested {
+			groups[first] = append(groups[first], rest)
+		}
+	}
+	sort.Strings(firsts)
+	sorted := make([][]string, len(firsts))
+	for i, first := range firsts {
+		if len(groups[first]) == 0 {
+			sorted[i] = []string{first}
+			continue
+		}
+		for _, rest := range sortTopologyDomains(groups[first]) {
+			sorted[i] = append(sorted[i], first+TopologyDomainSeparator+rest)
+		}
+	}
+	result := make([]string, 0, len(domains))
+	for round := 0; len(result) < len(domains); round++ {
+		for _, group := range sorted {
+			if round < len(group) {
+				result = append(result, group[round])
+			}
+		}
+	}
+	return result
 }
 
 func GetZoneSkewByRole(zoneToPrimaries map[string][]string, zoneToReplicas map[string][]string) (int, int, bool) {
@@ -144,10 +254,32 @@ func ZoneToRole(nodes []rapi.RedisClusterNode) (map[string][]string, map[string]
 	return zoneToPrimaries, zoneToReplicas
 }
 
+// GetNbPodsToCreate returns the number of pods to create for a rolling update, up to the maximum surge above the
+// required pods of the cluster
 func GetNbPodsToCreate(cluster *rapi.RedisCluster) int32 {
-	nbMigrationPods := 1 + *cluster.Spec.ReplicationFactor
-	nbRequiredPods := *cluster.Spec.NumberOfPrimaries * nbMigrationPods
-	return nbRequiredPods + nbMigrationPods - cluster.Status.Cluster.NumberOfPods
+	nbRequiredPods := *cluster.Spec.NumberOfPrimaries * (1 + *cluster.Spec.ReplicationFactor)
+	return nbRequiredPods + GetMaxSurge(cluster) - cluster.Status.Cluster.NumberOfPods
+}
+
+// GetMaxUnavailableShards returns the maximum number of shards replaced at the same time during a rolling update
+func GetMaxUnavailableShards(cluster *rapi.RedisCluster) int32 {
+	if rollingUpdate := cluster.Spec.RollingUpdate; rollingUpdate != nil && rollingUpdate.MaxUnavailableShards != nil && *rollingUpdate.MaxUnavailableShards > 0 {
+		return *rollingUpdate.MaxUnavailableShards
+	}
+	return 1
+}
+
+// GetMaxSurge returns the maximum number of pods created above the required pods of the cluster during a rolling
+// update, it never exceeds the pods of the shards replaced at the same time
+func GetMaxSurge(cluster *rapi.RedisCluster) int32 {
+	maxSurge := GetMaxUnavailableShards(cluster) * (1 + *cluster.Spec.ReplicationFactor)
+	if rollingUpdate := cluster.Spec.RollingUpdate; rollingUpdate != nil && rollingUpdate.MaxSurge != nil && *rollingUpdate.MaxSurge < maxSurge {
+		maxSurge = *rollingUpdate.MaxSurge
+	}
+	if maxSurge < 0 {
+		return 0
+	}
+	return maxSurge
 }
 
 func StringToByteString(value string) (string, error) {
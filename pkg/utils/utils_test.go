@@ -142,6 +142,33 @@ func TestGetZoneSkew(t *testing.T) {
 			},
 			want: 2,
 		},
+		{
+			name: "balanced hosts in unbalanced zones",
+			zoneToNodes: map[string][]string{
+				"zone1/node1": {redisPrimary1.ID, redisPrimary4.ID},
+				"zone1/node2": {redisPrimary2.ID, redisReplica3.ID},
+				"zone2/node3": {redisPrimary3.ID},
+			},
+			want: 3,
+		},
+		{
+			name: "unbalanced hosts in balanced zones",
+			zoneToNodes: map[string][]string{
+				"zone1/node1": {redisPrimary1.ID, redisPrimary4.ID},
+				"zone1/node2": {},
+				"zone2/node3": {redisPrimary2.ID, redisPrimary3.ID},
+			},
+			want: 2,
+		},
+		{
+			name: "balanced zones and hosts",
+			zoneToNodes: map[string][]string{
+				"zone1/node1": {redisPrimary1.ID},
+				"zone1/node2": {redisPrimary4.ID},
+				"zone2/node3": {redisPrimary2.ID, redisPrimary3.ID},
+			},
+			want: 0,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -153,12 +180,21 @@ func TestGetZoneSkew(t *testing.T) {
 	}
 }
 
+// hostNode returns a node of the zone labeled with its host name
+func hostNode(name, zone string) *corev1.Node {
+	node := testutil.NewNode(name, zone)
+	node.Labels[corev1.LabelHostname] = name
+	return node
+}
+
 func TestGetZone(t *testing.T) {
+	hostKeys := []string{corev1.LabelTopologyZone, corev1.LabelHostname}
 	tests := []struct {
-		name     string
-		nodeName string
-		nodes    []corev1.Node
-		want     string
+		name         string
+		nodeName     string
+		nodes        []corev1.Node
+		topologyKeys []string
+		want         string
 	}{
 		{
 			name:     "no nodes",
@@ -190,10 +226,38 @@ func TestGetZone(t *testing.T) {
 			nodes:    []corev1.Node{*node1, *node2, *node4},
 			want:     "zone1",
 		},
+		{
+			name:         "custom topology key",
+			nodeName:     "node1",
+			nodes:        []corev1.Node{*hostNode("node1", "zone1")},
+			topologyKeys: []string{corev1.LabelHostname},
+			want:         "node1",
+		},
+		{
+			name:         "zone and host",
+			nodeName:     "node1",
+			nodes:        []corev1.Node{*hostNode("node1", "zone1")},
+			topologyKeys: hostKeys,
+			want:         "zone1/node1",
+		},
+		{
+			name:         "no host label",
+			nodeName:     node1.Name,
+			nodes:        []corev1.Node{*node1},
+			topologyKeys: hostKeys,
+			want:         "zone1/unknown",
+		},
+		{
+			name:         "unknown node with topology keys",
+			nodeName:     "node9",
+			nodes:        []corev1.Node{*node1},
+			topologyKeys: hostKeys,
+			want:         "unknown/unknown",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := utils.GetZone(tt.nodeName, tt.nodes)
+			got := utils.GetZone(tt.nodeName, tt.nodes, tt.topologyKeys)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetZone() = %v, want %v", got, tt.want)
 			}
@@ -202,10 +266,12 @@ func TestGetZone(t *testing.T) {
 }
 
 func TestGetZones(t *testing.T) {
+	hostKeys := []string{corev1.LabelTopologyZone, corev1.LabelHostname}
 	tests := []struct {
-		name  string
-		nodes []corev1.Node
-		want  []string
+		name         string
+		nodes        []corev1.Node
+		topologyKeys []string
+		want         []string
 	}{
 		{
 			name:  "no nodes",
@@ -232,10 +298,22 @@ func TestGetZones(t *testing.T) {
 			nodes: []corev1.Node{*node1, *node2, *node3, *node5},
 			want:  []string{"unknown", "zone1", "zone2", "zone3"},
 		},
+		{
+			name:         "no nodes with topology keys",
+			nodes:        []corev1.Node{},
+			topologyKeys: hostKeys,
+			want:         []string{"unknown/unknown"},
+		},
+		{
+			name:         "hosts interleaved across zones",
+			nodes:        []corev1.Node{*hostNode("node1", "zone1"), *hostNode("node2", "zone1"), *hostNode("node3", "zone1"), *hostNode("node4", "zone2"), *hostNode("node5", "zone2")},
+			topologyKeys: hostKeys,
+			want:         []string{"zone1/node1", "zone2/node4", "zone1/node2", "zone2/node5", "zone1/node3"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := utils.GetZones(tt.nodes)
+			got := utils.GetZones(tt.nodes, tt.topologyKeys)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetZones() = %v, want %v", got, tt.want)
 			}
@@ -424,6 +502,54 @@ func TestZoneToRole(t *testing.T) {
 	}
 }
 
+func TestGetNbPodsToCreate(t *testing.T) {
+	int32Ptr := func(v int32) *int32 { return &v }
+	tests := []struct {
+		name          string
+		rollingUpdate *rapi.RollingUpdate
+		nbPods        int32
+		want          int32
+	}{
+		{
+			name:   "default surge of a shard",
+			nbPods: 6,
+			want:   2,
+		},
+		{
+			name:          "parallel shards",
+			rollingUpdate: &rapi.RollingUpdate{MaxUnavailableShards: int32Ptr(2)},
+			nbPods:        6,
+			want:          4,
+		},
+		{
+			name:          "surge capped by the unavailable shards",
+			rollingUpdate: &rapi.RollingUpdate{MaxSurge: int32Ptr(10)},
+			nbPods:        6,
+			want:          2,
+		},
+		{
+			name:          "zero surge",
+			rollingUpdate: &rapi.RollingUpdate{MaxSurge: int32Ptr(0)},
+			nbPods:        6,
+			want:          0,
+		},
+		{
+			name:   "surge pods already created",
+			nbPods: 8,
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &rapi.RedisCluster{Spec: rapi.RedisClusterSpec{NumberOfPrimaries: int32Ptr(3), ReplicationFactor: int32Ptr(1), RollingUpdate: tt.rollingUpdate}}
+			cluster.Status.Cluster.NumberOfPods = tt.nbPods
+			if got := utils.GetNbPodsToCreate(cluster); got != tt.want {
+				t.Errorf("GetNbPodsToCreate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestStringToByteString(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -52,9 +52,11 @@ func NewRedisCluster(name, namespace, tag string, nbPrimary, replication int32)
 					SlotBatchSize: proto.Int32(1000),
 				},
 			},
-			Scaling: &rapi.Migration{
-				KeyBatchSize:  proto.Int32(10000),
-				SlotBatchSize: proto.Int32(1000),
+			Scaling: &rapi.Scaling{
+				Migration: rapi.Migration{
+					KeyBatchSize:  proto.Int32(10000),
+					SlotBatchSize: proto.Int32(1000),
+				},
 			},
 			PodTemplate: &v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
@@ -339,7 +341,7 @@ func ZonesBalancedFunc(kubeClient kclient.Client, redisCluster *rapi.RedisCluste
 				primaryToReplicas[node.PrimaryRef] = append(primaryToReplicas[node.PrimaryRef], node)
 			}
 		}
-		if int(*cluster.Spec.ReplicationFactor) < len(utils.GetZones(kubeNodes)) {
+		if int(*cluster.Spec.ReplicationFactor) < len(utils.GetZones(kubeNodes, utils.GetTopologyKeys(cluster))) {
 			// check for primaries and replicas in same zone
 			for primary, replicas := range primaryToReplicas {
 				for _, replica := range replicas {